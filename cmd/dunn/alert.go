@@ -0,0 +1,105 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "log"
+  "os"
+  "strconv"
+  "strings"
+  "text/tabwriter"
+  "time"
+
+  "dunn-finance/pkg/alert"
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/model"
+)
+
+var alertCommands = []subcommand{
+  {"add", "Add a rule for -code, or for every code without -code"},
+  {"list", "Print every rule"},
+  {"enable", "Enable the rules of the ids"},
+  {"disable", "Disable the rules of the ids"},
+  {"delete", "Delete the rules of the ids and their alerts"},
+  {"history", "Print the latest -limit alerts, of -code when given"},
+}
+
+const alertExamples = `
+
+Examples:
+  dunn alert add -code 5253 "close crosses_above 2,200"
+  dunn alert add -code 5253 "volume > 3x vma25"
+  dunn alert add "close below dma75"
+
+Rules are evaluated after dunn import and by the alerts step of dunn daemon.`
+
+func runAlert(ctx context.Context, cfg *config.Config, args []string) error {
+  command, args, err := parseSubcommand("alert", alertCommands, args)
+  if err != nil { return err }
+
+  fs := newFlagSet("alert "+command, "[flags] [expression|ids...]", summaryOf(alertCommands, command)+"."+alertExamples)
+  db := addDBFlags(fs, cfg)
+  code := fs.String("code", "", "Stock code of the rule or of the history")
+  note := fs.String("note", "", "Note added to the alert messages of the rule")
+  limit := fs.Int("limit", 20, "Number of alerts printed by history")
+  if err := parseFlags(fs, args); err != nil { return err }
+  args = fs.Args()
+
+  var cond *alert.Condition
+  var ids []int64
+  switch command {
+    case "add":
+      if cond, err = alert.Parse(strings.Join(args, " ")); err != nil { return usagef("%v", err) }
+    case "enable", "disable", "delete":
+      if len(args) == 0 { return usagef("specify the rule ids") }
+      for _, arg := range args {
+        id, err := strconv.ParseInt(arg, 10, 64)
+        if err != nil { return usagef("invalid rule id %q", arg) }
+        ids = append(ids, id)
+      }
+  }
+
+  conn, err := db.open()
+  if err != nil { return err }
+  defer conn.Close()
+
+  ruleDao := dao.NewAlertRuleDAO(conn)
+
+  switch command {
+    case "add":
+      rule := &model.AlertRule{Code: *code, Expression: cond.String(), Note: *note, Enabled: true, CreatedAt: time.Now()}
+      if err := ruleDao.Create(ctx, rule); err != nil { return fmt.Errorf("failed to add the rule: %w", err) }
+      log.Printf("[INFO] Added rule %d: %s\n", rule.ID, rule.Expression)
+    case "list":
+      rules, err := ruleDao.FindAll(ctx)
+      if err != nil { return fmt.Errorf("failed to load rules: %w", err) }
+      tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+      fmt.Fprintln(tw, "ID\tCODE\tEXPRESSION\tENABLED\tNOTE")
+      for _, rule := range rules {
+        code := rule.Code
+        if code == "" { code = "*" }
+        fmt.Fprintf(tw, "%d\t%s\t%s\t%t\t%s\n", rule.ID, code, rule.Expression, rule.Enabled, rule.Note)
+      }
+      return tw.Flush()
+    case "enable", "disable", "delete":
+      for _, id := range ids {
+        var n int64
+        if command == "delete" {
+          n, err = ruleDao.Delete(ctx, id)
+        } else {
+          n, err = ruleDao.SetEnabled(ctx, id, command == "enable")
+        }
+        if err != nil { return fmt.Errorf("failed to %s rule %d: %w", command, id, err) }
+        if n == 0 { log.Printf("[WARN] No rule %d\n", id) }
+      }
+      log.Printf("[INFO] %s %d rules\n", map[string]string{"enable": "Enabled", "disable": "Disabled", "delete": "Deleted"}[command], len(ids))
+    case "history":
+      alerts, err := dao.NewAlertDAO(conn).FindRecent(ctx, *code, *limit)
+      if err != nil { return fmt.Errorf("failed to load alerts: %w", err) }
+      for _, a := range alerts {
+        fmt.Printf("%s  rule %d  %s\n", a.TriggeredAt.Local().Format("2006/01/02 15:04"), a.RuleID, a.Message)
+      }
+  }
+  return nil
+}
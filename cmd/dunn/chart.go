@@ -0,0 +1,98 @@
+package main
+
+import (
+  "context"
+  "database/sql"
+  "errors"
+  "fmt"
+  "log"
+  "os"
+
+  "dunn-finance/pkg/api"
+  "dunn-finance/pkg/chart"
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/model"
+)
+
+func runChart(ctx context.Context, cfg *config.Config, args []string) error {
+  fs := newFlagSet("chart", "-code CODE -out FILE [flags]", "Renders a candlestick chart of one code to an SVG or PNG file.")
+  db := addDBFlags(fs, cfg)
+  code := fs.String("code", "", "stock code")
+  from := fs.String("from", "", "First date of the chart. Empty for the beginning")
+  to := fs.String("to", "", "Last date of the chart. Empty for the end")
+  interval := fs.String("interval", "1d", "Bar size: 1d, 1w or 1mo")
+  out := fs.String("out", "", "Output file. The format is taken from its extension, .svg or .png")
+  width := fs.Int("width", 1200, "Width in pixels")
+  height := fs.Int("height", 800, "Height in pixels")
+  volume := fs.Bool("volume", true, "Draw the volume panel")
+  dma := fs.Bool("dma", true, "Overlay the 5, 25 and 75 day moving averages")
+  indicators := fs.String("indicators", "", "Comma separated indicator panels: rsi, macd")
+  colors := fs.String("colors", "japanese", "Candle colors: japanese (red up) or western (green up)")
+  if err := parseFlags(fs, args); err != nil { return err }
+
+  if *code == "" { return usagef("specify the stock code with -code") }
+  if *out == "" { return usagef("specify the output file with -out") }
+
+  format, err := chart.FormatOf(*out)
+  if err != nil { return usagef("%v", err) }
+  barInterval, err := api.ParseInterval(*interval)
+  if err != nil { return usagef("%v", err) }
+  opts := chart.Options{Width: *width, Height: *height, Volume: *volume, DMA: *dma}
+  if opts.Indicators, err = chart.ParseIndicators(*indicators); err != nil { return usagef("%v", err) }
+  if opts.Colors, err = parseChartColors(*colors); err != nil { return err }
+
+  var fromDate, toDate model.Date
+  if *from != "" {
+    if fromDate, err = model.ParseDate(*from); err != nil { return usagef("-from: %v", err) }
+  }
+  if *to != "" {
+    if toDate, err = model.ParseDate(*to); err != nil { return usagef("-to: %v", err) }
+  }
+
+  conn, err := db.open()
+  if err != nil { return err }
+  defer conn.Close()
+
+  // The bitmap font of PNG has no Japanese, so only SVG carries the name.
+  opts.Title = *code
+  stock, err := dao.NewStockDAO(conn).Find(ctx, *code)
+  switch {
+    case err == nil:
+      if format == chart.FormatSVG { opts.Title += " " + stock.Name }
+    case !errors.Is(err, sql.ErrNoRows):
+      log.Printf("[WARN] Failed to load the name of %s: %v\n", *code, err)
+  }
+
+  var bars []*model.AdjustedDailyOHLCV
+  for bar, err := range dao.NewAdjustedDailyOHLCVDAO(conn).IterateFiltered(ctx, []string{*code}, fromDate, toDate) {
+    if err != nil { return fmt.Errorf("failed to load bars: %w", err) }
+    bars = append(bars, bar)
+  }
+  bars = api.Resample(bars, barInterval)
+  // Moving averages are daily, so they would mislead on weekly or monthly bars.
+  if barInterval != api.IntervalDaily { opts.DMA = false }
+
+  file, err := os.Create(*out)
+  if err != nil { return err }
+  if err := chart.Render(file, format, bars, opts); err != nil {
+    file.Close()
+    os.Remove(*out)
+    return fmt.Errorf("failed to render %s: %w", *out, err)
+  }
+  if err := file.Close(); err != nil { return err }
+
+  log.Printf("[INFO] Wrote %d bars of %s to %s\n", len(bars), *code, *out)
+  return nil
+}
+
+// parseChartColors returns the candle colors of -colors.
+func parseChartColors(s string) (chart.Colors, error) {
+  switch s {
+    case "japanese":
+      return chart.JapaneseColors, nil
+    case "western":
+      return chart.WesternColors, nil
+  }
+  return chart.Colors{}, usagef("unknown -colors %q. Use japanese or western", s)
+}
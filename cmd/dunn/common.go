@@ -0,0 +1,133 @@
+package main
+
+import (
+  "context"
+  "database/sql"
+  "errors"
+  "flag"
+  "fmt"
+  "os"
+  "strings"
+
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/database"
+)
+
+// usageError is a problem with the arguments. It exits with exitUsage.
+type usageError struct {
+  msg string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+  return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// errFlags is returned for flags that did not parse. The flag package has
+// printed the problem and the usage already.
+var errFlags = errors.New("invalid flags")
+
+// newFlagSet returns the flags of a subcommand with the usage printed by
+// --help.
+func newFlagSet(name string, synopsis string, description string) *flag.FlagSet {
+  fs := flag.NewFlagSet("dunn "+name, flag.ContinueOnError)
+  fs.Usage = func() {
+    fmt.Fprintf(os.Stderr, "Usage: dunn %s %s\n\n%s\n\nFlags:\n", name, synopsis, description)
+    fs.PrintDefaults()
+  }
+  return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+  if err := fs.Parse(args); err != nil {
+    if errors.Is(err, flag.ErrHelp) { return err }
+    return errFlags
+  }
+  return nil
+}
+
+// dbFlags are the -dbpath and -driver flags every database command has,
+// defaulting to the config.
+type dbFlags struct {
+  path   *string
+  driver *string
+}
+
+func addDBFlags(fs *flag.FlagSet, cfg *config.Config) *dbFlags {
+  return &dbFlags{
+    path:   fs.String("dbpath", cfg.DB.Path, "Path to the DB file, or the DSN with -driver postgres"),
+    driver: fs.String("driver", cfg.DB.Driver, "Database driver: sqlite3 or postgres"),
+  }
+}
+
+func (f *dbFlags) open() (database.DBConnector, error) {
+  if *f.path == "" { return nil, usagef("no database: set db.path in the config, DUNN_DB_PATH or -dbpath") }
+
+  dbManager := &database.DBManager{ Driver: *f.driver, DSN: *f.path }
+  return dbManager.GetDBInstance(), nil
+}
+
+// splitCodes splits comma separated codes, skipping empty ones.
+func splitCodes(s string) []string {
+  var codes []string
+  for _, code := range strings.Split(s, ",") {
+    if code = strings.TrimSpace(code); code != "" { codes = append(codes, code) }
+  }
+  return codes
+}
+
+// selectCodes returns the codes of -codes followed by those of the
+// watchlist, if any.
+func selectCodes(ctx context.Context, db database.Querier, codes string, watchlist string) ([]string, error) {
+  selected := splitCodes(codes)
+  if watchlist == "" { return selected, nil }
+
+  listed, err := dao.NewWatchlistCodeDAO(db).Codes(ctx, watchlist)
+  if errors.Is(err, sql.ErrNoRows) { return nil, usagef("no watchlist %s", watchlist) }
+  if err != nil { return nil, fmt.Errorf("failed to load watchlist %s: %w", watchlist, err) }
+  if len(listed) == 0 { return nil, fmt.Errorf("watchlist %s has no codes", watchlist) }
+
+  return append(selected, listed...), nil
+}
+
+// subcommand is an action of a command with several, like dunn db backup.
+type subcommand struct {
+  name    string
+  summary string
+}
+
+// parseSubcommand returns the action of command named by the first of args,
+// and the arguments after it.
+func parseSubcommand(command string, subs []subcommand, args []string) (string, []string, error) {
+  usage := func() {
+    fmt.Fprintf(os.Stderr, "Usage: dunn %s <command> [flags]\n\nCommands:\n", command)
+    for _, s := range subs {
+      fmt.Fprintf(os.Stderr, "  %-9s %s\n", s.name, s.summary)
+    }
+    fmt.Fprintf(os.Stderr, "\nRun 'dunn %s <command> --help' for the flags of a command.\n", command)
+  }
+
+  if len(args) == 0 {
+    usage()
+    return "", nil, errFlags
+  }
+  switch args[0] {
+    case "-h", "-help", "--help", "help":
+      usage()
+      return "", nil, flag.ErrHelp
+  }
+  for _, s := range subs {
+    if s.name == args[0] { return s.name, args[1:], nil }
+  }
+  return "", nil, usagef("unknown command %q", args[0])
+}
+
+// summaryOf returns the summary of the action, for the usage of its flags.
+func summaryOf(subs []subcommand, name string) string {
+  for _, s := range subs {
+    if s.name == name { return s.summary }
+  }
+  return ""
+}
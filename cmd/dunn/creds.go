@@ -0,0 +1,92 @@
+package main
+
+import (
+  "bufio"
+  "context"
+  "fmt"
+  "log"
+  "os"
+  "strings"
+
+  "golang.org/x/term"
+
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/credentials"
+)
+
+var credsCommands = []subcommand{
+  {"set", "Store the login of -site, prompted for"},
+  {"get", "Print the login of -site"},
+  {"delete", "Remove the login of -site"},
+}
+
+func runCreds(ctx context.Context, cfg *config.Config, args []string) error {
+  command, args, err := parseSubcommand("creds", credsCommands, args)
+  if err != nil { return err }
+
+  fs := newFlagSet("creds "+command, "[flags]", summaryOf(credsCommands, command)+`.
+
+Credentials are stored encrypted with a passphrase. The passphrase is read
+from $DUNN_CREDS_PASSPHRASE or prompted for.`)
+  site := fs.String("site", "sbisec", "Site name the credential is for")
+  path := fs.String("path", "", "Path to the credential file (default: $DUNN_CREDS_PATH or the user config dir)")
+  show := fs.Bool("show", false, "Print the password and PIN in clear text on get")
+  if err := parseFlags(fs, args); err != nil { return err }
+
+  if *path == "" {
+    defaultPath, err := credentials.DefaultPath()
+    if err != nil { return fmt.Errorf("failed to resolve the credential file path: %w", err) }
+    *path = defaultPath
+  }
+
+  reader := bufio.NewReader(os.Stdin)
+  passphrase := os.Getenv(credentials.PassphraseEnv)
+  if passphrase == "" {
+    if passphrase, err = prompt(reader, "Passphrase: ", true); err != nil { return err }
+  }
+  store := &credentials.Store{Path: *path, Passphrase: []byte(passphrase)}
+
+  switch command {
+    case "set":
+      cred := &credentials.Credential{}
+      if cred.UserID, err = prompt(reader, "User ID: ", false); err != nil { return err }
+      if cred.Password, err = prompt(reader, "Password: ", true); err != nil { return err }
+      if cred.PIN, err = prompt(reader, "Trading PIN (optional): ", true); err != nil { return err }
+      if err := store.Set(*site, cred); err != nil { return fmt.Errorf("failed to set credential: %w", err) }
+      log.Printf("[INFO] Stored credential for %s in %s\n", *site, *path)
+    case "get":
+      cred, err := store.Get(*site)
+      if err != nil { return fmt.Errorf("failed to get credential: %w", err) }
+      password, pin := mask(cred.Password), mask(cred.PIN)
+      if *show { password, pin = cred.Password, cred.PIN }
+      fmt.Printf("site: %s\nuser_id: %s\npassword: %s\npin: %s\n", *site, cred.UserID, password, pin)
+    case "delete":
+      if err := store.Delete(*site); err != nil { return fmt.Errorf("failed to delete credential: %w", err) }
+      log.Printf("[INFO] Deleted credential for %s from %s\n", *site, *path)
+  }
+  return nil
+}
+
+// prompt reads a line from the terminal without echo when secret is true.
+// Input is never taken from flags so it does not end up in shell history.
+func prompt(reader *bufio.Reader, label string, secret bool) (string, error) {
+  fmt.Fprint(os.Stderr, label)
+
+  fd := int(os.Stdin.Fd())
+  if secret && term.IsTerminal(fd) {
+    b, err := term.ReadPassword(fd)
+    fmt.Fprintln(os.Stderr)
+    if err != nil { return "", fmt.Errorf("failed to read input: %w", err) }
+    return string(b), nil
+  }
+
+  line, err := reader.ReadString('\n')
+  if err != nil && line == "" { return "", fmt.Errorf("failed to read input: %w", err) }
+
+  return strings.TrimRight(line, "\r\n"), nil
+}
+
+func mask(s string) string {
+  if s == "" { return "" }
+  return strings.Repeat("*", 8)
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "log"
+  "os"
+  "slices"
+  "strings"
+  "text/tabwriter"
+  "time"
+
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/csvreader"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/importer"
+  "dunn-finance/pkg/model"
+  "dunn-finance/pkg/scheduler"
+  "dunn-finance/pkg/validation"
+)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+// pipelineSteps are the steps a job may have, in the order they make sense.
+var pipelineSteps = []string{"fetch", "import", "indicators", "alerts"}
+
+// defaultJob runs when daemon.jobs of the config is empty: after the close
+// on trading days, it imports the CSV files downloaded since its last run.
+var defaultJob = config.Job{Name: "eod", Schedule: "45 15 * * 1-5", Steps: []string{"import", "indicators", "alerts"}}
+
+func runDaemon(ctx context.Context, cfg *config.Config, args []string) error {
+  fs := newFlagSet("daemon", "[flags]", `Runs the jobs of daemon.jobs in the config on their cron schedules, read in
+JST, until interrupted. Jobs run only on the days the Tokyo Stock Exchange
+is open unless all_days is set. Each job is a pipeline of the steps fetch,
+import, indicators and alerts, and each run is recorded in the jobs table.
+Without jobs in the config, the job eod imports the files of data.csv_dir
+at 15:45 on trading days, then fills the indicators and evaluates the alerts
+of the imported codes. A job that fetches too:
+  daemon:
+    jobs:
+      - name: eod
+        schedule: 45 15 * * 1-5
+        steps: [fetch, import, indicators, alerts]
+        watchlist: core`)
+  db := addDBFlags(fs, cfg)
+  runName := fs.String("run", "", "Run the job of this name once now and exit")
+  list := fs.Bool("list", false, "List the jobs with their next runs and the recent runs, and exit")
+  if err := parseFlags(fs, args); err != nil { return err }
+
+  conn, err := db.open()
+  if err != nil { return err }
+  defer conn.Close()
+
+  jobConfigs := cfg.Daemon.Jobs
+  if len(jobConfigs) == 0 { jobConfigs = []config.Job{defaultJob} }
+  var jobs []*scheduler.Job
+  for _, jc := range jobConfigs {
+    job, err := newPipelineJob(cfg, conn, jc)
+    if err != nil { return fmt.Errorf("daemon.jobs: %w", err) }
+    jobs = append(jobs, job)
+  }
+  s := &scheduler.Scheduler{DB: conn, Jobs: jobs}
+
+  if *list { return listJobs(ctx, conn, jobs) }
+
+  if *runName != "" {
+    i := slices.IndexFunc(jobs, func(job *scheduler.Job) bool { return job.Name == *runName })
+    if i < 0 { return usagef("no job %s", *runName) }
+    run, err := s.RunJob(ctx, jobs[i], time.Now())
+    if err != nil { return err }
+    if run.Status == model.JobFailed { return errors.New(run.Error) }
+    return nil
+  }
+
+  log.Printf("[INFO] daemon starts with %d jobs\n", len(jobs))
+  if err := s.Run(ctx); err != nil { return err }
+  log.Println("[INFO] daemon stopped")
+  return nil
+}
+
+// pipeline runs the steps of a job. Each step runs even when one before it
+// failed, on what that step got done.
+type pipeline struct {
+  cfg *config.Config
+  db  database.DBConnector
+  job config.Job
+}
+
+func newPipelineJob(cfg *config.Config, db database.DBConnector, jc config.Job) (*scheduler.Job, error) {
+  if jc.Name == "" { return nil, errors.New("a job has no name") }
+  schedule, err := scheduler.ParseSchedule(jc.Schedule)
+  if err != nil { return nil, fmt.Errorf("%s: %w", jc.Name, err) }
+  if len(jc.Steps) == 0 { return nil, fmt.Errorf("%s: no steps", jc.Name) }
+  for _, step := range jc.Steps {
+    if !slices.Contains(pipelineSteps, step) { return nil, fmt.Errorf("%s: unknown step %q: use %s", jc.Name, step, strings.Join(pipelineSteps, ", ")) }
+  }
+
+  p := &pipeline{cfg: cfg, db: db, job: jc}
+  return &scheduler.Job{Name: jc.Name, Schedule: schedule, AllDays: jc.AllDays, Run: p.run}, nil
+}
+
+func (p *pipeline) run(ctx context.Context, last *model.Job) (string, error) {
+  codes, err := selectCodes(ctx, p.db, strings.Join(p.job.Codes, ","), p.job.Watchlist)
+  if err != nil { return "", err }
+
+  // updated are the codes fetched or imported. indicators and alerts cover
+  // them, or the codes of the job when it neither fetches nor imports.
+  var updated []string
+  covered := func() ([]string, error) {
+    if slices.Contains(p.job.Steps, "fetch") || slices.Contains(p.job.Steps, "import") { return updated, nil }
+    if len(codes) > 0 { return codes, nil }
+    return allCodes(ctx, p.db)
+  }
+
+  var summaries []string
+  var errs []error
+  for _, step := range p.job.Steps {
+    var summary string
+    var stepCodes []string
+    var err error
+    switch step {
+      case "fetch":
+        stepCodes, summary, err = p.fetch(ctx, codes, last)
+      case "import":
+        stepCodes, summary, err = p.importFiles(ctx, codes, last)
+      case "indicators":
+        if stepCodes, err = covered(); err != nil || len(stepCodes) == 0 { break }
+        var n int
+        n, err = fillCodes(ctx, p.db, stepCodes)
+        summary = fmt.Sprintf("%d bars of %d codes", n, len(stepCodes))
+        stepCodes = nil
+      case "alerts":
+        if stepCodes, err = covered(); err != nil || len(stepCodes) == 0 { break }
+        var n int
+        n, err = runAlerts(ctx, p.cfg, p.db, stepCodes)
+        summary = fmt.Sprintf("%d triggered", n)
+        stepCodes = nil
+    }
+    for _, code := range stepCodes {
+      if !slices.Contains(updated, code) { updated = append(updated, code) }
+    }
+
+    if summary == "" { summary = "nothing to do" }
+    summaries = append(summaries, step+": "+summary)
+    if err != nil { errs = append(errs, fmt.Errorf("%s: %w", step, err)) }
+    if ctx.Err() != nil { break }
+  }
+
+  return strings.Join(summaries, "; "), errors.Join(errs...)
+}
+
+// fetch fetches the codes. J-Quants is asked for the week before the last
+// run on, to pick up corrections, and for everything before the first run.
+func (p *pipeline) fetch(ctx context.Context, codes []string, last *model.Job) ([]string, string, error) {
+  if len(codes) == 0 { return nil, "", nil }
+
+  source := p.job.Source
+  if source == "" { source = p.cfg.Browser.Site }
+  var from string
+  if last != nil { from = model.DateOf(last.ScheduledAt.In(jst)).AddDays(-7).String() }
+
+  fetch, closeFetcher, err := newFetcher(ctx, p.cfg, p.db, source, p.cfg.Browser.Fetcher, from, "")
+  defer closeFetcher()
+  if err != nil { return nil, "", err }
+
+  fetched, err := fetchCodes(ctx, fetch, codes)
+  return fetched, fmt.Sprintf("%d of %d codes from %s", len(fetched), len(codes), source), err
+}
+
+// importFiles imports the CSV files of data.csv_dir modified since the last
+// run, only those of the codes when there are codes.
+func (p *pipeline) importFiles(ctx context.Context, codes []string, last *model.Job) ([]string, string, error) {
+  if p.cfg.Data.CSVDir == "" { return nil, "", nil }
+
+  paths, err := importer.ExpandPaths(p.cfg.Data.CSVDir)
+  if err != nil { return nil, "", err }
+  paths = slices.DeleteFunc(paths, func(path string) bool {
+    if last != nil {
+      info, err := os.Stat(path)
+      if err == nil && info.ModTime().Before(last.StartedAt) { return true }
+    }
+    if len(codes) == 0 { return false }
+    code, err := csvreader.DetectCode(path)
+    return err == nil && !slices.Contains(codes, code)
+  })
+  if len(paths) == 0 { return nil, "", nil }
+
+  results := importer.ImportFiles(ctx, paths, csvreader.SBIFieldMap, true, 4, validation.PolicyRejectRow, p.db)
+  rows := 0
+  for _, r := range results {
+    rows += r.Inserted
+    if r.Err != nil { log.Printf("[ERROR] %s: %v\n", r.Path, r.Err) }
+  }
+  imported, err := importedCodes(results)
+  return imported, fmt.Sprintf("%d rows from %d files", rows, len(results)), err
+}
+
+func listJobs(ctx context.Context, db database.Querier, jobs []*scheduler.Job) error {
+  w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+  fmt.Fprintln(w, "JOB\tSCHEDULE\tDAYS\tNEXT")
+  for _, job := range jobs {
+    days := "trading"
+    if job.AllDays { days = "all" }
+    next := "never"
+    if t := job.Next(time.Now()); !t.IsZero() { next = t.Format("2006/01/02 15:04 MST") }
+    fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", job.Name, job.Schedule, days, next)
+  }
+  if err := w.Flush(); err != nil { return err }
+
+  runs, err := dao.NewJobDAO(db).FindRecent(ctx, "", 20)
+  if err != nil { return err }
+  if len(runs) == 0 { return nil }
+
+  fmt.Println()
+  w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+  fmt.Fprintln(w, "STARTED\tJOB\tSTATUS\tTIME\tSUMMARY")
+  for _, run := range runs {
+    duration := "-"
+    if run.FinishedAt != nil { duration = run.FinishedAt.Sub(run.StartedAt).Round(time.Second).String() }
+    summary := run.Summary
+    if run.Error != "" { summary = strings.TrimPrefix(summary+"; error: "+run.Error, "; ") }
+    fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", run.StartedAt.In(jst).Format("2006/01/02 15:04"), run.Name, run.Status, duration, summary)
+  }
+  return w.Flush()
+}
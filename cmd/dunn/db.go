@@ -0,0 +1,75 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "log"
+  "os"
+  "path/filepath"
+  "time"
+
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/database"
+)
+
+var dbCommands = []subcommand{
+  {"backup", "Copy the live database to -dir, then prune old backups by -keep and -max-age"},
+  {"restore", "Overwrite the database with -from after an integrity check"},
+  {"vacuum", "Rebuild the database file to reclaim free pages"},
+  {"analyze", "Refresh the query planner statistics"},
+  {"prune", "Remove backups in -dir by -keep and -max-age"},
+}
+
+func runDB(ctx context.Context, cfg *config.Config, args []string) error {
+  command, args, err := parseSubcommand("db", dbCommands, args)
+  if err != nil { return err }
+
+  fs := newFlagSet("db "+command, "[flags]", summaryOf(dbCommands, command)+". SQLite databases only.")
+  db := addDBFlags(fs, cfg)
+  dir := fs.String("dir", "", "Backup directory (default: the directory of -dbpath)")
+  from := fs.String("from", "", "Backup file to restore from")
+  keep := fs.Int("keep", 7, "Number of newest backups always kept. 0 disables the rule")
+  maxAge := fs.Duration("max-age", 0, "Backups younger than this are kept too, e.g. 720h. 0 disables the rule")
+  if err := parseFlags(fs, args); err != nil { return err }
+
+  if *db.path == "" { return usagef("no database: set db.path in the config, DUNN_DB_PATH or -dbpath") }
+  if *db.driver != "sqlite3" { return usagef("dunn db supports sqlite3 only, not %s", *db.driver) }
+  if command == "restore" && *from == "" { return usagef("specify the backup file with -from") }
+  if *dir == "" { *dir = filepath.Dir(*db.path) }
+
+  dbManager := &database.DBManager{ Driver: *db.driver, DSN: *db.path }
+  defer dbManager.GetDBInstance().Close()
+
+  policy := database.RetentionPolicy{Keep: *keep, MaxAge: *maxAge}
+
+  switch command {
+    case "backup":
+      if err := os.MkdirAll(*dir, 0o755); err != nil { return err }
+      now := time.Now()
+      dest := filepath.Join(*dir, database.BackupName(*db.path, now))
+      if err := dbManager.Backup(ctx, dest); err != nil { return fmt.Errorf("failed to back up: %w", err) }
+      log.Printf("[INFO] Backed up %s to %s\n", *db.path, dest)
+      return pruneBackups(*dir, *db.path, policy, now)
+    case "restore":
+      if err := dbManager.Restore(ctx, *from); err != nil { return fmt.Errorf("failed to restore: %w", err) }
+      log.Printf("[INFO] Restored %s from %s\n", *db.path, *from)
+    case "vacuum":
+      if err := dbManager.Vacuum(ctx); err != nil { return fmt.Errorf("failed to vacuum: %w", err) }
+      log.Printf("[INFO] Vacuumed %s\n", *db.path)
+    case "analyze":
+      if err := dbManager.Analyze(ctx); err != nil { return fmt.Errorf("failed to analyze: %w", err) }
+      log.Printf("[INFO] Analyzed %s\n", *db.path)
+    case "prune":
+      return pruneBackups(*dir, *db.path, policy, time.Now())
+  }
+  return nil
+}
+
+func pruneBackups(dir string, dbPath string, policy database.RetentionPolicy, now time.Time) error {
+  removed, err := database.PruneBackups(dir, dbPath, policy, now)
+  for _, path := range removed {
+    log.Printf("[INFO] Removed backup %s\n", path)
+  }
+  if err != nil { return fmt.Errorf("failed to prune backups: %w", err) }
+  return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+  "context"
+  "log"
+  "path/filepath"
+
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/export"
+  "dunn-finance/pkg/model"
+)
+
+func runExport(ctx context.Context, cfg *config.Config, args []string) error {
+  fs := newFlagSet("export", "[flags]", `Exports the stored bars to a Parquet or Arrow file, or to a directory of
+files partitioned by code or year.`)
+  db := addDBFlags(fs, cfg)
+  codes := fs.String("codes", "", "Comma separated stock codes. Empty exports all codes")
+  watchlist := fs.String("watchlist", "", "Name of the watchlist whose codes are exported, with those of -codes")
+  from := fs.String("from", "", "First date to export in yyyymmdd")
+  to := fs.String("to", "", "Last date to export in yyyymmdd")
+  format := fs.String("format", "parquet", "Output format: parquet or arrow")
+  partition := fs.String("partition", "none", "Partition by code, year or none")
+  out := fs.String("out", "", "Output file, or the output directory when partitioned. Defaults to data.export_dir of the config")
+  if err := parseFlags(fs, args); err != nil { return err }
+
+  if *out == "" && cfg.Data.ExportDir != "" {
+    *out = cfg.Data.ExportDir
+    if *partition == "none" { *out = filepath.Join(cfg.Data.ExportDir, "adjusted_daily_ohlcvs."+*format) }
+  }
+  if *out == "" { return usagef("specify -out or data.export_dir in the config") }
+  if *partition == "none" { *partition = "" }
+
+  var fromDate, toDate model.Date
+  var err error
+  if *from != "" {
+    if fromDate, err = model.ParseDate(*from); err != nil { return usagef("%v", err) }
+  }
+  if *to != "" {
+    if toDate, err = model.ParseDate(*to); err != nil { return usagef("%v", err) }
+  }
+
+  conn, err := db.open()
+  if err != nil { return err }
+  defer conn.Close()
+
+  codeList, err := selectCodes(ctx, conn, *codes, *watchlist)
+  if err != nil { return err }
+
+  rows := dao.NewAdjustedDailyOHLCVDAO(conn).IterateFiltered(ctx, codeList, fromDate, toDate)
+  opts := export.Options{Format: export.Format(*format), Partition: export.Partition(*partition)}
+  result, err := export.Export(ctx, rows, *out, opts)
+  if err != nil { return err }
+
+  for _, file := range result.Files {
+    log.Printf("[INFO] wrote %s\n", file)
+  }
+  log.Printf("[INFO] %d rows exported\n", result.Rows)
+  return nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "log"
+  "os"
+  "strings"
+  "text/tabwriter"
+  "time"
+
+  "github.com/go-rod/rod"
+
+  "dunn-finance/pkg/browser"
+  "dunn-finance/pkg/browser/httpfetcher"
+  "dunn-finance/pkg/browser/sites"
+  _ "dunn-finance/pkg/browser/sites/kabutan"
+  _ "dunn-finance/pkg/browser/sites/sbisec"
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/credentials"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/jquants"
+  "dunn-finance/pkg/model"
+)
+
+func runFetch(ctx context.Context, cfg *config.Config, args []string) error {
+  fs := newFlagSet("fetch", "[flags] -codes CODES | -watchlist NAME", fmt.Sprintf(`Fetches daily bars and upserts their prices and volume, keeping the moving
+averages already stored. -source is jquants, which needs JQUANTS_REFRESH_TOKEN
+or the jquants credential, or one of the sites: %s.
+Sites are read with the browser settings of the config.`, strings.Join(sites.Names(), ", ")))
+  db := addDBFlags(fs, cfg)
+  source := fs.String("source", cfg.Browser.Site, "jquants or a site name. Defaults to browser.site of the config")
+  codes := fs.String("codes", "", "Comma separated stock codes")
+  watchlist := fs.String("watchlist", "", "Name of the watchlist whose codes are fetched, with those of -codes")
+  from := fs.String("from", "", "First date with jquants (yyyymmdd)")
+  to := fs.String("to", "", "Last date with jquants (yyyymmdd)")
+  fetcherMode := fs.String("fetcher", cfg.Browser.Fetcher, "How sites are read: http, browser, or auto (http with browser fallback)")
+  printOnly := fs.Bool("print", false, "Print the bars fetched from a site instead of storing them")
+  if err := parseFlags(fs, args); err != nil { return err }
+
+  if *codes == "" && *watchlist == "" { return usagef("specify -codes or -watchlist") }
+  if *printOnly && *source == "jquants" { return usagef("-print works with sites only, not jquants") }
+
+  // Printed bars need the database only for the codes of the watchlist.
+  var conn database.DBConnector
+  if !*printOnly || *watchlist != "" {
+    var err error
+    if conn, err = db.open(); err != nil { return err }
+    defer conn.Close()
+  }
+
+  codeList, err := selectCodes(ctx, conn, *codes, *watchlist)
+  if err != nil { return err }
+
+  var fetch func(code string) (string, error)
+  var closeFetcher func()
+  if *printOnly {
+    fetch, closeFetcher, err = sitePrint(cfg, *source, *fetcherMode)
+  } else {
+    fetch, closeFetcher, err = newFetcher(ctx, cfg, conn, *source, *fetcherMode, *from, *to)
+  }
+  defer closeFetcher()
+  if err != nil { return err }
+
+  _, err = fetchCodes(ctx, fetch, codeList)
+  return err
+}
+
+// newFetcher returns the function fetching and storing the bars of a code
+// from the source, and the function closing the browser it may launch,
+// which is never nil.
+func newFetcher(ctx context.Context, cfg *config.Config, db database.DBConnector, source string, fetcherMode string, from string, to string) (func(code string) (string, error), func(), error) {
+  if source == "jquants" {
+    fetch, err := jquantsFetch(ctx, cfg, db, from, to)
+    return fetch, func() {}, err
+  }
+  return siteFetch(ctx, cfg, db, source, fetcherMode)
+}
+
+// fetchCodes fetches each code, and returns those fetched. A failed code
+// does not stop the others.
+func fetchCodes(ctx context.Context, fetch func(code string) (string, error), codes []string) ([]string, error) {
+  var fetched []string
+  for _, code := range codes {
+    summary, err := fetch(code)
+    if err != nil {
+      if ctx.Err() != nil { return fetched, err }
+      log.Printf("[ERROR] %s: %v\n", code, err)
+      continue
+    }
+    log.Printf("[INFO] %s: %s\n", code, summary)
+    fetched = append(fetched, code)
+  }
+  if failed := len(codes) - len(fetched); failed > 0 { return fetched, fmt.Errorf("%d of %d codes failed", failed, len(codes)) }
+  return fetched, nil
+}
+
+func jquantsFetch(ctx context.Context, cfg *config.Config, db database.DBConnector, from string, to string) (func(code string) (string, error), error) {
+  baseURL := cfg.JQuants.BaseURL
+  if baseURL == "" { baseURL = jquants.DefaultBaseURL }
+  client := jquants.NewClient(baseURL)
+  client.RefreshToken = os.Getenv("JQUANTS_REFRESH_TOKEN")
+  // The mail address and password are stored as user ID and password of "jquants".
+  if cred, err := credentials.Lookup("jquants"); err == nil {
+    client.MailAddress = cred.UserID
+    client.Password = cred.Password
+  }
+  if client.RefreshToken == "" && client.MailAddress == "" { return nil, errors.New("set JQUANTS_REFRESH_TOKEN or store the jquants login with dunn creds set -site jquants") }
+
+  importer := &jquants.Importer{Client: client, DB: db}
+  return func(code string) (string, error) {
+    result, err := importer.Import(ctx, code, from, to)
+    if err != nil { return "", err }
+    return fmt.Sprintf("%d quotes and %d adjustment factors as import %d", result.Quotes, result.Factors, result.ImportID), nil
+  }, nil
+}
+
+// newSiteAdapter returns the adapter of the site, logged in when the site
+// needs it, and the function closing the browser it may launch, which is
+// never nil.
+func newSiteAdapter(cfg *config.Config, site string, fetcherMode string) (sites.SiteAdapter, func(), error) {
+  var browserInstance *rod.Browser
+  closeBrowser := func() {
+    if browserInstance != nil { browserInstance.Close() }
+  }
+  newBrowserFetcher := func() (sites.Fetcher, error) {
+    log.Println("[INFO] Launching browser.")
+    browserInstance = browser.NewBrowserWithOptions(browser.Options{Bin: cfg.Browser.Bin, Headless: cfg.Browser.Headless, UserDataDir: cfg.Browser.UserDataDir})
+    return &browser.PageFetcher{Browser: browserInstance}, nil
+  }
+
+  var fetcher sites.Fetcher
+  switch fetcherMode {
+    case "http":
+      fetcher = httpfetcher.New()
+    case "browser":
+      fetcher, _ = newBrowserFetcher()
+    case "auto":
+      fetcher = &sites.FallbackFetcher{
+        Primary:        httpfetcher.New(),
+        NewFallback:    newBrowserFetcher,
+        ShouldFallback: func(err error) bool { return errors.Is(err, httpfetcher.ErrJavaScriptRequired) },
+      }
+    default:
+      return nil, closeBrowser, usagef("unknown -fetcher %q: use http, browser or auto", fetcherMode)
+  }
+
+  adapter, err := sites.New(site, fetcher)
+  if err != nil { return nil, closeBrowser, usagef("%v", err) }
+  if authenticator, ok := adapter.(sites.Authenticator); ok {
+    cred, err := credentials.Lookup(site)
+    if err != nil { return nil, closeBrowser, fmt.Errorf("failed to look up credential for %s: %w", site, err) }
+    if err := authenticator.Login(cred); err != nil { return nil, closeBrowser, fmt.Errorf("failed to log in to %s: %w", site, err) }
+  }
+  return adapter, closeBrowser, nil
+}
+
+func siteFetch(ctx context.Context, cfg *config.Config, db database.DBConnector, site string, fetcherMode string) (func(code string) (string, error), func(), error) {
+  adapter, closeBrowser, err := newSiteAdapter(cfg, site, fetcherMode)
+  if err != nil { return nil, closeBrowser, err }
+
+  return func(code string) (string, error) {
+    records, err := adapter.FetchDailyOHLCV(code)
+    if err != nil { return "", err }
+
+    var imp *model.Import
+    err = db.WithTx(ctx, func(tx database.Querier) error {
+      imp = &model.Import{Source: site, Code: code, ImportedAt: time.Now(), RowsRead: len(records), RowsWritten: len(records)}
+      if err := dao.NewImportDAO(tx).Create(ctx, imp); err != nil { return err }
+      if err := dao.NewStockDAO(tx).Register(ctx, code); err != nil { return err }
+      ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(tx)
+      for _, record := range records {
+        record.ImportID = &imp.ID
+        if err := ohlcvDao.UpsertPrices(ctx, record); err != nil { return fmt.Errorf("failed to write %s: %w", record.Yyyymmdd, err) }
+      }
+      return nil
+    })
+    if err != nil { return "", err }
+    return fmt.Sprintf("%d bars from %s as import %d", len(records), site, imp.ID), nil
+  }, closeBrowser, nil
+}
+
+// sitePrint returns the function printing the bars of a code fetched from
+// the site, without storing them.
+func sitePrint(cfg *config.Config, site string, fetcherMode string) (func(code string) (string, error), func(), error) {
+  adapter, closeBrowser, err := newSiteAdapter(cfg, site, fetcherMode)
+  if err != nil { return nil, closeBrowser, err }
+
+  return func(code string) (string, error) {
+    records, err := adapter.FetchDailyOHLCV(code)
+    if err != nil { return "", err }
+
+    tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(tw, "CODE\tDATE\tOPEN\tHIGH\tLOW\tCLOSE\tVOLUME")
+    for _, r := range records {
+      fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", code, r.Yyyymmdd, formatFloat(r.OpenPrice, "%g"), formatFloat(r.HighPrice, "%g"), formatFloat(r.LowPrice, "%g"), formatFloat(r.ClosePrice, "%g"), formatFloat(r.Volume, "%g"))
+    }
+    if err := tw.Flush(); err != nil { return "", err }
+    return fmt.Sprintf("%d bars from %s, not stored", len(records), site), nil
+  }, closeBrowser, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+  "context"
+  "database/sql"
+  "errors"
+  "fmt"
+  "os"
+  "text/tabwriter"
+  "time"
+
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/model"
+)
+
+func runHistory(ctx context.Context, cfg *config.Config, args []string) error {
+  fs := newFlagSet("history", "-code CODE -date YYYYMMDD [flags]", `Prints the revisions of one adjusted daily OHLCV, oldest first, ending
+with the current values, and the import that wrote each of them.`)
+  db := addDBFlags(fs, cfg)
+  code := fs.String("code", "", "stock code")
+  date := fs.String("date", "", "Date of the bar in yyyymmdd")
+  if err := parseFlags(fs, args); err != nil { return err }
+
+  if *code == "" { return usagef("specify the stock code with -code") }
+  if *date == "" { return usagef("specify the date with -date") }
+
+  yyyymmdd, err := model.ParseDate(*date)
+  if err != nil { return usagef("%v", err) }
+
+  conn, err := db.open()
+  if err != nil { return err }
+  defer conn.Close()
+
+  revisions, err := dao.NewAdjustedDailyOHLCVHistoryDAO(conn).FindRevisions(ctx, *code, yyyymmdd)
+  if err != nil { return fmt.Errorf("failed to load history: %w", err) }
+
+  current, err := dao.NewAdjustedDailyOHLCVDAO(conn).Find(ctx, *code, yyyymmdd)
+  if errors.Is(err, sql.ErrNoRows) {
+    current = nil
+  } else if err != nil {
+    return fmt.Errorf("failed to load %s %s: %w", *code, yyyymmdd, err)
+  }
+  if current == nil && len(revisions) == 0 { return fmt.Errorf("no data for %s %s", *code, yyyymmdd) }
+
+  importDao := dao.NewImportDAO(conn)
+  imports := map[int64]*model.Import{}
+  describe := func(id *int64) string {
+    if id == nil { return "-" }
+    imp, ok := imports[*id]
+    if !ok {
+      imp, err = importDao.Find(ctx, *id)
+      if err != nil { return fmt.Sprintf("%d", *id) }
+      imports[*id] = imp
+    }
+    source := imp.Source
+    if imp.FilePath != "" { source = imp.FilePath }
+    return fmt.Sprintf("%d %s %s", imp.ID, imp.ImportedAt.Format(time.RFC3339), source)
+  }
+
+  tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+  fmt.Fprintln(tw, "REPLACED AT\tOPEN\tHIGH\tLOW\tCLOSE\tVOLUME\tIMPORT")
+  for _, r := range revisions {
+    fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.ReplacedAt.Format(time.RFC3339), formatFloat(r.OpenPrice, "%g"), formatFloat(r.HighPrice, "%g"), formatFloat(r.LowPrice, "%g"), formatFloat(r.ClosePrice, "%g"), formatFloat(r.Volume, "%g"), describe(r.ImportID))
+  }
+  if current != nil {
+    fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", "current", formatFloat(current.OpenPrice, "%g"), formatFloat(current.HighPrice, "%g"), formatFloat(current.LowPrice, "%g"), formatFloat(current.ClosePrice, "%g"), formatFloat(current.Volume, "%g"), describe(current.ImportID))
+  }
+  return tw.Flush()
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "log"
+  "os"
+  "slices"
+  "time"
+
+  "dunn-finance/pkg/alert"
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/csvreader"
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/importer"
+  "dunn-finance/pkg/validation"
+)
+
+func runImport(ctx context.Context, cfg *config.Config, args []string) error {
+  fs := newFlagSet("import", "[flags] [-csvpath FILE -code CODE | -dir DIR]", `Imports SBI time chart CSV files. -csvpath imports one file as -code, or the code
+detected from the file. -dir imports every *.csv of a directory or glob, by
+default data.csv_dir of the config. The alert rules of the imported codes are
+evaluated afterwards.`)
+  db := addDBFlags(fs, cfg)
+  csvPath := fs.String("csvpath", "", "Path to one CSV file")
+  code := fs.String("code", "", "Stock code of -csvpath. Detected from the file when empty")
+  dir := fs.String("dir", cfg.Data.CSVDir, "Directory or glob of CSV files. The code is detected per file")
+  watchlist := fs.String("watchlist", "", "With -dir, import only the files of the codes on this watchlist")
+  isSkipHeader := fs.Bool("skip-header", true, "Whether to skip the header row")
+  workers := fs.Int("workers", 4, "Number of files parsed concurrently")
+  onInvalid := fs.String("on-invalid", "reject-row", "What to do with invalid rows: reject-row, reject-file or warn")
+  alerts := fs.Bool("alerts", true, "Evaluate the alert rules of the imported codes")
+  if err := parseFlags(fs, args); err != nil { return err }
+
+  policy, err := validation.ParsePolicy(*onInvalid)
+  if err != nil { return usagef("%v", err) }
+  if *csvPath == "" && *dir == "" { return usagef("specify -csvpath or -dir") }
+  if *csvPath != "" && *watchlist != "" { return usagef("-watchlist needs -dir") }
+
+  conn, err := db.open()
+  if err != nil { return err }
+  defer conn.Close()
+
+  var results []*importer.FileResult
+  if *csvPath != "" {
+    results = []*importer.FileResult{importer.ImportFile(ctx, *csvPath, *code, csvreader.SBIFieldMap, *isSkipHeader, policy, conn)}
+  } else {
+    paths, err := importer.ExpandPaths(*dir)
+    if err != nil { return err }
+    if *watchlist != "" {
+      codes, err := selectCodes(ctx, conn, "", *watchlist)
+      if err != nil { return err }
+      paths = slices.DeleteFunc(paths, func(path string) bool {
+        code, err := csvreader.DetectCode(path)
+        return err == nil && !slices.Contains(codes, code)
+      })
+    }
+    log.Printf("[INFO] dir: %s, files: %d, workers: %d\n", *dir, len(paths), *workers)
+    results = importer.ImportFiles(ctx, paths, csvreader.SBIFieldMap, *isSkipHeader, *workers, policy, conn)
+  }
+  if err := importer.PrintResults(os.Stdout, results); err != nil { return err }
+
+  codes, err := importedCodes(results)
+  if *alerts && len(codes) > 0 {
+    if _, alertErr := runAlerts(ctx, cfg, conn, codes); alertErr != nil { log.Printf("[WARN] Alerts: %v\n", alertErr) }
+  }
+  return err
+}
+
+// importedCodes returns the codes with rows inserted, and an error telling
+// how many files failed, if any.
+func importedCodes(results []*importer.FileResult) ([]string, error) {
+  var codes []string
+  failed := 0
+  for _, r := range results {
+    if r.Err != nil { failed++ }
+    if r.Inserted > 0 && !slices.Contains(codes, r.Code) { codes = append(codes, r.Code) }
+  }
+
+  if failed > 0 { return codes, fmt.Errorf("%d of %d files failed", failed, len(results)) }
+  return codes, nil
+}
+
+// runAlerts evaluates the alert rules of the codes and delivers those
+// triggered.
+func runAlerts(ctx context.Context, cfg *config.Config, db database.Querier, codes []string) (int, error) {
+  notifiers, err := notifiersOf(cfg)
+  if err != nil { return 0, err }
+
+  triggered, err := alert.Run(ctx, db, codes, notifiers, time.Now())
+  log.Printf("[INFO] %d alerts triggered\n", len(triggered))
+  return len(triggered), err
+}
+
+// notifiersOf delivers alerts to stdout and the notifiers of the config.
+func notifiersOf(cfg *config.Config) (alert.Notifiers, error) {
+  c := &alert.Config{Stdout: os.Stdout, File: cfg.Alerts.File, Webhook: cfg.Alerts.Webhook, SMTPAddr: cfg.Alerts.SMTPAddr, MailFrom: cfg.Alerts.MailFrom, MailTo: cfg.Alerts.MailTo}
+  return c.Notifiers()
+}
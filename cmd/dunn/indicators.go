@@ -0,0 +1,85 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "log"
+  "slices"
+
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/indicator"
+  "dunn-finance/pkg/model"
+)
+
+func runIndicators(ctx context.Context, cfg *config.Config, args []string) error {
+  fs := newFlagSet("indicators", "[flags]", `Computes the 5, 25 and 75 day moving averages of the close and the 5 and 25
+day moving averages of the volume where stored bars lack them, as bars
+fetched from J-Quants or a site do. Averages already stored are kept.`)
+  db := addDBFlags(fs, cfg)
+  codes := fs.String("codes", "", "Comma separated stock codes. Empty fills every code")
+  watchlist := fs.String("watchlist", "", "Name of the watchlist whose codes are filled, with those of -codes")
+  if err := parseFlags(fs, args); err != nil { return err }
+
+  conn, err := db.open()
+  if err != nil { return err }
+  defer conn.Close()
+
+  codeList, err := selectCodes(ctx, conn, *codes, *watchlist)
+  if err != nil { return err }
+  if len(codeList) == 0 {
+    if codeList, err = allCodes(ctx, conn); err != nil { return err }
+  }
+
+  _, err = fillCodes(ctx, conn, codeList)
+  return err
+}
+
+// allCodes returns the codes with bars, in order.
+func allCodes(ctx context.Context, db database.Querier) ([]string, error) {
+  latest, err := dao.NewAdjustedDailyOHLCVDAO(db).LatestDates(ctx)
+  if err != nil { return nil, err }
+
+  codes := make([]string, 0, len(latest))
+  for code := range latest {
+    codes = append(codes, code)
+  }
+  slices.Sort(codes)
+
+  return codes, nil
+}
+
+// fillCodes fills the moving averages of the codes and returns the number
+// of bars filled.
+func fillCodes(ctx context.Context, db database.DBConnector, codes []string) (int, error) {
+  total := 0
+  for _, code := range codes {
+    n, err := fillMovingAverages(ctx, db, code)
+    if err != nil { return total, fmt.Errorf("%s: %w", code, err) }
+    log.Printf("[INFO] %s: filled %d bars\n", code, n)
+    total += n
+  }
+  return total, nil
+}
+
+// fillMovingAverages fills the bars of the code in one transaction.
+func fillMovingAverages(ctx context.Context, db database.DBConnector, code string) (int, error) {
+  var bars []*model.AdjustedDailyOHLCV
+  for bar, err := range dao.NewAdjustedDailyOHLCVDAO(db).IterateFiltered(ctx, []string{code}, model.Date{}, model.Date{}) {
+    if err != nil { return 0, err }
+    bars = append(bars, bar)
+  }
+
+  changed := indicator.FillMovingAverages(bars)
+  err := db.WithTx(ctx, func(tx database.Querier) error {
+    ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(tx)
+    for _, bar := range changed {
+      if err := ohlcvDao.UpdateMovingAverages(ctx, bar); err != nil { return err }
+    }
+    return nil
+  })
+  if err != nil { return 0, err }
+
+  return len(changed), nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "flag"
+  "fmt"
+  "log"
+  "os"
+  "os/signal"
+  "syscall"
+
+  _ "github.com/lib/pq"
+  _ "github.com/mattn/go-sqlite3"
+
+  "dunn-finance/pkg/config"
+)
+
+// Exit codes of every subcommand.
+const (
+  exitOK      = 0
+  exitFailure = 1
+  exitUsage   = 2
+)
+
+type command struct {
+  name    string
+  summary string
+  run     func(ctx context.Context, cfg *config.Config, args []string) error
+}
+
+var commands = []*command{
+  {"import", "Import SBI CSV files into the database", runImport},
+  {"fetch", "Fetch daily bars from J-Quants or a broker site into the database", runFetch},
+  {"indicators", "Fill in the moving averages missing from stored bars", runIndicators},
+  {"screen", "List the codes whose latest bar meets conditions", runScreen},
+  {"history", "Print the revisions of one bar and the imports that wrote them", runHistory},
+  {"chart", "Render a candlestick chart of a code to SVG or PNG", runChart},
+  {"report", "Write HTML performance reports of codes", runReport},
+  {"tui", "Browse codes and their bars in the terminal", runTUI},
+  {"export", "Export bars to Parquet or Arrow files", runExport},
+  {"watchlist", "Create and edit watchlists", runWatchlist},
+  {"alert", "Manage alert rules and print triggered alerts", runAlert},
+  {"serve", "Serve the JSON and gRPC APIs", runServe},
+  {"migrate", "Create or update the database schema", runMigrate},
+  {"daemon", "Run the scheduled import pipelines until interrupted", runDaemon},
+  {"db", "Back up, restore and maintain a SQLite database", runDB},
+  {"creds", "Store the logins of sites, encrypted", runCreds},
+}
+
+const usageHeader = `Usage: dunn [-config PATH] <command> [flags] [args]
+
+Settings are read from -config, $DUNN_CONFIG or config.yaml in the user
+config dir, then overridden by DUNN_* env vars and the flags of the command.
+
+Commands:`
+
+func usage() {
+  fmt.Fprintln(os.Stderr, usageHeader)
+  for _, c := range commands {
+    fmt.Fprintf(os.Stderr, "  %-11s %s\n", c.name, c.summary)
+  }
+  fmt.Fprintln(os.Stderr, "\nRun 'dunn <command> --help' for the flags of a command.")
+}
+
+func main() {
+  os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+  fs := flag.NewFlagSet("dunn", flag.ContinueOnError)
+  configPath := fs.String("config", "", "Path to the config file")
+  fs.Usage = func() {
+    usage()
+    fmt.Fprintln(os.Stderr, "\nFlags:")
+    fs.PrintDefaults()
+  }
+  if err := fs.Parse(args); err != nil {
+    if errors.Is(err, flag.ErrHelp) { return exitOK }
+    return exitUsage
+  }
+
+  args = fs.Args()
+  if len(args) == 0 {
+    usage()
+    return exitUsage
+  }
+  name := args[0]
+  if name == "help" {
+    if len(args) < 2 {
+      usage()
+      return exitOK
+    }
+    name, args = args[1], []string{args[1], "--help"}
+  }
+
+  var cmd *command
+  for _, c := range commands {
+    if c.name == name { cmd = c }
+  }
+  if cmd == nil {
+    fmt.Fprintf(os.Stderr, "dunn: unknown command %q\n\n", name)
+    usage()
+    return exitUsage
+  }
+
+  cfg, err := config.Load(*configPath)
+  if err != nil {
+    log.Printf("[ERROR] Failed to load config: %v\n", err)
+    return exitFailure
+  }
+
+  ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+  defer stop()
+
+  err = cmd.run(ctx, cfg, args[1:])
+  var ue *usageError
+  switch {
+    case err == nil, errors.Is(err, flag.ErrHelp):
+      return exitOK
+    case errors.Is(err, errFlags):
+      return exitUsage
+    case errors.As(err, &ue):
+      fmt.Fprintf(os.Stderr, "dunn %s: %v\nRun 'dunn %s --help' for usage.\n", name, ue.msg, name)
+      return exitUsage
+    default:
+      log.Printf("[ERROR] %s: %v\n", name, err)
+      return exitFailure
+  }
+}
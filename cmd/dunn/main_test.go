@@ -0,0 +1,67 @@
+package main
+
+import (
+  "context"
+  "encoding/json"
+  "net"
+  "net/http"
+  "os"
+  "path/filepath"
+  "testing"
+  "time"
+
+  "dunn-finance/pkg/api"
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/dto"
+)
+
+// TestSmoke_MigrateImportServe runs the commands on a new database as a user
+// would, so the schema of migrate has to fit import and serve.
+func TestSmoke_MigrateImportServe(t *testing.T) {
+  dir := t.TempDir()
+  configPath := filepath.Join(dir, "config.yaml")
+  if err := os.WriteFile(configPath, []byte("db:\n  path: "+filepath.Join(dir, "dunn.db")+"\n"), 0o600); err != nil { t.Fatal(err) }
+
+  if code := run([]string{"-config", configPath, "migrate"}); code != exitOK { t.Fatalf("migrate exited with %d", code) }
+  csvPath := filepath.Join("..", "..", "pkg", "importer", "testdata", "sbi_timechart_5253_20250720.csv")
+  if code := run([]string{"-config", configPath, "import", "-alerts=false", "-csvpath", csvPath}); code != exitOK { t.Fatalf("import exited with %d", code) }
+  if code := run([]string{"-config", configPath, "import", "-no-such-flag"}); code != exitUsage { t.Errorf("Expected exit %d for a bad flag, got %d", exitUsage, code) }
+
+  listener, err := net.Listen("tcp", "127.0.0.1:0")
+  if err != nil { t.Fatal(err) }
+  addr := listener.Addr().String()
+  listener.Close()
+
+  cfg, err := config.Load(configPath)
+  if err != nil { t.Fatal(err) }
+  ctx, cancel := context.WithCancel(context.Background())
+  served := make(chan error, 1)
+  go func() { served <- runServe(ctx, cfg, []string{"-addr", addr, "-grpc-addr", ""}) }()
+
+  var stocks api.Page[*dto.StockDTO]
+  getJSON(t, "http://"+addr+"/stocks", &stocks)
+  if len(stocks.Items) != 1 || stocks.Items[0].Code != "5253" { t.Errorf("Unexpected stocks: %+v", stocks) }
+  var bars api.Page[*dto.AdjustedDailyOHLCV]
+  getJSON(t, "http://"+addr+"/stocks/5253/ohlcv", &bars)
+  if bars.Total != 10 { t.Errorf("Expected the 10 imported bars, got %d", bars.Total) }
+
+  cancel()
+  if err := <-served; err != nil { t.Errorf("serve failed: %v", err) }
+}
+
+// getJSON retries until the server listens.
+func getJSON(t *testing.T, url string, v any) {
+  t.Helper()
+  deadline := time.Now().Add(5 * time.Second)
+  for {
+    res, err := http.Get(url)
+    if err == nil {
+      defer res.Body.Close()
+      if res.StatusCode != http.StatusOK { t.Fatalf("GET %s: status %d", url, res.StatusCode) }
+      if err := json.NewDecoder(res.Body).Decode(v); err != nil { t.Fatalf("GET %s: %v", url, err) }
+      return
+    }
+    if time.Now().After(deadline) { t.Fatalf("GET %s: %v", url, err) }
+    time.Sleep(20 * time.Millisecond)
+  }
+}
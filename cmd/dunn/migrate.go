@@ -0,0 +1,49 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "log"
+  "os"
+  "text/tabwriter"
+
+  schema "dunn-finance/configs/sql"
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/database"
+)
+
+func runMigrate(ctx context.Context, cfg *config.Config, args []string) error {
+  fs := newFlagSet("migrate", "[flags]", `Creates the tables, or the tables added since the last migrate. The schema
+files are built into the binary and recorded in schema_migrations as they run.`)
+  db := addDBFlags(fs, cfg)
+  status := fs.Bool("status", false, "List the schema files and when they ran instead of running them")
+  if err := parseFlags(fs, args); err != nil { return err }
+
+  fsys, err := schema.FS(*db.driver)
+  if err != nil { return err }
+
+  conn, err := db.open()
+  if err != nil { return err }
+  defer conn.Close()
+
+  if *status {
+    applied, err := database.AppliedMigrations(ctx, conn)
+    if err != nil { return err }
+    w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(w, "FILE\tAPPLIED AT")
+    for _, name := range schema.Files {
+      appliedAt := "pending"
+      if t, ok := applied[name]; ok { appliedAt = t.Local().Format("2006/01/02 15:04:05") }
+      fmt.Fprintf(w, "%s\t%s\n", name, appliedAt)
+    }
+    return w.Flush()
+  }
+
+  ran, err := database.Migrate(ctx, conn, fsys, schema.Files)
+  for _, name := range ran {
+    log.Printf("[INFO] ran %s\n", name)
+  }
+  if err != nil { return err }
+  log.Printf("[INFO] %d of %d schema files ran\n", len(ran), len(schema.Files))
+  return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+  "context"
+  "database/sql"
+  "errors"
+  "fmt"
+  "log"
+  "os"
+  "path/filepath"
+  "strings"
+  "time"
+
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/model"
+  "dunn-finance/pkg/report"
+)
+
+func runReport(ctx context.Context, cfg *config.Config, args []string) error {
+  fs := newFlagSet("report", "[flags]", `Writes self-contained HTML reports of the recent performance of codes:
+one file with every code using -out, or one file per code using -outdir.`)
+  db := addDBFlags(fs, cfg)
+  codes := fs.String("codes", "", "Comma separated stock codes")
+  watchlist := fs.String("watchlist", "", "Name of the watchlist whose codes are reported, after those of -codes")
+  out := fs.String("out", "", "Output HTML file with every code")
+  outDir := fs.String("outdir", "", "Output directory with one <code>.html per code. Defaults to data.report_dir of the config")
+  title := fs.String("title", "", "Title of the report. Defaults to the watchlist or the codes")
+  colors := fs.String("colors", "japanese", "Colors of rises and falls: japanese (red up) or western (green up)")
+  if err := parseFlags(fs, args); err != nil { return err }
+
+  if *codes == "" && *watchlist == "" { return usagef("specify the stock codes with -codes or -watchlist") }
+  if *out == "" && *outDir == "" { *outDir = cfg.Data.ReportDir }
+  if (*out == "") == (*outDir == "") { return usagef("specify either -out or -outdir") }
+
+  chartColors, err := parseChartColors(*colors)
+  if err != nil { return err }
+
+  conn, err := db.open()
+  if err != nil { return err }
+  defer conn.Close()
+
+  codeList, err := selectCodes(ctx, conn, *codes, *watchlist)
+  if err != nil { return err }
+
+  stockDao := dao.NewStockDAO(conn)
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(conn)
+  var items []*report.Item
+  for _, code := range codeList {
+    stock, err := stockDao.Find(ctx, code)
+    if errors.Is(err, sql.ErrNoRows) {
+      stock = &model.Stock{Code: code}
+    } else if err != nil {
+      return fmt.Errorf("failed to load stock %s: %w", code, err)
+    }
+
+    var bars []*model.AdjustedDailyOHLCV
+    for bar, err := range ohlcvDao.IterateFiltered(ctx, []string{code}, model.Date{}, model.Date{}) {
+      if err != nil { return fmt.Errorf("failed to load bars of %s: %w", code, err) }
+      bars = append(bars, bar)
+    }
+
+    item, err := report.NewItem(stock, bars, chartColors)
+    if err != nil { return err }
+    if item == nil {
+      log.Printf("[WARN] No bars for %s, skipping\n", code)
+      continue
+    }
+    items = append(items, item)
+  }
+  if len(items) == 0 { return errors.New("no code has bars") }
+
+  now := time.Now()
+  if *outDir != "" {
+    if err := os.MkdirAll(*outDir, 0o755); err != nil { return err }
+    for _, item := range items {
+      path := filepath.Join(*outDir, item.Summary.Code+".html")
+      r := &report.Report{Title: strings.TrimSpace(item.Summary.Code + " " + item.Summary.Name), GeneratedAt: now, Items: []*report.Item{item}, Colors: chartColors}
+      if err := writeReport(path, r); err != nil { return err }
+      log.Printf("[INFO] Wrote %s\n", path)
+    }
+    return nil
+  }
+
+  r := &report.Report{Title: *title, GeneratedAt: now, Items: items, Colors: chartColors}
+  if r.Title == "" && *watchlist != "" { r.Title = "Report " + *watchlist }
+  if r.Title == "" { r.Title = "Report " + *codes }
+  if err := writeReport(*out, r); err != nil { return err }
+  log.Printf("[INFO] Wrote %d codes to %s\n", len(items), *out)
+  return nil
+}
+
+// writeReport renders the report next to path and renames it into place, so
+// that a failed run leaves no half written file.
+func writeReport(path string, r *report.Report) error {
+  tmp := path + ".tmp"
+  file, err := os.Create(tmp)
+  if err != nil { return err }
+  if err := report.Render(file, r); err != nil {
+    file.Close()
+    os.Remove(tmp)
+    return fmt.Errorf("failed to render %s: %w", path, err)
+  }
+  if err := file.Close(); err != nil { return err }
+  return os.Rename(tmp, path)
+}
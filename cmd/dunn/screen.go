@@ -0,0 +1,52 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "os"
+  "text/tabwriter"
+
+  "dunn-finance/pkg/alert"
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/screen"
+)
+
+func runScreen(ctx context.Context, cfg *config.Config, args []string) error {
+  fs := newFlagSet("screen", "[flags] EXPR...", `Lists the codes whose latest bar meets every expression, written as alert
+rules, e.g.
+  dunn screen 'close > dma25' 'volume > 3*vma25'`)
+  db := addDBFlags(fs, cfg)
+  codes := fs.String("codes", "", "Comma separated stock codes. Empty screens every code")
+  watchlist := fs.String("watchlist", "", "Name of the watchlist whose codes are screened, with those of -codes")
+  if err := parseFlags(fs, args); err != nil { return err }
+
+  if fs.NArg() == 0 { return usagef("specify at least one expression") }
+  var conds []*alert.Condition
+  for _, expr := range fs.Args() {
+    cond, err := alert.Parse(expr)
+    if err != nil { return usagef("%v", err) }
+    conds = append(conds, cond)
+  }
+
+  conn, err := db.open()
+  if err != nil { return err }
+  defer conn.Close()
+
+  codeList, err := selectCodes(ctx, conn, *codes, *watchlist)
+  if err != nil { return err }
+
+  matches, err := screen.Screen(ctx, conn, codeList, conds)
+  if err != nil { return err }
+
+  w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+  fmt.Fprintln(w, "CODE\tDATE\tCLOSE\tCHG%\tVOLUME\t")
+  for _, m := range matches {
+    fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t\n", m.Code, m.Bar.Yyyymmdd, formatFloat(m.Bar.ClosePrice, "%.1f"), formatFloat(m.Change(), "%+.2f"), formatFloat(m.Bar.Volume, "%.0f"))
+  }
+  return w.Flush()
+}
+
+func formatFloat(v *float64, format string) string {
+  if v == nil { return "-" }
+  return fmt.Sprintf(format, *v)
+}
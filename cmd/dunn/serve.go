@@ -0,0 +1,86 @@
+package main
+
+import (
+  "context"
+  "errors"
+  "log"
+  "net"
+  "net/http"
+  "time"
+
+  "google.golang.org/grpc"
+
+  "dunn-finance/pkg/api"
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/marketdata"
+)
+
+func runServe(ctx context.Context, cfg *config.Config, args []string) error {
+  fs := newFlagSet("serve", "[flags]", `Serves stocks and adjusted daily OHLCVs as JSON and the MarketData gRPC
+service until interrupted.`)
+  db := addDBFlags(fs, cfg)
+  addr := fs.String("addr", cfg.Serve.Addr, "Address the JSON API listens on. Empty disables it")
+  grpcAddr := fs.String("grpc-addr", cfg.Serve.GRPCAddr, "Address the gRPC service listens on. Empty disables it")
+  if err := parseFlags(fs, args); err != nil { return err }
+
+  if *addr == "" && *grpcAddr == "" { return usagef("both -addr and -grpc-addr are empty") }
+
+  conn, err := db.open()
+  if err != nil { return err }
+  defer conn.Close()
+
+  // The first server to fail stops the other.
+  ctx, cancel := context.WithCancel(ctx)
+  defer cancel()
+  errs := make(chan error, 2)
+  servers := 0
+
+  if *addr != "" {
+    server := &http.Server{
+      Addr:              *addr,
+      Handler:           (&api.Server{DB: conn}).Handler(),
+      ReadHeaderTimeout: 10 * time.Second,
+    }
+    go func() {
+      <-ctx.Done()
+      shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+      defer cancel()
+      if err := server.Shutdown(shutdownCtx); err != nil { log.Printf("[ERROR] Failed to shut down: %v\n", err) }
+    }()
+    servers++
+    go func() {
+      log.Printf("[INFO] JSON API listening on %s\n", *addr)
+      err := server.ListenAndServe()
+      if errors.Is(err, http.ErrServerClosed) { err = nil }
+      errs <- err
+    }()
+  }
+
+  if *grpcAddr != "" {
+    listener, err := net.Listen("tcp", *grpcAddr)
+    if err != nil {
+      cancel()
+      for ; servers > 0; servers-- { <-errs }
+      return err
+    }
+    server := grpc.NewServer()
+    (&marketdata.Server{DB: conn}).Register(server)
+    go func() {
+      <-ctx.Done()
+      server.GracefulStop()
+    }()
+    servers++
+    go func() {
+      log.Printf("[INFO] gRPC listening on %s\n", listener.Addr())
+      errs <- server.Serve(listener)
+    }()
+  }
+
+  var errList []error
+  for ; servers > 0; servers-- {
+    if err := <-errs; err != nil { errList = append(errList, err) }
+    cancel()
+  }
+  log.Println("[INFO] Stopped")
+  return errors.Join(errList...)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+  "context"
+  "fmt"
+  "os"
+
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/model"
+  "dunn-finance/pkg/tui"
+)
+
+func runTUI(ctx context.Context, cfg *config.Config, args []string) error {
+  fs := newFlagSet("tui", "[flags]", "Browses the stocks and their bars in the terminal.")
+  db := addDBFlags(fs, cfg)
+  colors := fs.String("colors", "japanese", "Candle colors: japanese (red up) or western (green up)")
+  if err := parseFlags(fs, args); err != nil { return err }
+
+  app := &tui.App{}
+  switch *colors {
+    case "japanese":
+      app.Colors = tui.JapaneseColors
+    case "western":
+      app.Colors = tui.WesternColors
+    default:
+      return usagef("unknown -colors %q. Use japanese or western", *colors)
+  }
+
+  conn, err := db.open()
+  if err != nil { return err }
+  defer conn.Close()
+
+  stocks, err := dao.NewStockDAO(conn).FindMany(ctx, "ORDER BY code")
+  if err != nil { return fmt.Errorf("failed to load stocks: %w", err) }
+  app.Stocks = stocks
+
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(conn)
+  app.Load = func(code string) ([]*model.AdjustedDailyOHLCV, error) {
+    var bars []*model.AdjustedDailyOHLCV
+    for bar, err := range ohlcvDao.IterateFiltered(ctx, []string{code}, model.Date{}, model.Date{}) {
+      if err != nil { return nil, err }
+      bars = append(bars, bar)
+    }
+    return bars, nil
+  }
+
+  return tui.Run(ctx, os.Stdin, os.Stdout, app)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+  "context"
+  "database/sql"
+  "errors"
+  "fmt"
+  "log"
+  "os"
+  "strings"
+  "text/tabwriter"
+  "time"
+
+  "dunn-finance/pkg/config"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/model"
+)
+
+var watchlistCommands = []subcommand{
+  {"create", "Create the list -name with -notes"},
+  {"list", "Print every list with its number of codes"},
+  {"show", "Print the codes of -name with their notes and added dates"},
+  {"add", "Add the codes to -name, with -note on each"},
+  {"remove", "Remove the codes from -name"},
+  {"rename", "Rename -name to -to"},
+  {"notes", "Replace the notes of -name with -notes"},
+  {"delete", "Delete -name and its codes"},
+}
+
+func runWatchlist(ctx context.Context, cfg *config.Config, args []string) error {
+  command, args, err := parseSubcommand("watchlist", watchlistCommands, args)
+  if err != nil { return err }
+
+  fs := newFlagSet("watchlist "+command, "[flags] [codes...]", summaryOf(watchlistCommands, command)+`.
+
+Codes are separate arguments or comma separated. fetch, import, indicators,
+screen, export and report take -watchlist NAME to run over the codes of a list.`)
+  db := addDBFlags(fs, cfg)
+  name := fs.String("name", "", "Name of the watchlist")
+  notes := fs.String("notes", "", "Notes of the watchlist")
+  note := fs.String("note", "", "Note of the codes added")
+  to := fs.String("to", "", "New name with rename")
+  if err := parseFlags(fs, args); err != nil { return err }
+  codes := splitCodes(strings.Join(fs.Args(), ","))
+
+  if command != "list" && *name == "" { return usagef("specify the watchlist with -name") }
+  switch command {
+    case "add", "remove":
+      if len(codes) == 0 { return usagef("specify the codes to %s", command) }
+    case "rename":
+      if *to == "" { return usagef("specify the new name with -to") }
+  }
+
+  conn, err := db.open()
+  if err != nil { return err }
+  defer conn.Close()
+
+  listDao := dao.NewWatchlistDAO(conn)
+  codeDao := dao.NewWatchlistCodeDAO(conn)
+
+  switch command {
+    case "create":
+      list := &model.Watchlist{Name: *name, Notes: *notes, CreatedAt: time.Now()}
+      if err := listDao.Create(ctx, list); err != nil { return fmt.Errorf("failed to create %s: %w", *name, err) }
+      log.Printf("[INFO] Created watchlist %s\n", *name)
+      return nil
+    case "list":
+      lists, err := listDao.FindAll(ctx)
+      if err != nil { return fmt.Errorf("failed to load watchlists: %w", err) }
+      tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+      fmt.Fprintln(tw, "NAME\tCODES\tCREATED\tNOTES")
+      for _, list := range lists {
+        count, err := codeDao.Count(ctx, "WHERE watchlist_id = ?", list.ID)
+        if err != nil { return fmt.Errorf("failed to count codes of %s: %w", list.Name, err) }
+        fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", list.Name, count, list.CreatedAt.Local().Format("2006/01/02"), list.Notes)
+      }
+      return tw.Flush()
+  }
+
+  list, err := listDao.FindByName(ctx, *name)
+  if errors.Is(err, sql.ErrNoRows) { return usagef("no watchlist %s", *name) }
+  if err != nil { return fmt.Errorf("failed to load %s: %w", *name, err) }
+
+  switch command {
+    case "show":
+      members, err := codeDao.FindByWatchlist(ctx, list.ID)
+      if err != nil { return fmt.Errorf("failed to load codes of %s: %w", *name, err) }
+      if list.Notes != "" { fmt.Println(list.Notes) }
+      tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+      fmt.Fprintln(tw, "CODE\tADDED\tNOTE")
+      for _, member := range members {
+        fmt.Fprintf(tw, "%s\t%s\t%s\n", member.Code, member.AddedAt.Local().Format("2006/01/02"), member.Note)
+      }
+      return tw.Flush()
+    case "add":
+      now := time.Now()
+      err := conn.WithTx(ctx, func(tx database.Querier) error {
+        txDao := dao.NewWatchlistCodeDAO(tx)
+        for _, code := range codes {
+          if err := txDao.Add(ctx, &model.WatchlistCode{WatchlistID: list.ID, Code: code, Note: *note, AddedAt: now}); err != nil { return fmt.Errorf("%s: %w", code, err) }
+        }
+        return nil
+      })
+      if err != nil { return fmt.Errorf("failed to add codes to %s: %w", *name, err) }
+      log.Printf("[INFO] Added %d codes to %s\n", len(codes), *name)
+    case "remove":
+      for _, code := range codes {
+        n, err := codeDao.Remove(ctx, list.ID, code)
+        if err != nil { return fmt.Errorf("failed to remove %s: %w", code, err) }
+        if n == 0 { log.Printf("[WARN] %s is not on %s\n", code, *name) }
+      }
+      log.Printf("[INFO] Removed codes from %s\n", *name)
+    case "rename":
+      list.Name = *to
+      if err := listDao.Update(ctx, list); err != nil { return fmt.Errorf("failed to rename %s: %w", *name, err) }
+      log.Printf("[INFO] Renamed watchlist %s to %s\n", *name, *to)
+    case "notes":
+      list.Notes = *notes
+      if err := listDao.Update(ctx, list); err != nil { return fmt.Errorf("failed to update %s: %w", *name, err) }
+      log.Printf("[INFO] Updated the notes of %s\n", *name)
+    case "delete":
+      if _, err := listDao.Delete(ctx, list.ID); err != nil { return fmt.Errorf("failed to delete %s: %w", *name, err) }
+      log.Printf("[INFO] Deleted watchlist %s\n", *name)
+  }
+  return nil
+}
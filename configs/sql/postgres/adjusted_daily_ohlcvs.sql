@@ -0,0 +1,24 @@
+-- import_id is added by adjusted_daily_ohlcvs_import_id.sql, also on tables
+-- created before imports existed.
+CREATE TABLE IF NOT EXISTS adjusted_daily_ohlcvs (
+  yyyymmdd     TEXT NOT NULL,
+  code         TEXT NOT NULL,
+  open_price   DOUBLE PRECISION,
+  high_price   DOUBLE PRECISION,
+  low_price    DOUBLE PRECISION,
+  close_price  DOUBLE PRECISION,
+  dma_price_5  DOUBLE PRECISION,
+  dma_price_25 DOUBLE PRECISION,
+  dma_price_75 DOUBLE PRECISION,
+  vmap         DOUBLE PRECISION,
+  volume       DOUBLE PRECISION,
+  vma_5        DOUBLE PRECISION,
+  vma_25       DOUBLE PRECISION,
+  PRIMARY KEY (code, yyyymmdd),
+  FOREIGN KEY (code) REFERENCES codes(code)
+);
+
+-- The primary key serves per-code range scans. This one serves
+-- cross-sections of a date and the latest date lookups.
+CREATE INDEX IF NOT EXISTS idx_adjusted_daily_ohlcvs_yyyymmdd_code
+  ON adjusted_daily_ohlcvs (yyyymmdd, code);
@@ -0,0 +1,50 @@
+CREATE TABLE IF NOT EXISTS adjusted_daily_ohlcvs_history (
+  id                    BIGSERIAL PRIMARY KEY,
+  yyyymmdd              TEXT NOT NULL,
+  code                  TEXT NOT NULL,
+  open_price            DOUBLE PRECISION,
+  high_price            DOUBLE PRECISION,
+  low_price             DOUBLE PRECISION,
+  close_price           DOUBLE PRECISION,
+  dma_price_5           DOUBLE PRECISION,
+  dma_price_25          DOUBLE PRECISION,
+  dma_price_75          DOUBLE PRECISION,
+  vmap                  DOUBLE PRECISION,
+  volume                DOUBLE PRECISION,
+  vma_5                 DOUBLE PRECISION,
+  vma_25                DOUBLE PRECISION,
+  import_id             BIGINT,
+  replaced_by_import_id BIGINT,
+  replaced_at           TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_adjusted_daily_ohlcvs_history_code_yyyymmdd
+  ON adjusted_daily_ohlcvs_history (code, yyyymmdd);
+
+CREATE OR REPLACE FUNCTION adjusted_daily_ohlcvs_keep_history() RETURNS trigger AS $$
+BEGIN
+  INSERT INTO adjusted_daily_ohlcvs_history (
+    yyyymmdd, code, open_price, high_price, low_price, close_price,
+    dma_price_5, dma_price_25, dma_price_75, vmap, volume, vma_5, vma_25,
+    import_id, replaced_by_import_id
+  ) VALUES (
+    OLD.yyyymmdd, OLD.code, OLD.open_price, OLD.high_price, OLD.low_price, OLD.close_price,
+    OLD.dma_price_5, OLD.dma_price_25, OLD.dma_price_75, OLD.vmap, OLD.volume, OLD.vma_5, OLD.vma_25,
+    OLD.import_id, NEW.import_id
+  );
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Keeps the old values when an update, including an upsert, changes them.
+-- Rewriting the same values only moves import_id and saves nothing.
+DROP TRIGGER IF EXISTS adjusted_daily_ohlcvs_keep_history ON adjusted_daily_ohlcvs;
+CREATE TRIGGER adjusted_daily_ohlcvs_keep_history
+BEFORE UPDATE ON adjusted_daily_ohlcvs
+FOR EACH ROW
+WHEN ((OLD.open_price, OLD.high_price, OLD.low_price, OLD.close_price,
+       OLD.dma_price_5, OLD.dma_price_25, OLD.dma_price_75, OLD.vmap, OLD.volume, OLD.vma_5, OLD.vma_25)
+  IS DISTINCT FROM
+      (NEW.open_price, NEW.high_price, NEW.low_price, NEW.close_price,
+       NEW.dma_price_5, NEW.dma_price_25, NEW.dma_price_75, NEW.vmap, NEW.volume, NEW.vma_5, NEW.vma_25))
+EXECUTE FUNCTION adjusted_daily_ohlcvs_keep_history();
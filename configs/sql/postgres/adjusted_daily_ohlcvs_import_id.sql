@@ -0,0 +1,2 @@
+-- The import that wrote each bar.
+ALTER TABLE adjusted_daily_ohlcvs ADD COLUMN IF NOT EXISTS import_id BIGINT REFERENCES imports(id);
@@ -0,0 +1,3 @@
+-- codes.name was nullable before importers registered codes, and StockDAO
+-- reads it as a string.
+UPDATE codes SET name = '' WHERE name IS NULL;
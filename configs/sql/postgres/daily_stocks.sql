@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS daily_stocks (
+  yyyymmdd INTEGER,
+  code TEXT,
+  openPrice DOUBLE PRECISION,
+  highPrice DOUBLE PRECISION,
+  lowPrice DOUBLE PRECISION,
+  closePrice DOUBLE PRECISION,
+  rsi DOUBLE PRECISION,
+  macd DOUBLE PRECISION,
+  signal DOUBLE PRECISION
+)
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS watchlists (
+  id         BIGSERIAL PRIMARY KEY,
+  name       TEXT NOT NULL UNIQUE,
+  notes      TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS watchlist_codes (
+  watchlist_id BIGINT NOT NULL,
+  code         TEXT NOT NULL,
+  note         TEXT NOT NULL DEFAULT '',
+  added_at     TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (watchlist_id, code),
+  FOREIGN KEY (watchlist_id) REFERENCES watchlists(id) ON DELETE CASCADE
+);
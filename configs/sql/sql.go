@@ -0,0 +1,32 @@
+// Package sql embeds the schema files, so that dunn migrate works without
+// the source tree.
+package sql
+
+import (
+  "embed"
+  "io/fs"
+)
+
+//go:embed sqlite3/*.sql postgres/*.sql
+var files embed.FS
+
+// Files are the schema files in the order to run them, referenced tables
+// first. New files are appended, unless later files depend on them.
+var Files = []string{
+  "codes.sql",
+  "imports.sql",
+  "adjusted_daily_ohlcvs.sql",
+  "adjusted_daily_ohlcvs_import_id.sql",
+  "adjusted_daily_ohlcvs_history.sql",
+  "adjustment_factors.sql",
+  "daily_stocks.sql",
+  "watchlists.sql",
+  "alerts.sql",
+  "jobs.sql",
+  "codes_name.sql",
+}
+
+// FS returns the schema files of the dialect, sqlite3 or postgres.
+func FS(dialect string) (fs.FS, error) {
+  return fs.Sub(files, dialect)
+}
@@ -1,3 +1,5 @@
+-- import_id is added by adjusted_daily_ohlcvs_import_id.sql, also on tables
+-- created before imports existed.
 CREATE TABLE IF NOT EXISTS adjusted_daily_ohlcvs (
   yyyymmdd     TEXT NOT NULL,
   code         TEXT NOT NULL,
@@ -15,3 +17,8 @@ CREATE TABLE IF NOT EXISTS adjusted_daily_ohlcvs (
   PRIMARY KEY (code, yyyymmdd),
   FOREIGN KEY (code) REFERENCES codes(code)
 );
+
+-- The primary key serves per-code range scans. This one serves
+-- cross-sections of a date and the latest date lookups.
+CREATE INDEX IF NOT EXISTS idx_adjusted_daily_ohlcvs_yyyymmdd_code
+  ON adjusted_daily_ohlcvs (yyyymmdd, code);
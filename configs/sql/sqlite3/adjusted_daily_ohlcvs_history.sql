@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS adjusted_daily_ohlcvs_history (
+  id                    INTEGER PRIMARY KEY AUTOINCREMENT,
+  yyyymmdd              TEXT NOT NULL,
+  code                  TEXT NOT NULL,
+  open_price            REAL,
+  high_price            REAL,
+  low_price             REAL,
+  close_price           REAL,
+  dma_price_5           REAL,
+  dma_price_25          REAL,
+  dma_price_75          REAL,
+  vmap                  REAL,
+  volume                REAL,
+  vma_5                 REAL,
+  vma_25                REAL,
+  import_id             INTEGER,
+  replaced_by_import_id INTEGER,
+  replaced_at           TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_adjusted_daily_ohlcvs_history_code_yyyymmdd
+  ON adjusted_daily_ohlcvs_history (code, yyyymmdd);
+
+-- Keeps the old values when an update, including an upsert, changes them.
+-- Rewriting the same values only moves import_id and saves nothing.
+CREATE TRIGGER IF NOT EXISTS adjusted_daily_ohlcvs_keep_history
+BEFORE UPDATE ON adjusted_daily_ohlcvs
+WHEN OLD.open_price IS NOT NEW.open_price
+  OR OLD.high_price IS NOT NEW.high_price
+  OR OLD.low_price IS NOT NEW.low_price
+  OR OLD.close_price IS NOT NEW.close_price
+  OR OLD.dma_price_5 IS NOT NEW.dma_price_5
+  OR OLD.dma_price_25 IS NOT NEW.dma_price_25
+  OR OLD.dma_price_75 IS NOT NEW.dma_price_75
+  OR OLD.vmap IS NOT NEW.vmap
+  OR OLD.volume IS NOT NEW.volume
+  OR OLD.vma_5 IS NOT NEW.vma_5
+  OR OLD.vma_25 IS NOT NEW.vma_25
+BEGIN
+  INSERT INTO adjusted_daily_ohlcvs_history (
+    yyyymmdd, code, open_price, high_price, low_price, close_price,
+    dma_price_5, dma_price_25, dma_price_75, vmap, volume, vma_5, vma_25,
+    import_id, replaced_by_import_id
+  ) VALUES (
+    OLD.yyyymmdd, OLD.code, OLD.open_price, OLD.high_price, OLD.low_price, OLD.close_price,
+    OLD.dma_price_5, OLD.dma_price_25, OLD.dma_price_75, OLD.vmap, OLD.volume, OLD.vma_5, OLD.vma_25,
+    OLD.import_id, NEW.import_id
+  );
+END;
@@ -0,0 +1,4 @@
+-- The import that wrote each bar. SQLite has no ADD COLUMN IF NOT EXISTS, so
+-- the line below makes migrate skip the file on tables having the column.
+-- migrate: unless column adjusted_daily_ohlcvs.import_id
+ALTER TABLE adjusted_daily_ohlcvs ADD COLUMN import_id INTEGER REFERENCES imports(id);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS adjustment_factors (
+  yyyymmdd TEXT NOT NULL,
+  code     TEXT NOT NULL,
+  factor   REAL NOT NULL,
+  PRIMARY KEY (code, yyyymmdd),
+  FOREIGN KEY (code) REFERENCES codes(code)
+);
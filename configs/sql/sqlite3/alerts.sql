@@ -0,0 +1,21 @@
+-- A rule with an empty code applies to every code.
+CREATE TABLE IF NOT EXISTS alert_rules (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  code       TEXT NOT NULL DEFAULT '',
+  expression TEXT NOT NULL,
+  note       TEXT NOT NULL DEFAULT '',
+  enabled    BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMP NOT NULL
+);
+
+-- A rule triggers at most once per code and bar date.
+CREATE TABLE IF NOT EXISTS alerts (
+  id           INTEGER PRIMARY KEY AUTOINCREMENT,
+  rule_id      INTEGER NOT NULL,
+  code         TEXT NOT NULL,
+  yyyymmdd     TEXT NOT NULL,
+  message      TEXT NOT NULL,
+  triggered_at TIMESTAMP NOT NULL,
+  UNIQUE (rule_id, code, yyyymmdd),
+  FOREIGN KEY (rule_id) REFERENCES alert_rules(id) ON DELETE CASCADE
+);
@@ -1,4 +1,6 @@
+-- The stocks read by StockDAO. Importers register a code with an empty name
+-- before writing its first bars, which refer to it.
 CREATE TABLE IF NOT EXISTS codes (
   code TEXT PRIMARY KEY,
-  name TEXT
+  name TEXT NOT NULL DEFAULT ''
 )
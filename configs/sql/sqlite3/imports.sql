@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS imports (
+  id           INTEGER PRIMARY KEY AUTOINCREMENT,
+  source       TEXT NOT NULL,
+  file_path    TEXT NOT NULL DEFAULT '',
+  file_hash    TEXT NOT NULL DEFAULT '',
+  code         TEXT NOT NULL,
+  imported_at  TIMESTAMP NOT NULL,
+  rows_read    INTEGER NOT NULL DEFAULT 0,
+  rows_written INTEGER NOT NULL DEFAULT 0
+);
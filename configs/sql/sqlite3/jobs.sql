@@ -0,0 +1,14 @@
+-- One row per run of a scheduled job of dunn daemon. status is running,
+-- succeeded or failed.
+CREATE TABLE IF NOT EXISTS jobs (
+  id           INTEGER PRIMARY KEY AUTOINCREMENT,
+  name         TEXT NOT NULL,
+  scheduled_at TIMESTAMP NOT NULL,
+  started_at   TIMESTAMP NOT NULL,
+  finished_at  TIMESTAMP,
+  status       TEXT NOT NULL,
+  summary      TEXT NOT NULL DEFAULT '',
+  error        TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS jobs_name_started_at ON jobs (name, started_at);
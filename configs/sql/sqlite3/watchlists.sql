@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS watchlists (
+  id         INTEGER PRIMARY KEY AUTOINCREMENT,
+  name       TEXT NOT NULL UNIQUE,
+  notes      TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS watchlist_codes (
+  watchlist_id INTEGER NOT NULL,
+  code         TEXT NOT NULL,
+  note         TEXT NOT NULL DEFAULT '',
+  added_at     TIMESTAMP NOT NULL,
+  PRIMARY KEY (watchlist_id, code),
+  FOREIGN KEY (watchlist_id) REFERENCES watchlists(id) ON DELETE CASCADE
+);
@@ -16,6 +16,7 @@
             pkgs.go
             pkgs.protobuf
             pkgs.protoc-gen-go
+            pkgs.protoc-gen-go-grpc
           ];
         };
       });
@@ -0,0 +1,85 @@
+package alert
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "sort"
+  "strconv"
+  "strings"
+  "time"
+
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/model"
+)
+
+// Evaluate checks the enabled rules of each code against its latest bar and
+// records the alerts that trigger. Only alerts not recorded before are
+// returned, so importing the same day again does not notify twice. A rule
+// with an invalid expression is skipped and reported in the error, after
+// the other rules are evaluated.
+func Evaluate(ctx context.Context, db database.Querier, codes []string, now time.Time) ([]*model.Alert, error) {
+  if len(codes) == 0 { return nil, nil }
+
+  latest, err := dao.NewAdjustedDailyOHLCVDAO(db).FindLatestN(ctx, codes, 2)
+  if err != nil { return nil, err }
+
+  ruleDao := dao.NewAlertRuleDAO(db)
+  alertDao := dao.NewAlertDAO(db)
+  sorted := append([]string(nil), codes...)
+  sort.Strings(sorted)
+
+  var alerts []*model.Alert
+  var errs []error
+  for _, code := range sorted {
+    bars := latest[code]
+    if len(bars) == 0 { continue }
+    cur := bars[len(bars)-1]
+    var prev *model.AdjustedDailyOHLCV
+    if len(bars) > 1 { prev = bars[len(bars)-2] }
+
+    rules, err := ruleDao.FindEnabled(ctx, code)
+    if err != nil { return alerts, err }
+    for _, rule := range rules {
+      cond, err := Parse(rule.Expression)
+      if err != nil {
+        errs = append(errs, fmt.Errorf("rule %d: %w", rule.ID, err))
+        continue
+      }
+      if !cond.Holds(prev, cur) { continue }
+
+      alert := &model.Alert{RuleID: rule.ID, Code: code, Yyyymmdd: cur.Yyyymmdd, Message: Message(rule, cond, cur), TriggeredAt: now}
+      recorded, err := alertDao.Record(ctx, alert)
+      if err != nil { return alerts, err }
+      if recorded { alerts = append(alerts, alert) }
+    }
+  }
+
+  return alerts, errors.Join(errs...)
+}
+
+// Run evaluates the rules of the codes and delivers the new alerts through
+// notifier. Alerts are recorded even when the delivery fails.
+func Run(ctx context.Context, db database.Querier, codes []string, notifier Notifier, now time.Time) ([]*model.Alert, error) {
+  alerts, err := Evaluate(ctx, db, codes, now)
+  if len(alerts) == 0 { return alerts, err }
+
+  if notifyErr := notifier.Notify(ctx, alerts); notifyErr != nil { err = errors.Join(err, fmt.Errorf("failed to notify alerts: %w", notifyErr)) }
+  return alerts, err
+}
+
+// Message describes the triggered rule with the values of the bar, e.g.
+// "5253 2025/07/18 close crosses_above 2200 (close 2215, note)".
+func Message(rule *model.AlertRule, cond *Condition, bar *model.AdjustedDailyOHLCV) string {
+  var values []string
+  for _, operand := range []Operand{cond.Left, cond.Right} {
+    if operand.Field == "" { continue }
+    if v, ok := (Operand{Field: operand.Field, Factor: 1}).value(bar); ok {
+      values = append(values, operand.Field+" "+strconv.FormatFloat(v, 'f', -1, 64))
+    }
+  }
+  if rule.Note != "" { values = append(values, rule.Note) }
+
+  return fmt.Sprintf("%s %s %s (%s)", bar.Code, bar.Yyyymmdd.Time().Format("2006/01/02"), cond, strings.Join(values, ", "))
+}
@@ -0,0 +1,59 @@
+package alert_test
+
+import (
+  "context"
+  "testing"
+  "time"
+
+  _ "github.com/mattn/go-sqlite3"
+
+  "dunn-finance/pkg/alert"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/model"
+)
+
+func TestEvaluate_Success(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+
+  stockDao := dao.NewStockDAO(db)
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(db)
+  for _, code := range []string{"5253", "7203"} {
+    if err := stockDao.Create(ctx, &model.Stock{Code: code}); err != nil { t.Fatal(err) }
+    for i, close := range []float64{2150, 2190, 2215} {
+      bar := &model.AdjustedDailyOHLCV{Yyyymmdd: model.MustParseDate("20250716").AddDays(i), Code: code, ClosePrice: f(close), Volume: f(1000 * float64(i+1)), VMA25: f(900), DMAPrice75: f(2300)}
+      if err := ohlcvDao.Create(ctx, bar); err != nil { t.Fatal(err) }
+    }
+  }
+
+  ruleDao := dao.NewAlertRuleDAO(db)
+  rules := []*model.AlertRule{
+    {Code: "5253", Expression: "close crosses_above 2,200", Note: "breakout", Enabled: true},
+    {Code: "5253", Expression: "volume > 3x vma25", Enabled: true},
+    {Code: "5253", Expression: "close > 0", Enabled: false},
+    {Code: "7203", Expression: "close > 3000", Enabled: true},
+    {Expression: "close below dma75", Enabled: true},
+    {Code: "5253", Expression: "close is high", Enabled: true},
+  }
+  for _, rule := range rules {
+    rule.CreatedAt = time.Now()
+    if err := ruleDao.Create(ctx, rule); err != nil { t.Fatal(err) }
+  }
+
+  now := time.Date(2025, 7, 18, 16, 0, 0, 0, time.UTC)
+  alerts, err := alert.Evaluate(ctx, db, []string{"7203", "5253"}, now)
+  if err == nil { t.Error("Expected the invalid rule to be reported") }
+
+  want := []struct{ ruleID int64; code string }{{rules[0].ID, "5253"}, {rules[1].ID, "5253"}, {rules[4].ID, "5253"}, {rules[4].ID, "7203"}}
+  if len(alerts) != len(want) { t.Fatalf("want %d alerts, got %+v", len(want), alerts) }
+  for i, w := range want {
+    a := alerts[i]
+    if a.ID == 0 || a.RuleID != w.ruleID || a.Code != w.code || a.Yyyymmdd != model.MustParseDate("20250718") || !a.TriggeredAt.Equal(now) { t.Errorf("alert %d: unexpected %+v", i, a) }
+  }
+  if msg := alerts[0].Message; msg != "5253 2025/07/18 close crosses_above 2200 (close 2215, breakout)" { t.Errorf("Unexpected message %q", msg) }
+
+  // The same bars again trigger nothing new.
+  again, _ := alert.Evaluate(ctx, db, []string{"5253", "7203"}, now.Add(time.Hour))
+  if len(again) != 0 { t.Errorf("Expected no new alerts, got %+v", again) }
+  if count, _ := dao.NewAlertDAO(db).Count(ctx, ""); count != 4 { t.Errorf("want 4 recorded alerts, got %d", count) }
+}
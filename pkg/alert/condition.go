@@ -0,0 +1,178 @@
+// Package alert evaluates the alert rules stored in the database against the
+// latest bars and delivers the alerts that trigger through notifiers.
+package alert
+
+import (
+  "fmt"
+  "strconv"
+  "strings"
+
+  "dunn-finance/pkg/model"
+)
+
+// Operator compares the two operands of a condition.
+type Operator string
+
+const (
+  OpAbove        Operator = ">"
+  OpAboveOrEqual Operator = ">="
+  OpBelow        Operator = "<"
+  OpBelowOrEqual Operator = "<="
+  // OpCrossesAbove holds on the bar where the left operand moves from at or
+  // below the right one on the previous bar to above it.
+  OpCrossesAbove Operator = "crosses_above"
+  OpCrossesBelow Operator = "crosses_below"
+)
+
+var operators = map[string]Operator{
+  ">":             OpAbove,
+  "above":         OpAbove,
+  ">=":            OpAboveOrEqual,
+  "<":             OpBelow,
+  "below":         OpBelow,
+  "<=":            OpBelowOrEqual,
+  "crosses_above": OpCrossesAbove,
+  "crosses_below": OpCrossesBelow,
+}
+
+// fields are the columns of a bar a condition can refer to.
+var fields = map[string]func(bar *model.AdjustedDailyOHLCV) *float64{
+  "open":   func(bar *model.AdjustedDailyOHLCV) *float64 { return bar.OpenPrice },
+  "high":   func(bar *model.AdjustedDailyOHLCV) *float64 { return bar.HighPrice },
+  "low":    func(bar *model.AdjustedDailyOHLCV) *float64 { return bar.LowPrice },
+  "close":  func(bar *model.AdjustedDailyOHLCV) *float64 { return bar.ClosePrice },
+  "dma5":   func(bar *model.AdjustedDailyOHLCV) *float64 { return bar.DMAPrice5 },
+  "dma25":  func(bar *model.AdjustedDailyOHLCV) *float64 { return bar.DMAPrice25 },
+  "dma75":  func(bar *model.AdjustedDailyOHLCV) *float64 { return bar.DMAPrice75 },
+  "vmap":   func(bar *model.AdjustedDailyOHLCV) *float64 { return bar.VMAP },
+  "volume": func(bar *model.AdjustedDailyOHLCV) *float64 { return bar.Volume },
+  "vma5":   func(bar *model.AdjustedDailyOHLCV) *float64 { return bar.VMA5 },
+  "vma25":  func(bar *model.AdjustedDailyOHLCV) *float64 { return bar.VMA25 },
+}
+
+// Operand is a field of the bar times Factor, or the constant Factor when
+// Field is empty.
+type Operand struct {
+  Field  string
+  Factor float64
+}
+
+func (o Operand) value(bar *model.AdjustedDailyOHLCV) (float64, bool) {
+  if o.Field == "" { return o.Factor, true }
+  v := fields[o.Field](bar)
+  if v == nil { return 0, false }
+  return *v * o.Factor, true
+}
+
+func (o Operand) String() string {
+  factor := strconv.FormatFloat(o.Factor, 'f', -1, 64)
+  switch {
+    case o.Field == "":
+      return factor
+    case o.Factor == 1:
+      return o.Field
+    default:
+      return factor + "*" + o.Field
+  }
+}
+
+// Condition is a parsed rule expression.
+type Condition struct {
+  Left  Operand
+  Op    Operator
+  Right Operand
+}
+
+// Parse reads "<operand> <operator> <operand>", e.g.
+//
+//   close crosses_above 2,200
+//   volume > 3*vma25
+//   close below dma75
+//
+// An operand is a number, a field (open, high, low, close, dma5, dma25,
+// dma75, vmap, volume, vma5, vma25) or a number times a field as 3*vma25 or
+// 3x vma25. Operators are >, >=, <, <=, above, below, crosses_above and
+// crosses_below. Underscores in field names are ignored, so dma_75 is dma75.
+func Parse(expr string) (*Condition, error) {
+  tokens := strings.Fields(strings.ToLower(expr))
+  // "3x vma25" is one operand written as two tokens.
+  for i := 0; i+1 < len(tokens); i++ {
+    if strings.HasSuffix(tokens[i], "x") && isNumber(strings.TrimSuffix(tokens[i], "x")) && fields[normalizeField(tokens[i+1])] != nil {
+      tokens = append(tokens[:i], append([]string{strings.TrimSuffix(tokens[i], "x") + "*" + tokens[i+1]}, tokens[i+2:]...)...)
+    }
+  }
+  if len(tokens) != 3 { return nil, fmt.Errorf("invalid alert expression %q: want <operand> <operator> <operand>", expr) }
+
+  op, ok := operators[tokens[1]]
+  if !ok { return nil, fmt.Errorf("invalid alert expression %q: unknown operator %q", expr, tokens[1]) }
+  left, err := parseOperand(tokens[0])
+  if err != nil { return nil, fmt.Errorf("invalid alert expression %q: %w", expr, err) }
+  right, err := parseOperand(tokens[2])
+  if err != nil { return nil, fmt.Errorf("invalid alert expression %q: %w", expr, err) }
+  if left.Field == "" && right.Field == "" { return nil, fmt.Errorf("invalid alert expression %q: compares two numbers", expr) }
+
+  return &Condition{Left: left, Op: op, Right: right}, nil
+}
+
+func parseOperand(s string) (Operand, error) {
+  if isNumber(s) {
+    v, _ := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+    return Operand{Factor: v}, nil
+  }
+
+  factor := 1.0
+  if i := strings.Index(s, "*"); i >= 0 {
+    if !isNumber(s[:i]) { return Operand{}, fmt.Errorf("invalid factor %q", s[:i]) }
+    factor, _ = strconv.ParseFloat(strings.ReplaceAll(s[:i], ",", ""), 64)
+    s = s[i+1:]
+  }
+  field := normalizeField(s)
+  if fields[field] == nil { return Operand{}, fmt.Errorf("unknown field %q", s) }
+
+  return Operand{Field: field, Factor: factor}, nil
+}
+
+func normalizeField(s string) string {
+  return strings.ReplaceAll(s, "_", "")
+}
+
+func isNumber(s string) bool {
+  _, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+  return s != "" && err == nil
+}
+
+// String is the canonical form of the condition, which Parse reads back.
+func (c *Condition) String() string {
+  return fmt.Sprintf("%s %s %s", c.Left, c.Op, c.Right)
+}
+
+// Holds reports whether the condition holds on cur. prev is the bar before
+// it, nil when there is none, and is only used by the crossing operators.
+// A condition on a missing value, such as a DMA during its warm-up, does
+// not hold.
+func (c *Condition) Holds(prev *model.AdjustedDailyOHLCV, cur *model.AdjustedDailyOHLCV) bool {
+  left, ok := c.Left.value(cur)
+  if !ok { return false }
+  right, ok := c.Right.value(cur)
+  if !ok { return false }
+
+  switch c.Op {
+    case OpAbove:
+      return left > right
+    case OpAboveOrEqual:
+      return left >= right
+    case OpBelow:
+      return left < right
+    case OpBelowOrEqual:
+      return left <= right
+  }
+
+  if prev == nil { return false }
+  prevLeft, ok := c.Left.value(prev)
+  if !ok { return false }
+  prevRight, ok := c.Right.value(prev)
+  if !ok { return false }
+
+  if c.Op == OpCrossesAbove { return prevLeft <= prevRight && left > right }
+  return prevLeft >= prevRight && left < right
+}
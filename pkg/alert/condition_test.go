@@ -0,0 +1,66 @@
+package alert_test
+
+import (
+  "testing"
+
+  "dunn-finance/pkg/alert"
+  "dunn-finance/pkg/model"
+)
+
+func f(v float64) *float64 { return &v }
+
+func TestParse_Success(t *testing.T) {
+  for expr, want := range map[string]string{
+    "close crosses_above 2,200": "close crosses_above 2200",
+    "Volume > 3x VMA25":         "volume > 3*vma25",
+    "volume > 3*vma_25":         "volume > 3*vma25",
+    "close below dma_75":        "close < dma75",
+    "2000 <= low":               "2000 <= low",
+    "high above 1.05*dma25":     "high > 1.05*dma25",
+  } {
+    cond, err := alert.Parse(expr)
+    if err != nil {
+      t.Errorf("%q: %v", expr, err)
+      continue
+    }
+    if cond.String() != want { t.Errorf("%q: got %q, want %q", expr, cond, want) }
+  }
+}
+
+func TestParse_Failure(t *testing.T) {
+  for _, expr := range []string{
+    "",
+    "close >",
+    "close equals 2200",
+    "closing > 2200",
+    "close > 3*nothing",
+    "1 < 2",
+    "close > 2200 and volume > 0",
+  } {
+    if _, err := alert.Parse(expr); err == nil { t.Errorf("%q: expected an error", expr) }
+  }
+}
+
+func TestCondition_Holds(t *testing.T) {
+  prev := &model.AdjustedDailyOHLCV{ClosePrice: f(2190), Volume: f(1000), VMA25: f(900)}
+  cur := &model.AdjustedDailyOHLCV{ClosePrice: f(2215), Volume: f(3000), VMA25: f(950)}
+
+  for expr, want := range map[string]bool{
+    "close crosses_above 2200": true,
+    "close crosses_above 2100": false, // already above the day before
+    "close crosses_below 2200": false,
+    "close > 2215":             false,
+    "close >= 2215":            true,
+    "volume > 3x vma25":        true,
+    "volume > 4x vma25":        false,
+    // A DMA in its warm-up is missing and never triggers.
+    "close below dma75":        false,
+  } {
+    cond, err := alert.Parse(expr)
+    if err != nil { t.Fatal(err) }
+    if got := cond.Holds(prev, cur); got != want { t.Errorf("%q: got %t, want %t", expr, got, want) }
+  }
+
+  cond, _ := alert.Parse("close crosses_above 2200")
+  if cond.Holds(nil, cur) { t.Error("A crossing needs the previous bar") }
+}
@@ -0,0 +1,172 @@
+package alert
+
+import (
+  "bytes"
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "net"
+  "net/http"
+  "net/smtp"
+  "os"
+  "strings"
+  "time"
+
+  "dunn-finance/pkg/credentials"
+  "dunn-finance/pkg/dto"
+  "dunn-finance/pkg/mapper"
+  "dunn-finance/pkg/model"
+)
+
+// Notifier delivers the alerts of one evaluation. It is not called when
+// there are none.
+type Notifier interface {
+  Notify(ctx context.Context, alerts []*model.Alert) error
+}
+
+// Notifiers delivers through each notifier in turn. A failed one does not
+// stop the others, and the errors are joined.
+type Notifiers []Notifier
+
+func (ns Notifiers) Notify(ctx context.Context, alerts []*model.Alert) error {
+  var errs []error
+  for _, n := range ns {
+    if err := n.Notify(ctx, alerts); err != nil { errs = append(errs, err) }
+  }
+  return errors.Join(errs...)
+}
+
+// WriterNotifier prints one line per alert, e.g. to stdout.
+type WriterNotifier struct {
+  W io.Writer
+}
+
+func (n *WriterNotifier) Notify(ctx context.Context, alerts []*model.Alert) error {
+  for _, alert := range alerts {
+    if _, err := fmt.Fprintf(n.W, "[ALERT] %s\n", alert.Message); err != nil { return err }
+  }
+  return nil
+}
+
+// FileNotifier appends the alerts to Path as JSON lines.
+type FileNotifier struct {
+  Path string
+}
+
+func (n *FileNotifier) Notify(ctx context.Context, alerts []*model.Alert) error {
+  var buf bytes.Buffer
+  enc := json.NewEncoder(&buf)
+  enc.SetEscapeHTML(false)
+  for _, alert := range alerts {
+    if err := enc.Encode(mapper.ToAlertDTO(alert)); err != nil { return err }
+  }
+
+  f, err := os.OpenFile(n.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+  if err != nil { return err }
+  if _, err := f.Write(buf.Bytes()); err != nil {
+    f.Close()
+    return fmt.Errorf("failed to write alerts to %s: %w", n.Path, err)
+  }
+  return f.Close()
+}
+
+// WebhookPayload is the body WebhookNotifier posts.
+type WebhookPayload struct {
+  Alerts []*dto.AlertDTO `json:"alerts"`
+}
+
+// WebhookNotifier posts the alerts as WebhookPayload JSON to URL. Any
+// non-2xx response is an error.
+type WebhookNotifier struct {
+  URL    string
+  // Client defaults to one with a 10 second timeout.
+  Client *http.Client
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alerts []*model.Alert) error {
+  payload := WebhookPayload{}
+  for _, alert := range alerts {
+    payload.Alerts = append(payload.Alerts, mapper.ToAlertDTO(alert))
+  }
+  body, err := json.Marshal(payload)
+  if err != nil { return err }
+
+  req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+  if err != nil { return err }
+  req.Header.Set("Content-Type", "application/json")
+
+  client := n.Client
+  if client == nil { client = &http.Client{Timeout: 10 * time.Second} }
+  res, err := client.Do(req)
+  if err != nil { return fmt.Errorf("failed to post alerts: %w", err) }
+  defer res.Body.Close()
+  io.Copy(io.Discard, res.Body)
+
+  if res.StatusCode/100 != 2 { return fmt.Errorf("failed to post alerts: %s", res.Status) }
+  return nil
+}
+
+// SMTPNotifier mails the alerts in one plain text message.
+type SMTPNotifier struct {
+  // Addr is the host:port of the server.
+  Addr string
+  // Auth may be nil for servers that accept mail without it.
+  Auth smtp.Auth
+  From string
+  To   []string
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, alerts []*model.Alert) error {
+  if err := ctx.Err(); err != nil { return err }
+
+  var msg bytes.Buffer
+  fmt.Fprintf(&msg, "From: %s\r\n", n.From)
+  fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.To, ", "))
+  fmt.Fprintf(&msg, "Subject: [dunn-finance] %d alerts\r\n", len(alerts))
+  fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+  msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+  for _, alert := range alerts {
+    fmt.Fprintf(&msg, "%s\r\n", alert.Message)
+  }
+
+  if err := smtp.SendMail(n.Addr, n.Auth, n.From, n.To, msg.Bytes()); err != nil { return fmt.Errorf("failed to mail alerts: %w", err) }
+  return nil
+}
+
+// Config selects the notifiers of a command, usually from its flags.
+type Config struct {
+  // Stdout receives every alert when set.
+  Stdout   io.Writer
+  File     string
+  Webhook  string
+  // SMTPAddr is the host:port to mail MailTo through. The login, if any, is
+  // the "smtp" credential.
+  SMTPAddr string
+  MailFrom string
+  MailTo   []string
+}
+
+func (c *Config) Notifiers() (Notifiers, error) {
+  var ns Notifiers
+  if c.Stdout != nil { ns = append(ns, &WriterNotifier{W: c.Stdout}) }
+  if c.File != "" { ns = append(ns, &FileNotifier{Path: c.File}) }
+  if c.Webhook != "" { ns = append(ns, &WebhookNotifier{URL: c.Webhook}) }
+  if c.SMTPAddr != "" {
+    if c.MailFrom == "" || len(c.MailTo) == 0 { return nil, errors.New("mail needs a sender and recipients") }
+    host, _, err := net.SplitHostPort(c.SMTPAddr)
+    if err != nil { return nil, fmt.Errorf("invalid SMTP address %q: %w", c.SMTPAddr, err) }
+
+    n := &SMTPNotifier{Addr: c.SMTPAddr, From: c.MailFrom, To: c.MailTo}
+    cred, err := credentials.Lookup("smtp")
+    if err == nil {
+      n.Auth = smtp.PlainAuth("", cred.UserID, cred.Password, host)
+    } else if !errors.Is(err, credentials.ErrNotFound) {
+      return nil, err
+    }
+    ns = append(ns, n)
+  }
+
+  return ns, nil
+}
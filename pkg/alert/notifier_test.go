@@ -0,0 +1,135 @@
+package alert_test
+
+import (
+  "bufio"
+  "context"
+  "encoding/json"
+  "errors"
+  "net"
+  "net/http"
+  "net/http/httptest"
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+  "time"
+
+  "dunn-finance/pkg/alert"
+  "dunn-finance/pkg/dto"
+  "dunn-finance/pkg/model"
+)
+
+var testAlerts = []*model.Alert{
+  {ID: 1, RuleID: 1, Code: "5253", Yyyymmdd: model.MustParseDate("20250718"), Message: "5253 2025/07/18 close crosses_above 2200 (close 2215)", TriggeredAt: time.Date(2025, 7, 18, 16, 0, 0, 0, time.UTC)},
+  {ID: 2, RuleID: 2, Code: "5253", Yyyymmdd: model.MustParseDate("20250718"), Message: "5253 2025/07/18 volume > 3*vma25 (volume 3000, vma25 900)", TriggeredAt: time.Date(2025, 7, 18, 16, 0, 0, 0, time.UTC)},
+}
+
+func TestWriterNotifier(t *testing.T) {
+  var sb strings.Builder
+  if err := (&alert.WriterNotifier{W: &sb}).Notify(context.Background(), testAlerts); err != nil { t.Fatal(err) }
+  want := "[ALERT] 5253 2025/07/18 close crosses_above 2200 (close 2215)\n[ALERT] 5253 2025/07/18 volume > 3*vma25 (volume 3000, vma25 900)\n"
+  if sb.String() != want { t.Errorf("got %q", sb.String()) }
+}
+
+func TestFileNotifier_Appends(t *testing.T) {
+  path := filepath.Join(t.TempDir(), "alerts.jsonl")
+  n := &alert.FileNotifier{Path: path}
+  for _, alerts := range [][]*model.Alert{testAlerts[:1], testAlerts[1:]} {
+    if err := n.Notify(context.Background(), alerts); err != nil { t.Fatal(err) }
+  }
+
+  data, err := os.ReadFile(path)
+  if err != nil { t.Fatal(err) }
+  lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+  if len(lines) != 2 { t.Fatalf("want 2 lines, got %q", data) }
+  var got dto.AlertDTO
+  if err := json.Unmarshal([]byte(lines[1]), &got); err != nil { t.Fatal(err) }
+  if got.ID != 2 || got.Yyyymmdd != "20250718" || got.Message != testAlerts[1].Message { t.Errorf("Unexpected line: %+v", got) }
+}
+
+func TestWebhookNotifier(t *testing.T) {
+  var payload alert.WebhookPayload
+  server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" { t.Errorf("Unexpected request %s %s", r.Method, r.Header.Get("Content-Type")) }
+    if err := json.NewDecoder(r.Body).Decode(&payload); err != nil { t.Error(err) }
+    w.WriteHeader(http.StatusNoContent)
+  }))
+  defer server.Close()
+
+  if err := (&alert.WebhookNotifier{URL: server.URL, Client: server.Client()}).Notify(context.Background(), testAlerts); err != nil { t.Fatal(err) }
+  if len(payload.Alerts) != 2 || payload.Alerts[0].Code != "5253" || payload.Alerts[1].RuleID != 2 { t.Errorf("Unexpected payload: %+v", payload) }
+
+  failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusBadGateway) }))
+  defer failing.Close()
+  if err := (&alert.WebhookNotifier{URL: failing.URL}).Notify(context.Background(), testAlerts); err == nil || !strings.Contains(err.Error(), "502") { t.Errorf("Expected a 502 error, got %v", err) }
+}
+
+// fakeSMTP accepts one mail without auth or TLS and sends its data.
+func fakeSMTP(t *testing.T) (string, <-chan string) {
+  ln, err := net.Listen("tcp", "127.0.0.1:0")
+  if err != nil { t.Fatal(err) }
+  t.Cleanup(func() { ln.Close() })
+
+  received := make(chan string, 1)
+  go func() {
+    conn, err := ln.Accept()
+    if err != nil { return }
+    defer conn.Close()
+
+    r := bufio.NewReader(conn)
+    reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+    reply("220 localhost ESMTP")
+    for {
+      line, err := r.ReadString('\n')
+      if err != nil { return }
+      switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+        case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+          reply("250 localhost")
+        case strings.HasPrefix(cmd, "DATA"):
+          reply("354 End data with <CR><LF>.<CR><LF>")
+          var data strings.Builder
+          for {
+            line, err := r.ReadString('\n')
+            if err != nil { return }
+            if line == ".\r\n" { break }
+            data.WriteString(line)
+          }
+          received <- data.String()
+          reply("250 OK")
+        case strings.HasPrefix(cmd, "QUIT"):
+          reply("221 Bye")
+          return
+        default:
+          reply("250 OK")
+      }
+    }
+  }()
+
+  return ln.Addr().String(), received
+}
+
+func TestSMTPNotifier(t *testing.T) {
+  addr, received := fakeSMTP(t)
+  n := &alert.SMTPNotifier{Addr: addr, From: "dunn@example.com", To: []string{"me@example.com", "you@example.com"}}
+  if err := n.Notify(context.Background(), testAlerts); err != nil { t.Fatal(err) }
+
+  select {
+    case data := <-received:
+      for _, want := range []string{"To: me@example.com, you@example.com\r\n", "Subject: [dunn-finance] 2 alerts\r\n", testAlerts[0].Message + "\r\n", testAlerts[1].Message + "\r\n"} {
+        if !strings.Contains(data, want) { t.Errorf("Mail lacks %q:\n%s", want, data) }
+      }
+    case <-time.After(5 * time.Second):
+      t.Fatal("No mail received")
+  }
+}
+
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(ctx context.Context, alerts []*model.Alert) error { return errors.New("down") }
+
+func TestNotifiers_ContinueAfterFailure(t *testing.T) {
+  var sb strings.Builder
+  err := alert.Notifiers{failingNotifier{}, &alert.WriterNotifier{W: &sb}}.Notify(context.Background(), testAlerts)
+  if err == nil || err.Error() != "down" { t.Errorf("Expected the failure, got %v", err) }
+  if !strings.Contains(sb.String(), "[ALERT]") { t.Error("Expected the second notifier to run") }
+}
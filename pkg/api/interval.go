@@ -0,0 +1,88 @@
+package api
+
+import (
+  "fmt"
+
+  "dunn-finance/pkg/model"
+)
+
+// Interval is the bar size of the ohlcv endpoint.
+type Interval string
+
+const (
+  IntervalDaily   Interval = "1d"
+  IntervalWeekly  Interval = "1w"
+  IntervalMonthly Interval = "1mo"
+)
+
+func ParseInterval(s string) (Interval, error) {
+  switch s {
+    case "", "1d", "daily":
+      return IntervalDaily, nil
+    case "1w", "weekly":
+      return IntervalWeekly, nil
+    case "1mo", "monthly":
+      return IntervalMonthly, nil
+    default:
+      return "", fmt.Errorf("unknown interval %q: want 1d, 1w or 1mo", s)
+  }
+}
+
+// periodStart returns the Monday of the week, or the first of the month.
+func (i Interval) periodStart(d model.Date) model.Date {
+  t := d.Time()
+  switch i {
+    case IntervalWeekly:
+      offset := (int(t.Weekday()) + 6) % 7
+      return d.AddDays(-offset)
+    case IntervalMonthly:
+      return model.NewDate(t.Year(), t.Month(), 1)
+    default:
+      return d
+  }
+}
+
+// Resample merges daily bars in date order into bars of the interval. A bar
+// is dated by its first trading day and has the first open, the highest high,
+// the lowest low, the last close and the total volume. Moving averages do not
+// carry over and are left nil.
+func Resample(bars []*model.AdjustedDailyOHLCV, interval Interval) []*model.AdjustedDailyOHLCV {
+  if interval == IntervalDaily { return bars }
+
+  var result []*model.AdjustedDailyOHLCV
+  var current *model.AdjustedDailyOHLCV
+  var currentPeriod model.Date
+  for _, bar := range bars {
+    period := interval.periodStart(bar.Yyyymmdd)
+    if current == nil || period != currentPeriod {
+      current = &model.AdjustedDailyOHLCV{Yyyymmdd: bar.Yyyymmdd, Code: bar.Code}
+      currentPeriod = period
+      result = append(result, current)
+    }
+
+    if current.OpenPrice == nil { current.OpenPrice = copyOf(bar.OpenPrice) }
+    current.HighPrice = pick(current.HighPrice, bar.HighPrice, func(a, b float64) bool { return b > a })
+    current.LowPrice = pick(current.LowPrice, bar.LowPrice, func(a, b float64) bool { return b < a })
+    if bar.ClosePrice != nil { current.ClosePrice = copyOf(bar.ClosePrice) }
+    if bar.Volume != nil {
+      sum := *bar.Volume
+      if current.Volume != nil { sum += *current.Volume }
+      current.Volume = &sum
+    }
+  }
+
+  return result
+}
+
+func copyOf(v *float64) *float64 {
+  if v == nil { return nil }
+  c := *v
+  return &c
+}
+
+// pick returns b when a is nil or better(a, b), otherwise a.
+func pick(a *float64, b *float64, better func(a, b float64) bool) *float64 {
+  if b == nil { return a }
+  if a == nil || better(*a, *b) { return copyOf(b) }
+  return a
+}
@@ -0,0 +1,236 @@
+package api
+
+import (
+  "bytes"
+  "crypto/sha256"
+  "database/sql"
+  "encoding/hex"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "log"
+  "net/http"
+  "strconv"
+  "strings"
+
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/dto"
+  "dunn-finance/pkg/mapper"
+  "dunn-finance/pkg/model"
+)
+
+const (
+  DefaultLimit = 100
+  MaxLimit     = 1000
+)
+
+// Server serves stocks and their bars as JSON:
+//
+//   GET /stocks?limit=&offset=
+//   GET /stocks/{code}
+//   GET /stocks/{code}/ohlcv?from=&to=&interval=&limit=&offset=
+//
+// Lists are paginated with limit and offset. Every response carries an ETag
+// of its body, and a request whose If-None-Match matches gets 304.
+type Server struct {
+  DB database.DBConnector
+}
+
+// Page is the envelope of a list response.
+type Page[T any] struct {
+  Items  []T `json:"items"`
+  Total  int `json:"total"`
+  Limit  int `json:"limit"`
+  Offset int `json:"offset"`
+}
+
+// ErrorResponse is the body of every non-2xx response.
+type ErrorResponse struct {
+  Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+  Status  int    `json:"status"`
+  Message string `json:"message"`
+}
+
+// httpError is an error with the status to respond with.
+type httpError struct {
+  status  int
+  message string
+}
+
+func (e *httpError) Error() string { return e.message }
+
+func badRequest(format string, args ...any) error {
+  return &httpError{status: http.StatusBadRequest, message: fmt.Sprintf(format, args...)}
+}
+
+func notFound(format string, args ...any) error {
+  return &httpError{status: http.StatusNotFound, message: fmt.Sprintf(format, args...)}
+}
+
+func (s *Server) Handler() http.Handler {
+  mux := http.NewServeMux()
+  for pattern, fn := range map[string]func(r *http.Request) (any, error){
+    "/stocks":              s.listStocks,
+    "/stocks/{code}":       s.getStock,
+    "/stocks/{code}/ohlcv": s.listOHLCV,
+  } {
+    mux.HandleFunc("GET "+pattern, s.handle(fn))
+    // Without this the mux answers other methods with a plain text 405.
+    mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+      w.Header().Set("Allow", "GET, HEAD")
+      writeError(w, r, &httpError{status: http.StatusMethodNotAllowed, message: r.Method + " is not allowed"})
+    })
+  }
+  mux.HandleFunc("/", s.handle(func(r *http.Request) (any, error) {
+    return nil, notFound("no route for %s", r.URL.Path)
+  }))
+
+  return mux
+}
+
+// handle turns a handler returning a value into JSON with an ETag, and an
+// error into ErrorResponse.
+func (s *Server) handle(fn func(r *http.Request) (any, error)) http.HandlerFunc {
+  return func(w http.ResponseWriter, r *http.Request) {
+    v, err := fn(r)
+    if err != nil {
+      writeError(w, r, err)
+      return
+    }
+
+    body, err := json.Marshal(v)
+    if err != nil {
+      writeError(w, r, err)
+      return
+    }
+
+    sum := sha256.Sum256(body)
+    etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+    w.Header().Set("ETag", etag)
+    w.Header().Set("Cache-Control", "no-cache")
+    if matchesETag(r.Header.Get("If-None-Match"), etag) {
+      w.WriteHeader(http.StatusNotModified)
+      return
+    }
+
+    w.Header().Set("Content-Type", "application/json; charset=utf-8")
+    w.Write(body)
+  }
+}
+
+func matchesETag(ifNoneMatch string, etag string) bool {
+  for _, candidate := range strings.Split(ifNoneMatch, ",") {
+    candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+    if candidate == etag || candidate == "*" { return true }
+  }
+  return false
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+  status, message := http.StatusInternalServerError, "internal server error"
+  var he *httpError
+  if errors.As(err, &he) {
+    status, message = he.status, he.message
+  } else {
+    log.Printf("[ERROR] %s %s: %v\n", r.Method, r.URL.Path, err)
+  }
+
+  var buf bytes.Buffer
+  _ = json.NewEncoder(&buf).Encode(ErrorResponse{Error: ErrorBody{Status: status, Message: message}})
+  w.Header().Set("Content-Type", "application/json; charset=utf-8")
+  w.WriteHeader(status)
+  w.Write(buf.Bytes())
+}
+
+func pagination(r *http.Request) (int, int, error) {
+  limit, offset := DefaultLimit, 0
+  if s := r.URL.Query().Get("limit"); s != "" {
+    n, err := strconv.Atoi(s)
+    if err != nil || n < 1 || n > MaxLimit { return 0, 0, badRequest("limit must be between 1 and %d", MaxLimit) }
+    limit = n
+  }
+  if s := r.URL.Query().Get("offset"); s != "" {
+    n, err := strconv.Atoi(s)
+    if err != nil || n < 0 { return 0, 0, badRequest("offset must not be negative") }
+    offset = n
+  }
+
+  return limit, offset, nil
+}
+
+func dateParam(r *http.Request, name string) (model.Date, error) {
+  s := r.URL.Query().Get(name)
+  if s == "" { return model.Date{}, nil }
+
+  d, err := model.ParseDate(s)
+  if err != nil { return model.Date{}, badRequest("%s: %v", name, err) }
+  return d, nil
+}
+
+func (s *Server) listStocks(r *http.Request) (any, error) {
+  limit, offset, err := pagination(r)
+  if err != nil { return nil, err }
+
+  stockDao := dao.NewStockDAO(s.DB)
+  total, err := stockDao.Count(r.Context(), "")
+  if err != nil { return nil, err }
+  stocks, err := stockDao.FindPage(r.Context(), limit, offset)
+  if err != nil { return nil, err }
+
+  page := &Page[*dto.StockDTO]{Items: []*dto.StockDTO{}, Total: int(total), Limit: limit, Offset: offset}
+  for _, stock := range stocks {
+    page.Items = append(page.Items, mapper.ToStockDTO(stock))
+  }
+
+  return page, nil
+}
+
+func (s *Server) findStock(r *http.Request) (*model.Stock, error) {
+  code := r.PathValue("code")
+  stock, err := dao.NewStockDAO(s.DB).Find(r.Context(), code)
+  if errors.Is(err, sql.ErrNoRows) { return nil, notFound("stock %s not found", code) }
+  if err != nil { return nil, err }
+
+  return stock, nil
+}
+
+func (s *Server) getStock(r *http.Request) (any, error) {
+  stock, err := s.findStock(r)
+  if err != nil { return nil, err }
+
+  return mapper.ToStockDTO(stock), nil
+}
+
+func (s *Server) listOHLCV(r *http.Request) (any, error) {
+  stock, err := s.findStock(r)
+  if err != nil { return nil, err }
+
+  limit, offset, err := pagination(r)
+  if err != nil { return nil, err }
+  from, err := dateParam(r, "from")
+  if err != nil { return nil, err }
+  to, err := dateParam(r, "to")
+  if err != nil { return nil, err }
+  if !from.IsZero() && !to.IsZero() && from.After(to) { return nil, badRequest("from %s is after to %s", from, to) }
+  interval, err := ParseInterval(r.URL.Query().Get("interval"))
+  if err != nil { return nil, badRequest("%v", err) }
+
+  // Bars are resampled before paginating, so a page never splits a week.
+  var bars []*model.AdjustedDailyOHLCV
+  for bar, err := range dao.NewAdjustedDailyOHLCVDAO(s.DB).IterateFiltered(r.Context(), []string{stock.Code}, from, to) {
+    if err != nil { return nil, err }
+    bars = append(bars, bar)
+  }
+  bars = Resample(bars, interval)
+
+  page := &Page[*dto.AdjustedDailyOHLCV]{Items: []*dto.AdjustedDailyOHLCV{}, Total: len(bars), Limit: limit, Offset: offset}
+  for i := offset; i < len(bars) && i < offset+limit; i++ {
+    page.Items = append(page.Items, mapper.ToAdjustedDailyOHLCVDTO(bars[i]))
+  }
+
+  return page, nil
+}
@@ -0,0 +1,149 @@
+package api_test
+
+import (
+  "context"
+  "encoding/json"
+  "net/http"
+  "net/http/httptest"
+  "testing"
+
+  _ "github.com/mattn/go-sqlite3"
+
+  "dunn-finance/pkg/api"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/dto"
+  "dunn-finance/pkg/model"
+)
+
+func f(v float64) *float64 { return &v }
+
+func newTestServer(t *testing.T) *httptest.Server {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+
+  stockDao := dao.NewStockDAO(db)
+  for _, stock := range []*model.Stock{{Code: "7203", Name: "トヨタ自動車"}, {Code: "1234", Name: "テスト会社"}, {Code: "9984", Name: "ソフトバンクグループ"}} {
+    if err := stockDao.Create(ctx, stock); err != nil { t.Fatal(err) }
+  }
+
+  // Mon 2025-06-30 to Fri 2025-07-11, two weeks across a month end.
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(db)
+  for i, date := range []string{"20250630", "20250701", "20250702", "20250703", "20250704", "20250707", "20250708", "20250709", "20250710", "20250711"} {
+    price := 100 + float64(i)
+    ohlcv := &model.AdjustedDailyOHLCV{
+      Yyyymmdd: model.MustParseDate(date), Code: "1234",
+      OpenPrice: f(price), HighPrice: f(price + 5), LowPrice: f(price - 5), ClosePrice: f(price + 1), Volume: f(1000),
+    }
+    if err := ohlcvDao.Create(ctx, ohlcv); err != nil { t.Fatal(err) }
+  }
+
+  server := httptest.NewServer((&api.Server{DB: db}).Handler())
+  t.Cleanup(server.Close)
+
+  return server
+}
+
+func get(t *testing.T, server *httptest.Server, path string, header http.Header, v any) *http.Response {
+  req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+  if err != nil { t.Fatal(err) }
+  for k, values := range header {
+    req.Header[k] = values
+  }
+
+  res, err := server.Client().Do(req)
+  if err != nil { t.Fatal(err) }
+  defer res.Body.Close()
+
+  if v != nil && res.StatusCode != http.StatusNotModified {
+    if err := json.NewDecoder(res.Body).Decode(v); err != nil { t.Fatalf("Failed to decode %s: %v", path, err) }
+  }
+
+  return res
+}
+
+func TestServer_ListStocks(t *testing.T) {
+  server := newTestServer(t)
+
+  var page api.Page[*dto.StockDTO]
+  res := get(t, server, "/stocks?limit=2&offset=1", nil, &page)
+  if res.StatusCode != http.StatusOK { t.Fatalf("got status %d", res.StatusCode) }
+  if res.Header.Get("Content-Type") != "application/json; charset=utf-8" { t.Errorf("Unexpected content type %q", res.Header.Get("Content-Type")) }
+  if page.Total != 3 || page.Limit != 2 || page.Offset != 1 { t.Errorf("Unexpected page: %+v", page) }
+  if len(page.Items) != 2 || page.Items[0].Code != "7203" || page.Items[1].Name != "ソフトバンクグループ" { t.Errorf("Unexpected items: %+v", page.Items) }
+}
+
+func TestServer_GetStock(t *testing.T) {
+  server := newTestServer(t)
+
+  var stock dto.StockDTO
+  if res := get(t, server, "/stocks/7203", nil, &stock); res.StatusCode != http.StatusOK { t.Fatalf("got status %d", res.StatusCode) }
+  if stock.Code != "7203" || stock.Name != "トヨタ自動車" { t.Errorf("Unexpected stock: %+v", stock) }
+
+  var errRes api.ErrorResponse
+  res := get(t, server, "/stocks/0000", nil, &errRes)
+  if res.StatusCode != http.StatusNotFound || errRes.Error.Status != http.StatusNotFound || errRes.Error.Message != "stock 0000 not found" { t.Errorf("Unexpected error: %d %+v", res.StatusCode, errRes) }
+}
+
+func TestServer_ListOHLCV(t *testing.T) {
+  server := newTestServer(t)
+
+  var page api.Page[*dto.AdjustedDailyOHLCV]
+  res := get(t, server, "/stocks/1234/ohlcv?from=2025-07-01&to=20250709&limit=3&offset=1", nil, &page)
+  if res.StatusCode != http.StatusOK { t.Fatalf("got status %d", res.StatusCode) }
+  if page.Total != 7 || len(page.Items) != 3 { t.Fatalf("Unexpected page: total %d, %d items", page.Total, len(page.Items)) }
+  if page.Items[0].Yyyymmdd != "20250702" || *page.Items[0].ClosePrice != 103 { t.Errorf("Unexpected first bar: %+v", page.Items[0]) }
+  if page.Items[0].DMAPrice5 != nil { t.Errorf("Expected a missing DMA to be null") }
+}
+
+func TestServer_ListOHLCV_Interval(t *testing.T) {
+  server := newTestServer(t)
+
+  var weekly api.Page[*dto.AdjustedDailyOHLCV]
+  get(t, server, "/stocks/1234/ohlcv?interval=1w", nil, &weekly)
+  if weekly.Total != 2 { t.Fatalf("want 2 weeks, got %d", weekly.Total) }
+  week := weekly.Items[0]
+  if week.Yyyymmdd != "20250630" || *week.OpenPrice != 100 || *week.HighPrice != 109 || *week.LowPrice != 95 || *week.ClosePrice != 105 || *week.Volume != 5000 { t.Errorf("Unexpected week: %+v", week) }
+
+  var monthly api.Page[*dto.AdjustedDailyOHLCV]
+  get(t, server, "/stocks/1234/ohlcv?interval=monthly", nil, &monthly)
+  if monthly.Total != 2 || monthly.Items[0].Yyyymmdd != "20250630" || monthly.Items[1].Yyyymmdd != "20250701" || *monthly.Items[1].Volume != 9000 { t.Errorf("Unexpected months: %+v", monthly.Items) }
+}
+
+func TestServer_BadRequests(t *testing.T) {
+  server := newTestServer(t)
+
+  for _, path := range []string{
+    "/stocks?limit=0",
+    "/stocks?limit=abc",
+    "/stocks?offset=-1",
+    "/stocks/1234/ohlcv?from=2025-02-30",
+    "/stocks/1234/ohlcv?from=20250710&to=20250701",
+    "/stocks/1234/ohlcv?interval=1h",
+  } {
+    var errRes api.ErrorResponse
+    res := get(t, server, path, nil, &errRes)
+    if res.StatusCode != http.StatusBadRequest || errRes.Error.Status != http.StatusBadRequest || errRes.Error.Message == "" { t.Errorf("%s: got %d %+v", path, res.StatusCode, errRes) }
+  }
+
+  var errRes api.ErrorResponse
+  if res := get(t, server, "/nothing", nil, &errRes); res.StatusCode != http.StatusNotFound { t.Errorf("Expected 404, got %d", res.StatusCode) }
+
+  res, err := server.Client().Post(server.URL+"/stocks", "application/json", nil)
+  if err != nil { t.Fatal(err) }
+  res.Body.Close()
+  if res.StatusCode != http.StatusMethodNotAllowed || res.Header.Get("Content-Type") != "application/json; charset=utf-8" { t.Errorf("Expected a JSON 405, got %d %s", res.StatusCode, res.Header.Get("Content-Type")) }
+}
+
+func TestServer_ETag(t *testing.T) {
+  server := newTestServer(t)
+
+  first := get(t, server, "/stocks/1234/ohlcv", nil, &api.Page[*dto.AdjustedDailyOHLCV]{})
+  etag := first.Header.Get("ETag")
+  if etag == "" { t.Fatal("Expected an ETag") }
+
+  cached := get(t, server, "/stocks/1234/ohlcv", http.Header{"If-None-Match": {etag}}, nil)
+  if cached.StatusCode != http.StatusNotModified { t.Errorf("Expected 304, got %d", cached.StatusCode) }
+
+  other := get(t, server, "/stocks/1234/ohlcv?limit=1", http.Header{"If-None-Match": {etag}}, &api.Page[*dto.AdjustedDailyOHLCV]{})
+  if other.StatusCode != http.StatusOK || other.Header.Get("ETag") == etag { t.Errorf("Expected a new body and ETag, got %d %s", other.StatusCode, other.Header.Get("ETag")) }
+}
@@ -1,13 +1,90 @@
 package browser
 
 import (
+  "fmt"
+  "net/http"
+
   "github.com/go-rod/rod"
   "github.com/go-rod/rod/lib/launcher"
+  "github.com/go-rod/rod/lib/proto"
 )
 
 func NewBrowser() *rod.Browser {
-  url := launcher.New().MustLaunch()
+  return NewBrowserWithOptions(Options{Headless: true})
+}
+
+// Options are the launch settings of the browser.
+type Options struct {
+  // Bin is the Chrome or Chromium binary. Empty downloads one.
+  Bin         string
+  Headless    bool
+  // UserDataDir keeps the profile, such as cookies, across runs.
+  UserDataDir string
+}
+
+func NewBrowserWithOptions(opts Options) *rod.Browser {
+  l := launcher.New().Headless(opts.Headless)
+  if opts.Bin != "" { l = l.Bin(opts.Bin) }
+  if opts.UserDataDir != "" { l = l.UserDataDir(opts.UserDataDir) }
+  url := l.MustLaunch()
   browser := rod.New().ControlURL(url).MustConnect()
 
   return browser
 }
+
+// PageFetcher renders pages with a rod browser.
+// It implements sites.Fetcher.
+type PageFetcher struct {
+  Browser *rod.Browser
+}
+
+func (f *PageFetcher) FetchHTML(url string) (string, error) {
+  page, err := f.Browser.Page(proto.TargetCreateTarget{URL: url})
+  if err != nil { return "", err }
+  defer page.Close()
+
+  if err := page.WaitLoad(); err != nil { return "", err }
+
+  return page.HTML()
+}
+
+// SubmitForm fills in the named inputs of the form and submits it.
+// The browser keeps the session cookies for later FetchHTML calls.
+func (f *PageFetcher) SubmitForm(url string, formSelector string, values map[string]string) error {
+  page, err := f.Browser.Page(proto.TargetCreateTarget{URL: url})
+  if err != nil { return err }
+  defer page.Close()
+
+  if err := page.WaitLoad(); err != nil { return err }
+
+  form, err := page.Element(formSelector)
+  if err != nil { return err }
+
+  for name, value := range values {
+    input, err := form.Element(fmt.Sprintf("[name=%q]", name))
+    if err != nil { return err }
+    if err := input.Input(value); err != nil { return err }
+  }
+
+  wait := page.WaitNavigation(proto.PageLifecycleEventNameNetworkAlmostIdle)
+  if _, err := form.Eval(`() => this.submit()`); err != nil { return err }
+  wait()
+
+  return nil
+}
+
+// SetCookies copies cookies, e.g. from an HTTP login, into the browser.
+func (f *PageFetcher) SetCookies(url string, cookies []*http.Cookie) error {
+  params := make([]*proto.NetworkCookieParam, 0, len(cookies))
+  for _, c := range cookies {
+    params = append(params, &proto.NetworkCookieParam{
+      Name:     c.Name,
+      Value:    c.Value,
+      URL:      url,
+      Secure:   c.Secure,
+      HTTPOnly: c.HttpOnly,
+    })
+  }
+
+  return f.Browser.SetCookies(params)
+}
@@ -0,0 +1,223 @@
+package httpfetcher
+
+import (
+  "errors"
+  "fmt"
+  "io"
+  "net/http"
+  "net/http/cookiejar"
+  "net/url"
+  "strings"
+  "time"
+
+  "golang.org/x/net/html"
+)
+
+// ErrJavaScriptRequired is returned when the page cannot be used without
+// running its scripts. sites.FallbackFetcher switches to the browser on it.
+var ErrJavaScriptRequired = errors.New("page requires JavaScript")
+
+const defaultUserAgent = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36"
+
+// Fetcher fetches pages with net/http instead of launching Chromium.
+// It keeps cookies between requests so a form login carries over.
+// It implements sites.Fetcher and sites.FormSubmitter.
+type Fetcher struct {
+  Client    *http.Client
+  UserAgent string
+}
+
+func New() *Fetcher {
+  jar, _ := cookiejar.New(nil) // cookiejar.New never fails without options.
+
+  return &Fetcher{
+    Client:    &http.Client{Jar: jar, Timeout: 30 * time.Second},
+    UserAgent: defaultUserAgent,
+  }
+}
+
+func (f *Fetcher) FetchHTML(rawURL string) (string, error) {
+  body, err := f.get(rawURL)
+  if err != nil { return "", err }
+
+  doc := string(body)
+  if requiresJavaScript(doc) { return "", fmt.Errorf("%w: %s", ErrJavaScriptRequired, rawURL) }
+
+  return doc, nil
+}
+
+// Download returns the raw response body, e.g. a CSV export.
+func (f *Fetcher) Download(rawURL string) ([]byte, error) {
+  return f.get(rawURL)
+}
+
+// SubmitForm loads the page holding the form, keeps its hidden inputs such as
+// CSRF tokens, overrides the given values and posts it to the form action.
+func (f *Fetcher) SubmitForm(rawURL string, formSelector string, values map[string]string) error {
+  body, err := f.get(rawURL)
+  if err != nil { return err }
+
+  root, err := html.Parse(strings.NewReader(string(body)))
+  if err != nil { return err }
+
+  form := findForm(root, formSelector)
+  if form == nil { return fmt.Errorf("%w: form %s not found in %s", ErrJavaScriptRequired, formSelector, rawURL) }
+
+  fields := formFields(form)
+  for name, value := range values {
+    fields.Set(name, value)
+  }
+
+  action, err := resolveAction(rawURL, attr(form, "action"))
+  if err != nil { return err }
+
+  method := strings.ToUpper(attr(form, "method"))
+  if method == "" { method = http.MethodGet }
+
+  var req *http.Request
+  if method == http.MethodGet {
+    action.RawQuery = fields.Encode()
+    req, err = http.NewRequest(http.MethodGet, action.String(), nil)
+  } else {
+    req, err = http.NewRequest(method, action.String(), strings.NewReader(fields.Encode()))
+    if req != nil { req.Header.Set("Content-Type", "application/x-www-form-urlencoded") }
+  }
+  if err != nil { return err }
+  req.Header.Set("Referer", rawURL)
+
+  _, err = f.do(req)
+
+  return err
+}
+
+// Cookies returns the session cookies for rawURL so that a browser fallback
+// can continue the same session.
+func (f *Fetcher) Cookies(rawURL string) []*http.Cookie {
+  u, err := url.Parse(rawURL)
+  if err != nil || f.Client.Jar == nil { return nil }
+
+  return f.Client.Jar.Cookies(u)
+}
+
+func (f *Fetcher) get(rawURL string) ([]byte, error) {
+  req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+  if err != nil { return nil, err }
+
+  return f.do(req)
+}
+
+func (f *Fetcher) do(req *http.Request) ([]byte, error) {
+  if f.UserAgent != "" { req.Header.Set("User-Agent", f.UserAgent) }
+
+  res, err := f.Client.Do(req)
+  if err != nil { return nil, err }
+  defer res.Body.Close()
+
+  body, err := io.ReadAll(res.Body)
+  if err != nil { return nil, err }
+  if res.StatusCode >= 400 { return nil, fmt.Errorf("%s %s: %s", req.Method, req.URL, res.Status) }
+
+  return body, nil
+}
+
+// requiresJavaScript reports whether the body has nothing but scripts,
+// i.e. the page is a shell rendered on the client.
+func requiresJavaScript(doc string) bool {
+  root, err := html.Parse(strings.NewReader(doc))
+  if err != nil { return false }
+
+  body := findElement(root, func(n *html.Node) bool { return n.Data == "body" })
+  if body == nil { return false }
+
+  hasContent := false
+  var walk func(n *html.Node)
+  walk = func(n *html.Node) {
+    if hasContent { return }
+    if n.Type == html.ElementNode && (n.Data == "script" || n.Data == "noscript" || n.Data == "style") { return }
+    if n.Type == html.TextNode && strings.TrimSpace(n.Data) != "" {
+      hasContent = true
+      return
+    }
+    for c := n.FirstChild; c != nil; c = c.NextSibling {
+      walk(c)
+    }
+  }
+  walk(body)
+
+  return !hasContent
+}
+
+// findForm supports the selectors used by the site packages:
+// "form", "form#id" and "form[name=value]".
+func findForm(root *html.Node, selector string) *html.Node {
+  var key, value string
+  switch {
+    case strings.HasPrefix(selector, "form#"):
+      key, value = "id", strings.TrimPrefix(selector, "form#")
+    case strings.HasPrefix(selector, "form[") && strings.HasSuffix(selector, "]"):
+      kv := strings.SplitN(strings.TrimSuffix(strings.TrimPrefix(selector, "form["), "]"), "=", 2)
+      key = kv[0]
+      if len(kv) == 2 { value = strings.Trim(kv[1], `"'`) }
+  }
+
+  return findElement(root, func(n *html.Node) bool {
+    if n.Data != "form" { return false }
+    if key == "" { return true }
+    return attr(n, key) == value
+  })
+}
+
+func formFields(form *html.Node) url.Values {
+  fields := url.Values{}
+  var walk func(n *html.Node)
+  walk = func(n *html.Node) {
+    if n.Type == html.ElementNode && (n.Data == "input" || n.Data == "textarea" || n.Data == "select") {
+      name := attr(n, "name")
+      inputType := strings.ToLower(attr(n, "type"))
+      skip := name == "" || inputType == "submit" || inputType == "button" || inputType == "image"
+      if (inputType == "checkbox" || inputType == "radio") && !hasAttr(n, "checked") { skip = true }
+      if !skip { fields.Set(name, attr(n, "value")) }
+    }
+    for c := n.FirstChild; c != nil; c = c.NextSibling {
+      walk(c)
+    }
+  }
+  walk(form)
+
+  return fields
+}
+
+func resolveAction(pageURL string, action string) (*url.URL, error) {
+  base, err := url.Parse(pageURL)
+  if err != nil { return nil, err }
+
+  ref, err := url.Parse(action)
+  if err != nil { return nil, err }
+
+  return base.ResolveReference(ref), nil
+}
+
+func findElement(n *html.Node, match func(n *html.Node) bool) *html.Node {
+  if n.Type == html.ElementNode && match(n) { return n }
+  for c := n.FirstChild; c != nil; c = c.NextSibling {
+    if found := findElement(c, match); found != nil { return found }
+  }
+
+  return nil
+}
+
+func attr(n *html.Node, key string) string {
+  for _, a := range n.Attr {
+    if a.Key == key { return a.Val }
+  }
+
+  return ""
+}
+
+func hasAttr(n *html.Node, key string) bool {
+  for _, a := range n.Attr {
+    if a.Key == key { return true }
+  }
+
+  return false
+}
@@ -0,0 +1,123 @@
+package httpfetcher_test
+
+import (
+  "errors"
+  "net/http"
+  "net/http/httptest"
+  "strings"
+  "testing"
+
+  "dunn-finance/pkg/browser/httpfetcher"
+)
+
+const csrfToken = "token-abc"
+
+const loginPage = `<!DOCTYPE html>
+<html><body>
+  <form name="form_login" method="post" action="/login">
+    <input type="hidden" name="csrf_token" value="` + csrfToken + `">
+    <input type="text" name="user_id" value="">
+    <input type="password" name="user_password" value="">
+    <input type="submit" name="ACT_login" value="ログイン">
+  </form>
+</body></html>`
+
+const csvBody = "日付,始値,高値,安値,終値\n2025/07/18,\"2,189\",\"2,212\",\"2,123\",\"2,136\"\n"
+
+func newServer(t *testing.T) *httptest.Server {
+  mux := http.NewServeMux()
+  mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodGet {
+      w.Write([]byte(loginPage))
+      return
+    }
+
+    if err := r.ParseForm(); err != nil { t.Fatal(err) }
+    if r.PostForm.Get("csrf_token") != csrfToken {
+      http.Error(w, "invalid csrf token", http.StatusForbidden)
+      return
+    }
+    if r.PostForm.Get("user_id") != "user" || r.PostForm.Get("user_password") != "pw" {
+      http.Error(w, "invalid credential", http.StatusUnauthorized)
+      return
+    }
+    http.SetCookie(w, &http.Cookie{Name: "session", Value: "logged-in", Path: "/"})
+    http.Redirect(w, r, "/home", http.StatusFound)
+  })
+  mux.HandleFunc("/home", func(w http.ResponseWriter, r *http.Request) {
+    w.Write([]byte("<html><body><h1>ようこそ</h1></body></html>"))
+  })
+  mux.HandleFunc("/timechart.csv", func(w http.ResponseWriter, r *http.Request) {
+    if c, err := r.Cookie("session"); err != nil || c.Value != "logged-in" {
+      http.Error(w, "login required", http.StatusUnauthorized)
+      return
+    }
+    w.Header().Set("Content-Type", "text/csv")
+    w.Write([]byte(csvBody))
+  })
+  mux.HandleFunc("/spa", func(w http.ResponseWriter, r *http.Request) {
+    w.Write([]byte(`<html><body><div id="root"></div><noscript>JavaScript を有効にしてください</noscript><script src="/app.js"></script></body></html>`))
+  })
+
+  server := httptest.NewServer(mux)
+  t.Cleanup(server.Close)
+
+  return server
+}
+
+func TestFetcher_FetchHTML_Success(t *testing.T) {
+  server := newServer(t)
+  fetcher := httpfetcher.New()
+
+  doc, err := fetcher.FetchHTML(server.URL + "/home")
+  if err != nil { t.Fatal(err) }
+  if !strings.Contains(doc, "ようこそ") { t.Errorf("Unexpected document: %s", doc) }
+}
+
+func TestFetcher_FetchHTML_JavaScriptRequired(t *testing.T) {
+  server := newServer(t)
+  fetcher := httpfetcher.New()
+
+  _, err := fetcher.FetchHTML(server.URL + "/spa")
+  if !errors.Is(err, httpfetcher.ErrJavaScriptRequired) { t.Errorf("Expected ErrJavaScriptRequired, got %v", err) }
+}
+
+func TestFetcher_SubmitForm_Download_Success(t *testing.T) {
+  server := newServer(t)
+  fetcher := httpfetcher.New()
+
+  _, err := fetcher.Download(server.URL + "/timechart.csv")
+  if err == nil { t.Fatalf("Expected error before login, got nil") }
+
+  err = fetcher.SubmitForm(server.URL+"/login", "form[name=form_login]", map[string]string{
+    "user_id":       "user",
+    "user_password": "pw",
+  })
+  if err != nil { t.Fatal(err) }
+
+  body, err := fetcher.Download(server.URL + "/timechart.csv")
+  if err != nil { t.Fatal(err) }
+  if string(body) != csvBody { t.Errorf("got %q, want %q", body, csvBody) }
+
+  cookies := fetcher.Cookies(server.URL)
+  if len(cookies) != 1 || cookies[0].Value != "logged-in" { t.Errorf("Unexpected cookies: %v", cookies) }
+}
+
+func TestFetcher_SubmitForm_Failure(t *testing.T) {
+  server := newServer(t)
+
+  t.Run("wrong credential", func(t *testing.T) {
+    fetcher := httpfetcher.New()
+    err := fetcher.SubmitForm(server.URL+"/login", "form[name=form_login]", map[string]string{
+      "user_id":       "user",
+      "user_password": "wrong",
+    })
+    if err == nil { t.Errorf("Expected error for wrong credential, got nil") }
+  })
+
+  t.Run("form not found", func(t *testing.T) {
+    fetcher := httpfetcher.New()
+    err := fetcher.SubmitForm(server.URL+"/spa", "form[name=form_login]", nil)
+    if !errors.Is(err, httpfetcher.ErrJavaScriptRequired) { t.Errorf("Expected ErrJavaScriptRequired, got %v", err) }
+  })
+}
@@ -0,0 +1,105 @@
+package sites
+
+import (
+  "fmt"
+  "net/http"
+  "sync"
+)
+
+// Downloader is implemented by fetchers that can return a raw response body,
+// e.g. a CSV export.
+type Downloader interface {
+  Download(url string) ([]byte, error)
+}
+
+// CookieSource and CookieSink let FallbackFetcher hand the session over to
+// the fallback fetcher, so a login made over HTTP is kept in the browser.
+type CookieSource interface {
+  Cookies(url string) []*http.Cookie
+}
+
+type CookieSink interface {
+  SetCookies(url string, cookies []*http.Cookie) error
+}
+
+// FallbackFetcher uses Primary, and switches to the fetcher made by
+// NewFallback when Primary fails with an error matching ShouldFallback.
+// The fallback is created lazily, so e.g. Chromium only starts when needed,
+// and is used for every later request once created.
+type FallbackFetcher struct {
+  Primary        Fetcher
+  NewFallback    func() (Fetcher, error)
+  ShouldFallback func(err error) bool
+
+  mu       sync.Mutex
+  fallback Fetcher
+}
+
+func (f *FallbackFetcher) FetchHTML(url string) (string, error) {
+  if fallback := f.current(); fallback != nil { return fallback.FetchHTML(url) }
+
+  doc, err := f.Primary.FetchHTML(url)
+  if err == nil || !f.ShouldFallback(err) { return doc, err }
+
+  fallback, err := f.switchToFallback(url)
+  if err != nil { return "", err }
+
+  return fallback.FetchHTML(url)
+}
+
+func (f *FallbackFetcher) SubmitForm(url string, formSelector string, values map[string]string) error {
+  if fallback := f.current(); fallback != nil { return submitForm(fallback, url, formSelector, values) }
+
+  err := submitForm(f.Primary, url, formSelector, values)
+  if err == nil || !f.ShouldFallback(err) { return err }
+
+  fallback, err := f.switchToFallback(url)
+  if err != nil { return err }
+
+  return submitForm(fallback, url, formSelector, values)
+}
+
+// Download always prefers the primary fetcher since a browser is no better
+// at plain file downloads.
+func (f *FallbackFetcher) Download(url string) ([]byte, error) {
+  for _, fetcher := range []Fetcher{f.Primary, f.current()} {
+    if downloader, ok := fetcher.(Downloader); ok { return downloader.Download(url) }
+  }
+
+  return nil, fmt.Errorf("%w: no fetcher can download %s", ErrNotSupported, url)
+}
+
+func (f *FallbackFetcher) current() Fetcher {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+
+  return f.fallback
+}
+
+func (f *FallbackFetcher) switchToFallback(url string) (Fetcher, error) {
+  f.mu.Lock()
+  defer f.mu.Unlock()
+
+  if f.fallback != nil { return f.fallback, nil }
+
+  fallback, err := f.NewFallback()
+  if err != nil { return nil, err }
+
+  source, isSource := f.Primary.(CookieSource)
+  sink, isSink := fallback.(CookieSink)
+  if isSource && isSink {
+    if err := sink.SetCookies(url, source.Cookies(url)); err != nil { return nil, err }
+  }
+
+  f.fallback = fallback
+
+  return fallback, nil
+}
+
+func submitForm(fetcher Fetcher, url string, formSelector string, values map[string]string) error {
+  submitter, ok := fetcher.(FormSubmitter)
+  if !ok { return fmt.Errorf("%w: fetcher %T cannot submit forms", ErrNotSupported, fetcher) }
+
+  return submitter.SubmitForm(url, formSelector, values)
+}
+
@@ -0,0 +1,78 @@
+package sites_test
+
+import (
+  "errors"
+  "net/http"
+  "testing"
+
+  "dunn-finance/pkg/browser/sites"
+)
+
+var errNeedsBrowser = errors.New("needs browser")
+
+type stubFetcher struct {
+  doc     string
+  err     error
+  calls   int
+  cookies []*http.Cookie
+}
+
+func (f *stubFetcher) FetchHTML(url string) (string, error) {
+  f.calls++
+  return f.doc, f.err
+}
+
+func (f *stubFetcher) Cookies(url string) []*http.Cookie { return f.cookies }
+
+func (f *stubFetcher) SetCookies(url string, cookies []*http.Cookie) error {
+  f.cookies = cookies
+  return nil
+}
+
+func newFallbackFetcher(primary *stubFetcher, fallback *stubFetcher, created *int) *sites.FallbackFetcher {
+  return &sites.FallbackFetcher{
+    Primary: primary,
+    NewFallback: func() (sites.Fetcher, error) {
+      *created++
+      return fallback, nil
+    },
+    ShouldFallback: func(err error) bool { return errors.Is(err, errNeedsBrowser) },
+  }
+}
+
+func TestFallbackFetcher_FetchHTML_Primary(t *testing.T) {
+  created := 0
+  primary := &stubFetcher{doc: "primary"}
+  fetcher := newFallbackFetcher(primary, &stubFetcher{doc: "fallback"}, &created)
+
+  doc, err := fetcher.FetchHTML("https://example.com")
+  if err != nil { t.Fatal(err) }
+  if doc != "primary" { t.Errorf("got %s, want primary", doc) }
+  if created != 0 { t.Errorf("Expected fallback not to be created, but created %d times", created) }
+}
+
+func TestFallbackFetcher_FetchHTML_Fallback(t *testing.T) {
+  created := 0
+  primary := &stubFetcher{err: errNeedsBrowser, cookies: []*http.Cookie{{Name: "session", Value: "abc"}}}
+  fallback := &stubFetcher{doc: "fallback"}
+  fetcher := newFallbackFetcher(primary, fallback, &created)
+
+  for i := 0; i < 2; i++ {
+    doc, err := fetcher.FetchHTML("https://example.com")
+    if err != nil { t.Fatal(err) }
+    if doc != "fallback" { t.Errorf("got %s, want fallback", doc) }
+  }
+  if created != 1 { t.Errorf("Expected fallback to be created once, but created %d times", created) }
+  if primary.calls != 1 { t.Errorf("Expected primary to be called once, but called %d times", primary.calls) }
+  if len(fallback.cookies) != 1 || fallback.cookies[0].Value != "abc" { t.Errorf("Expected session cookie to be handed over, got %v", fallback.cookies) }
+}
+
+func TestFallbackFetcher_FetchHTML_OtherError(t *testing.T) {
+  created := 0
+  primary := &stubFetcher{err: errors.New("connection refused")}
+  fetcher := newFallbackFetcher(primary, &stubFetcher{}, &created)
+
+  _, err := fetcher.FetchHTML("https://example.com")
+  if err == nil { t.Errorf("Expected error, got nil") }
+  if created != 0 { t.Errorf("Expected fallback not to be created, but created %d times", created) }
+}
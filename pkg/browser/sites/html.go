@@ -0,0 +1,175 @@
+package sites
+
+import (
+  "fmt"
+  "strconv"
+  "strings"
+  "time"
+
+  "golang.org/x/net/html"
+
+  "dunn-finance/pkg/model"
+)
+
+// Table is a parsed HTML table. Rows do not include the header row.
+type Table struct {
+  Header []string
+  Rows   [][]string
+}
+
+// Index returns the column index of the header, or -1.
+func (t *Table) Index(header string) int {
+  for i, h := range t.Header {
+    if h == header { return i }
+  }
+
+  return -1
+}
+
+// FindTable returns the first table in the document whose header contains
+// all of the given headers.
+func FindTable(doc string, headers ...string) (*Table, error) {
+  tables, err := FindTables(doc, headers...)
+  if err != nil { return nil, err }
+
+  return tables[0], nil
+}
+
+// FindTables returns every table in the document whose header contains all
+// of the given headers, in document order.
+func FindTables(doc string, headers ...string) ([]*Table, error) {
+  root, err := html.Parse(strings.NewReader(doc))
+  if err != nil { return nil, err }
+
+  var found []*Table
+  var walk func(n *html.Node)
+  walk = func(n *html.Node) {
+    if n.Type == html.ElementNode && n.Data == "table" {
+      table := parseTable(n)
+      if table.hasHeaders(headers) { found = append(found, table) }
+    }
+    for c := n.FirstChild; c != nil; c = c.NextSibling {
+      walk(c)
+    }
+  }
+  walk(root)
+
+  if len(found) == 0 { return nil, fmt.Errorf("table with headers %v not found", headers) }
+
+  return found, nil
+}
+
+func (t *Table) hasHeaders(headers []string) bool {
+  for _, h := range headers {
+    if t.Index(h) < 0 { return false }
+  }
+
+  return true
+}
+
+func parseTable(n *html.Node) *Table {
+  table := &Table{}
+
+  var walk func(n *html.Node)
+  walk = func(n *html.Node) {
+    // Nested tables are parsed separately.
+    if n.Type == html.ElementNode && n.Data == "table" { return }
+    if n.Type == html.ElementNode && n.Data == "tr" {
+      var cells []string
+      isHeader := true
+      for c := n.FirstChild; c != nil; c = c.NextSibling {
+        if c.Type != html.ElementNode { continue }
+        if c.Data != "th" && c.Data != "td" { continue }
+        if c.Data == "td" { isHeader = false }
+        cells = append(cells, TextContent(c))
+      }
+      if len(cells) == 0 { return }
+      if table.Header == nil && isHeader {
+        table.Header = cells
+      } else {
+        table.Rows = append(table.Rows, cells)
+      }
+      return
+    }
+    for c := n.FirstChild; c != nil; c = c.NextSibling {
+      walk(c)
+    }
+  }
+  for c := n.FirstChild; c != nil; c = c.NextSibling {
+    walk(c)
+  }
+
+  return table
+}
+
+// TextContent returns the trimmed text of the node and its descendants.
+func TextContent(n *html.Node) string {
+  var sb strings.Builder
+  var walk func(n *html.Node)
+  walk = func(n *html.Node) {
+    if n.Type == html.TextNode { sb.WriteString(n.Data) }
+    for c := n.FirstChild; c != nil; c = c.NextSibling {
+      walk(c)
+    }
+  }
+  walk(n)
+
+  return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// ParseFloat parses numbers such as "2,189", "1.23%" or "15.2倍".
+// "--", "-" and empty cells mean no value and return nil.
+func ParseFloat(s string) (*float64, error) {
+  s = strings.TrimSpace(s)
+  for _, unit := range []string{"%", "％", "倍"} {
+    s = strings.TrimSpace(strings.TrimSuffix(s, unit))
+  }
+  if s == "" || s == "-" || s == "--" || s == "－" { return nil, nil }
+
+  s = strings.ReplaceAll(s, ",", "")
+  f, err := strconv.ParseFloat(s, 64)
+  if err != nil { return nil, fmt.Errorf("failed to parse float %q: %w", s, err) }
+
+  return &f, nil
+}
+
+// ParseDate parses a date in one of the layouts, falling back to the formats
+// model.ParseDate knows.
+func ParseDate(s string, layouts ...string) (model.Date, error) {
+  s = strings.TrimSpace(s)
+  for _, layout := range layouts {
+    t, err := time.Parse(layout, s)
+    if err == nil { return model.DateOf(t), nil }
+  }
+  if d, err := model.ParseDate(s); err == nil { return d, nil }
+
+  return model.Date{}, fmt.Errorf("failed to parse date %q with layouts %v", s, layouts)
+}
+
+// KeyValues collects label/value pairs laid out as <th>label</th><td>value</td>
+// inside table rows, or as <dt>label</dt><dd>value</dd>.
+func KeyValues(doc string) (map[string]string, error) {
+  root, err := html.Parse(strings.NewReader(doc))
+  if err != nil { return nil, err }
+
+  result := make(map[string]string)
+  var walk func(n *html.Node)
+  walk = func(n *html.Node) {
+    if n.Type == html.ElementNode && (n.Data == "th" || n.Data == "dt") {
+      value := n.NextSibling
+      for value != nil && value.Type != html.ElementNode {
+        value = value.NextSibling
+      }
+      if value != nil && (value.Data == "td" || value.Data == "dd") {
+        key := TextContent(n)
+        if _, exists := result[key]; !exists { result[key] = TextContent(value) }
+      }
+    }
+    for c := n.FirstChild; c != nil; c = c.NextSibling {
+      walk(c)
+    }
+  }
+  walk(root)
+
+  return result, nil
+}
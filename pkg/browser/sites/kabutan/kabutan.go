@@ -0,0 +1,103 @@
+package kabutan
+
+import (
+  "fmt"
+  "strings"
+
+  "dunn-finance/pkg/browser/sites"
+  "dunn-finance/pkg/model"
+)
+
+const (
+  dailyOHLCVURL   = "https://kabutan.jp/stock/kabuka?code=%s&ashi=day"
+  fundamentalsURL = "https://kabutan.jp/stock/?code=%s"
+)
+
+var ohlcvColumns = sites.OHLCVColumns{
+  Date:   "日付",
+  Open:   "始値",
+  High:   "高値",
+  Low:    "安値",
+  Close:  "終値",
+  Volume: "売買高(株)",
+}
+
+func init() {
+  sites.Register("kabutan", func(fetcher sites.Fetcher) sites.SiteAdapter {
+    return &Adapter{Fetcher: fetcher}
+  })
+}
+
+type Adapter struct {
+  Fetcher sites.Fetcher
+}
+
+func (a *Adapter) FetchDailyOHLCV(code string) ([]*model.AdjustedDailyOHLCV, error) {
+  doc, err := a.Fetcher.FetchHTML(fmt.Sprintf(dailyOHLCVURL, code))
+  if err != nil { return nil, err }
+
+  // Kabutan shows dates as 25/07/18.
+  return sites.FindOHLCVs(doc, code, ohlcvColumns, "06/01/02")
+}
+
+func (a *Adapter) FetchStockList() ([]*model.Stock, error) {
+  return nil, sites.ErrNotSupported
+}
+
+func (a *Adapter) FetchFundamentals(code string) (*model.Fundamentals, error) {
+  doc, err := a.Fetcher.FetchHTML(fmt.Sprintf(fundamentalsURL, code))
+  if err != nil { return nil, err }
+
+  f := &model.Fundamentals{Code: code}
+
+  // PER/PBR/利回り are a header row followed by a value row.
+  table, err := sites.FindTable(doc, "PER", "PBR", "利回り")
+  if err != nil { return nil, err }
+  if len(table.Rows) == 0 { return nil, fmt.Errorf("no fundamentals row for %s", code) }
+
+  row := table.Rows[0]
+  targets := map[string]**float64{
+    "PER":    &f.PER,
+    "PBR":    &f.PBR,
+    "利回り": &f.DividendYield,
+  }
+  for header, target := range targets {
+    idx := table.Index(header)
+    if idx >= len(row) { continue }
+    v, err := sites.ParseFloat(row[idx])
+    if err != nil { return nil, err }
+    *target = v
+  }
+
+  kv, err := sites.KeyValues(doc)
+  if err != nil { return nil, err }
+  if text, ok := kv["時価総額"]; ok {
+    v, err := parseMarketCap(text)
+    if err != nil { return nil, err }
+    f.MarketCap = v
+  }
+
+  return f, nil
+}
+
+// parseMarketCap parses values such as "1兆2,345億円" or "675億円" into yen.
+func parseMarketCap(s string) (*float64, error) {
+  s = strings.TrimSuffix(strings.TrimSpace(s), "円")
+
+  var total float64
+  if i := strings.Index(s, "兆"); i >= 0 {
+    v, err := sites.ParseFloat(s[:i])
+    if err != nil { return nil, err }
+    if v != nil { total += *v * 1e12 }
+    s = s[i+len("兆"):]
+  }
+  if i := strings.Index(s, "億"); i >= 0 {
+    v, err := sites.ParseFloat(s[:i])
+    if err != nil { return nil, err }
+    if v != nil { total += *v * 1e8 }
+    s = s[i+len("億"):]
+  }
+  if s != "" { return nil, fmt.Errorf("failed to parse market cap remainder %q", s) }
+
+  return &total, nil
+}
@@ -0,0 +1,57 @@
+package kabutan_test
+
+import (
+  "errors"
+  "os"
+  "testing"
+
+  "dunn-finance/pkg/browser/sites"
+  _ "dunn-finance/pkg/browser/sites/kabutan"
+)
+
+type fileFetcher struct {
+  path string
+}
+
+func (f *fileFetcher) FetchHTML(url string) (string, error) {
+  b, err := os.ReadFile(f.path)
+  return string(b), err
+}
+
+func newAdapter(t *testing.T, path string) sites.SiteAdapter {
+  adapter, err := sites.New("kabutan", &fileFetcher{path: path})
+  if err != nil { t.Fatal(err) }
+
+  return adapter
+}
+
+func TestAdapter_FetchDailyOHLCV_Success(t *testing.T) {
+  adapter := newAdapter(t, "testdata/daily_ohlcv_5253.html")
+
+  records, err := adapter.FetchDailyOHLCV("5253")
+  if err != nil { t.Fatal(err) }
+  if len(records) != 2 { t.Fatalf("Expected record length: 2, but is %d", len(records)) }
+
+  if records[0].Yyyymmdd.String() != "20250718" { t.Errorf("Expected: 20250718, but got: %s", records[0].Yyyymmdd) }
+  if records[1].Yyyymmdd.String() != "20250717" { t.Errorf("Expected: 20250717, but got: %s", records[1].Yyyymmdd) }
+  if *records[1].ClosePrice != 2158 { t.Errorf("Expected: 2158, but got: %f", *records[1].ClosePrice) }
+  if *records[1].Volume != 1492200 { t.Errorf("Expected: 1492200, but got: %f", *records[1].Volume) }
+}
+
+func TestAdapter_FetchStockList_NotSupported(t *testing.T) {
+  adapter := newAdapter(t, "testdata/daily_ohlcv_5253.html")
+
+  _, err := adapter.FetchStockList()
+  if !errors.Is(err, sites.ErrNotSupported) { t.Errorf("Expected ErrNotSupported, got %v", err) }
+}
+
+func TestAdapter_FetchFundamentals_Success(t *testing.T) {
+  adapter := newAdapter(t, "testdata/fundamentals_5253.html")
+
+  f, err := adapter.FetchFundamentals("5253")
+  if err != nil { t.Fatal(err) }
+  if *f.PER != 28.5 { t.Errorf("Expected: 28.5, but got: %f", *f.PER) }
+  if *f.PBR != 7.12 { t.Errorf("Expected: 7.12, but got: %f", *f.PBR) }
+  if f.DividendYield != nil { t.Errorf("Expected: nil, but got: %f", *f.DividendYield) }
+  if *f.MarketCap != 1432e8 { t.Errorf("Expected: 1432e8, but got: %f", *f.MarketCap) }
+}
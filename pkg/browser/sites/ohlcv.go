@@ -0,0 +1,67 @@
+package sites
+
+import (
+  "fmt"
+
+  "dunn-finance/pkg/model"
+)
+
+// OHLCVColumns names the table headers holding each value of a daily bar.
+type OHLCVColumns struct {
+  Date   string
+  Open   string
+  High   string
+  Low    string
+  Close  string
+  Volume string
+}
+
+func (c OHLCVColumns) headers() []string {
+  return []string{c.Date, c.Open, c.High, c.Low, c.Close, c.Volume}
+}
+
+// FindOHLCVs finds the price tables in doc and converts their rows to
+// model.AdjustedDailyOHLCV. Moving averages are left nil since the sites
+// do not publish them.
+func FindOHLCVs(doc string, code string, columns OHLCVColumns, dateLayouts ...string) ([]*model.AdjustedDailyOHLCV, error) {
+  tables, err := FindTables(doc, columns.headers()...)
+  if err != nil { return nil, err }
+
+  var result []*model.AdjustedDailyOHLCV
+  for _, table := range tables {
+    ohlcvs, err := tableToOHLCVs(table, code, columns, dateLayouts)
+    if err != nil { return nil, err }
+    result = append(result, ohlcvs...)
+  }
+
+  return result, nil
+}
+
+func tableToOHLCVs(table *Table, code string, columns OHLCVColumns, dateLayouts []string) ([]*model.AdjustedDailyOHLCV, error) {
+  dateIdx := table.Index(columns.Date)
+  var result []*model.AdjustedDailyOHLCV
+  for i, row := range table.Rows {
+    if len(row) < len(table.Header) { continue }
+
+    yyyymmdd, err := ParseDate(row[dateIdx], dateLayouts...)
+    if err != nil { return nil, fmt.Errorf("row %d: %w", i, err) }
+
+    ohlcv := &model.AdjustedDailyOHLCV{Yyyymmdd: yyyymmdd, Code: code}
+    targets := map[string]**float64{
+      columns.Open:   &ohlcv.OpenPrice,
+      columns.High:   &ohlcv.HighPrice,
+      columns.Low:    &ohlcv.LowPrice,
+      columns.Close:  &ohlcv.ClosePrice,
+      columns.Volume: &ohlcv.Volume,
+    }
+    for header, target := range targets {
+      v, err := ParseFloat(row[table.Index(header)])
+      if err != nil { return nil, fmt.Errorf("row %d, column %s: %w", i, header, err) }
+      *target = v
+    }
+
+    result = append(result, ohlcv)
+  }
+
+  return result, nil
+}
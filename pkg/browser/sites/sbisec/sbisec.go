@@ -1,10 +1,113 @@
 package sbisec
 
 import (
+  "fmt"
   "log"
+  "strings"
+
   "github.com/go-rod/rod"
+
+  "dunn-finance/pkg/browser/sites"
+  "dunn-finance/pkg/credentials"
+  "dunn-finance/pkg/model"
+)
+
+const (
+  loginURL        = "https://site1.sbisec.co.jp/ETGate/"
+  loginForm       = "form[name=form_login]"
+  dailyOHLCVURL   = "https://site1.sbisec.co.jp/ETGate/?_ControlID=WPLETsiR001Control&_PageID=WPLETsiR001Idtl10&_ActionID=stockDetail&s_rkbn=2&i_stock_sec=%s&i_dom_flg=1&i_exchange_code=JPN&i_output_type=4"
+  stockListURL    = "https://site1.sbisec.co.jp/ETGate/?_ControlID=WPLETmgR001Control&_PageID=WPLETmgR001Mdtl20&_ActionID=DefaultAID"
+  fundamentalsURL = "https://site1.sbisec.co.jp/ETGate/?_ControlID=WPLETsiR001Control&_PageID=WPLETsiR001Idtl10&_ActionID=stockDetail&s_rkbn=2&i_stock_sec=%s&i_dom_flg=1&i_exchange_code=JPN&i_output_type=1"
 )
 
+var ohlcvColumns = sites.OHLCVColumns{
+  Date:   "日付",
+  Open:   "始値",
+  High:   "高値",
+  Low:    "安値",
+  Close:  "終値",
+  Volume: "出来高",
+}
+
+func init() {
+  sites.Register("sbisec", func(fetcher sites.Fetcher) sites.SiteAdapter {
+    return &Adapter{Fetcher: fetcher}
+  })
+}
+
+type Adapter struct {
+  Fetcher sites.Fetcher
+}
+
+func (a *Adapter) Login(cred *credentials.Credential) error {
+  submitter, ok := a.Fetcher.(sites.FormSubmitter)
+  if !ok { return fmt.Errorf("%w: fetcher %T cannot submit forms", sites.ErrNotSupported, a.Fetcher) }
+
+  return submitter.SubmitForm(loginURL, loginForm, map[string]string{
+    "user_id":       cred.UserID,
+    "user_password": cred.Password,
+  })
+}
+
+func (a *Adapter) FetchDailyOHLCV(code string) ([]*model.AdjustedDailyOHLCV, error) {
+  doc, err := a.Fetcher.FetchHTML(fmt.Sprintf(dailyOHLCVURL, code))
+  if err != nil { return nil, err }
+
+  return sites.FindOHLCVs(doc, code, ohlcvColumns, "2006/01/02")
+}
+
+func (a *Adapter) FetchStockList() ([]*model.Stock, error) {
+  doc, err := a.Fetcher.FetchHTML(stockListURL)
+  if err != nil { return nil, err }
+
+  table, err := sites.FindTable(doc, "コード", "銘柄名")
+  if err != nil { return nil, err }
+
+  codeIdx, nameIdx := table.Index("コード"), table.Index("銘柄名")
+  var stocks []*model.Stock
+  for _, row := range table.Rows {
+    if len(row) < len(table.Header) { continue }
+    stocks = append(stocks, &model.Stock{Code: row[codeIdx], Name: row[nameIdx]})
+  }
+
+  return stocks, nil
+}
+
+func (a *Adapter) FetchFundamentals(code string) (*model.Fundamentals, error) {
+  doc, err := a.Fetcher.FetchHTML(fmt.Sprintf(fundamentalsURL, code))
+  if err != nil { return nil, err }
+
+  kv, err := sites.KeyValues(doc)
+  if err != nil { return nil, err }
+
+  f := &model.Fundamentals{Code: code}
+  targets := map[string]**float64{
+    "PER":        &f.PER,
+    "PBR":        &f.PBR,
+    "配当利回り": &f.DividendYield,
+    "時価総額":   &f.MarketCap,
+  }
+  for label, target := range targets {
+    text, ok := kv[label]
+    if !ok { continue }
+
+    if label == "時価総額" {
+      // SBI shows the market cap in millions of yen.
+      v, err := sites.ParseFloat(strings.TrimSuffix(text, "百万円"))
+      if err != nil { return nil, err }
+      if v != nil { *v *= 1_000_000 }
+      *target = v
+      continue
+    }
+
+    v, err := sites.ParseFloat(text)
+    if err != nil { return nil, err }
+    *target = v
+  }
+
+  return f, nil
+}
+
 func PrintPageTitle(browser *rod.Browser) {
   page := browser.MustPage("https://www.sbisec.co.jp").MustWaitLoad()
   log.Printf("[DEBUG] SBI 証券のページタイトル: %s", page.MustInfo().Title)
@@ -0,0 +1,108 @@
+package sbisec_test
+
+import (
+  "errors"
+  "os"
+  "testing"
+
+  "dunn-finance/pkg/browser/sites"
+  _ "dunn-finance/pkg/browser/sites/sbisec"
+  "dunn-finance/pkg/credentials"
+)
+
+type fileFetcher struct {
+  path string
+}
+
+func (f *fileFetcher) FetchHTML(url string) (string, error) {
+  b, err := os.ReadFile(f.path)
+  return string(b), err
+}
+
+func newAdapter(t *testing.T, path string) sites.SiteAdapter {
+  adapter, err := sites.New("sbisec", &fileFetcher{path: path})
+  if err != nil { t.Fatal(err) }
+
+  return adapter
+}
+
+func TestAdapter_FetchDailyOHLCV_Success(t *testing.T) {
+  adapter := newAdapter(t, "testdata/daily_ohlcv_5253.html")
+
+  records, err := adapter.FetchDailyOHLCV("5253")
+  if err != nil { t.Fatal(err) }
+  if len(records) != 3 { t.Fatalf("Expected record length: 3, but is %d", len(records)) }
+
+  record := records[0]
+  if record.Yyyymmdd.String() != "20250718" { t.Errorf("Expected: 20250718, but got: %s", record.Yyyymmdd) }
+  if record.Code != "5253" { t.Errorf("Expected: 5253, but got: %s", record.Code) }
+  if *record.OpenPrice != 2189 { t.Errorf("Expected: 2189, but got: %f", *record.OpenPrice) }
+  if *record.HighPrice != 2212 { t.Errorf("Expected: 2212, but got: %f", *record.HighPrice) }
+  if *record.LowPrice != 2123 { t.Errorf("Expected: 2123, but got: %f", *record.LowPrice) }
+  if *record.ClosePrice != 2136 { t.Errorf("Expected: 2136, but got: %f", *record.ClosePrice) }
+  if *record.Volume != 1501800 { t.Errorf("Expected: 1501800, but got: %f", *record.Volume) }
+  if record.DMAPrice5 != nil { t.Errorf("Expected: nil, but got: %f", *record.DMAPrice5) }
+
+  if records[2].ClosePrice != nil { t.Errorf("Expected: nil, but got: %f", *records[2].ClosePrice) }
+}
+
+func TestAdapter_FetchStockList_Success(t *testing.T) {
+  adapter := newAdapter(t, "testdata/stock_list.html")
+
+  stocks, err := adapter.FetchStockList()
+  if err != nil { t.Fatal(err) }
+  if len(stocks) != 2 { t.Fatalf("Expected stock length: 2, but is %d", len(stocks)) }
+  if stocks[1].Code != "7203" { t.Errorf("Expected: 7203, but got: %s", stocks[1].Code) }
+  if stocks[1].Name != "トヨタ自動車" { t.Errorf("Expected: トヨタ自動車, but got: %s", stocks[1].Name) }
+}
+
+func TestAdapter_FetchFundamentals_Success(t *testing.T) {
+  adapter := newAdapter(t, "testdata/fundamentals_5253.html")
+
+  f, err := adapter.FetchFundamentals("5253")
+  if err != nil { t.Fatal(err) }
+  if *f.PER != 28.51 { t.Errorf("Expected: 28.51, but got: %f", *f.PER) }
+  if *f.PBR != 7.12 { t.Errorf("Expected: 7.12, but got: %f", *f.PBR) }
+  if f.DividendYield != nil { t.Errorf("Expected: nil, but got: %f", *f.DividendYield) }
+  if *f.MarketCap != 143245e6 { t.Errorf("Expected: 143245e6, but got: %f", *f.MarketCap) }
+}
+
+func TestAdapter_FetchDailyOHLCV_Failure(t *testing.T) {
+  adapter := newAdapter(t, "testdata/stock_list.html")
+
+  _, err := adapter.FetchDailyOHLCV("5253")
+  if err == nil { t.Errorf("Expected error for page without price table, got nil") }
+}
+
+type formRecorder struct {
+  fileFetcher
+  formSelector string
+  values       map[string]string
+}
+
+func (f *formRecorder) SubmitForm(url string, formSelector string, values map[string]string) error {
+  f.formSelector = formSelector
+  f.values = values
+  return nil
+}
+
+func TestAdapter_Login_Success(t *testing.T) {
+  fetcher := &formRecorder{}
+  adapter, err := sites.New("sbisec", fetcher)
+  if err != nil { t.Fatal(err) }
+
+  authenticator, ok := adapter.(sites.Authenticator)
+  if !ok { t.Fatalf("Expected sbisec adapter to implement sites.Authenticator") }
+
+  err = authenticator.Login(&credentials.Credential{UserID: "user", Password: "pw", PIN: "1234"})
+  if err != nil { t.Fatal(err) }
+  if fetcher.values["user_id"] != "user" { t.Errorf("got %s, want user", fetcher.values["user_id"]) }
+  if fetcher.values["user_password"] != "pw" { t.Errorf("got %s, want pw", fetcher.values["user_password"]) }
+}
+
+func TestAdapter_Login_NotSupported(t *testing.T) {
+  adapter := newAdapter(t, "testdata/stock_list.html").(sites.Authenticator)
+
+  err := adapter.Login(&credentials.Credential{UserID: "user", Password: "pw"})
+  if !errors.Is(err, sites.ErrNotSupported) { t.Errorf("Expected ErrNotSupported, got %v", err) }
+}
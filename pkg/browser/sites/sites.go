@@ -0,0 +1,79 @@
+package sites
+
+import (
+  "errors"
+  "fmt"
+  "sort"
+  "sync"
+
+  "dunn-finance/pkg/credentials"
+  "dunn-finance/pkg/model"
+)
+
+var ErrNotSupported = errors.New("operation not supported by site")
+
+// Fetcher returns the HTML of the page at url.
+// browser.PageFetcher implements it with a rod browser.
+type Fetcher interface {
+  FetchHTML(url string) (string, error)
+}
+
+// FormSubmitter is implemented by fetchers that can fill in and submit a form,
+// keeping the resulting session for later fetches.
+type FormSubmitter interface {
+  SubmitForm(url string, formSelector string, values map[string]string) error
+}
+
+// SiteAdapter is implemented by every data source under pkg/browser/sites.
+// Adapters return ErrNotSupported for operations the site does not offer.
+type SiteAdapter interface {
+  FetchDailyOHLCV(code string) ([]*model.AdjustedDailyOHLCV, error)
+  FetchStockList() ([]*model.Stock, error)
+  FetchFundamentals(code string) (*model.Fundamentals, error)
+}
+
+// Authenticator is implemented by adapters for sites that require a login.
+type Authenticator interface {
+  Login(cred *credentials.Credential) error
+}
+
+type Factory func(fetcher Fetcher) SiteAdapter
+
+var (
+  mu        sync.RWMutex
+  factories = make(map[string]Factory)
+)
+
+// Register makes a site adapter available by name.
+// Site packages call it from init(), so importing them with _ is enough.
+func Register(name string, factory Factory) {
+  mu.Lock()
+  defer mu.Unlock()
+
+  if factory == nil { panic("sites: Register factory is nil") }
+  if _, dup := factories[name]; dup { panic("sites: Register called twice for site " + name) }
+  factories[name] = factory
+}
+
+func New(name string, fetcher Fetcher) (SiteAdapter, error) {
+  mu.RLock()
+  factory, ok := factories[name]
+  mu.RUnlock()
+
+  if !ok { return nil, fmt.Errorf("unknown site %q (registered: %v)", name, Names()) }
+
+  return factory(fetcher), nil
+}
+
+func Names() []string {
+  mu.RLock()
+  defer mu.RUnlock()
+
+  names := make([]string, 0, len(factories))
+  for name := range factories {
+    names = append(names, name)
+  }
+  sort.Strings(names)
+
+  return names
+}
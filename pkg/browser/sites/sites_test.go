@@ -0,0 +1,59 @@
+package sites_test
+
+import (
+  "testing"
+
+  "dunn-finance/pkg/browser/sites"
+  "dunn-finance/pkg/model"
+)
+
+type stubAdapter struct{}
+
+func (a *stubAdapter) FetchDailyOHLCV(code string) ([]*model.AdjustedDailyOHLCV, error) { return nil, nil }
+func (a *stubAdapter) FetchStockList() ([]*model.Stock, error) { return nil, sites.ErrNotSupported }
+func (a *stubAdapter) FetchFundamentals(code string) (*model.Fundamentals, error) { return nil, sites.ErrNotSupported }
+
+func TestRegistry_New_Success(t *testing.T) {
+  sites.Register("stub", func(fetcher sites.Fetcher) sites.SiteAdapter { return &stubAdapter{} })
+
+  adapter, err := sites.New("stub", nil)
+  if err != nil { t.Fatal(err) }
+  if _, ok := adapter.(*stubAdapter); !ok { t.Errorf("Expected *stubAdapter, got %T", adapter) }
+
+  found := false
+  for _, name := range sites.Names() {
+    if name == "stub" { found = true }
+  }
+  if !found { t.Errorf("Expected stub in %v", sites.Names()) }
+}
+
+func TestRegistry_New_Failure(t *testing.T) {
+  _, err := sites.New("not-registered", nil)
+  if err == nil { t.Errorf("Expected error for unknown site, got nil") }
+}
+
+func TestParseFloat(t *testing.T) {
+  tests := map[string]struct {
+    input    string
+    expected *float64
+  }{
+    "with separators": {input: "1,501,800", expected: floatToPointer(1501800)},
+    "with percent":    {input: "1.23%", expected: floatToPointer(1.23)},
+    "with times":      {input: "28.5倍", expected: floatToPointer(28.5)},
+    "no value":        {input: "--", expected: nil},
+    "empty":           {input: " ", expected: nil},
+  }
+
+  for name, test := range tests {
+    t.Run(name, func(t *testing.T) {
+      actual, err := sites.ParseFloat(test.input)
+      if err != nil { t.Fatal(err) }
+      if (actual == nil) != (test.expected == nil) { t.Fatalf("got %v, want %v", actual, test.expected) }
+      if actual != nil && *actual != *test.expected { t.Errorf("got %f, want %f", *actual, *test.expected) }
+    })
+  }
+
+  if _, err := sites.ParseFloat("abc"); err == nil { t.Errorf("Expected error for garbled value, got nil") }
+}
+
+func floatToPointer(v float64) *float64 { return &v }
@@ -0,0 +1,121 @@
+// Package calendar tells the days the Tokyo Stock Exchange is open: the
+// weekdays that are neither national holidays of Japan nor in the year-end
+// closure from December 31 to January 3. Holidays follow the rules in force
+// since 2007, with the changes of 2019 to 2021; earlier years may be off.
+package calendar
+
+import (
+  "time"
+
+  "dunn-finance/pkg/model"
+)
+
+// IsTradingDay reports whether the exchange is open on d.
+func IsTradingDay(d model.Date) bool {
+  switch d.Time().Weekday() {
+    case time.Saturday, time.Sunday:
+      return false
+  }
+  if _, ok := Holiday(d); ok { return false }
+
+  t := d.Time()
+  if t.Month() == time.December && t.Day() == 31 { return false }
+  if t.Month() == time.January && t.Day() <= 3 { return false }
+
+  return true
+}
+
+// NextTradingDay returns the first trading day after d.
+func NextTradingDay(d model.Date) model.Date {
+  for d = d.AddDays(1); !IsTradingDay(d); d = d.AddDays(1) {
+  }
+  return d
+}
+
+// Holiday returns the name of the national holiday on d, if any.
+func Holiday(d model.Date) (string, bool) {
+  if name, ok := holiday(d); ok { return name, true }
+
+  // A holiday on Sunday moves to the next day that is not a holiday.
+  for prev := d.AddDays(-1); ; prev = prev.AddDays(-1) {
+    if _, ok := holiday(prev); !ok { break }
+    if prev.Time().Weekday() == time.Sunday { return "Substitute Holiday", true }
+  }
+
+  // A day between two holidays is a holiday too.
+  if d.Time().Weekday() != time.Sunday {
+    _, before := holiday(d.AddDays(-1))
+    _, after := holiday(d.AddDays(1))
+    if before && after { return "Citizens' Holiday", true }
+  }
+
+  return "", false
+}
+
+// holiday returns the holidays fixed by the law, before substitutes.
+func holiday(d model.Date) (string, bool) {
+  t := d.Time()
+  year, month, day := t.Date()
+  if name, ok := specialHolidays[d]; ok { return name, true }
+
+  switch month {
+    case time.January:
+      if day == 1 { return "New Year's Day", true }
+      if isNthMonday(t, 2) { return "Coming of Age Day", true }
+    case time.February:
+      if day == 11 { return "National Foundation Day", true }
+      if day == 23 && year >= 2020 { return "Emperor's Birthday", true }
+    case time.March:
+      if day == equinox(year, 20.8431) { return "Vernal Equinox Day", true }
+    case time.April:
+      if day == 29 { return "Showa Day", true }
+    case time.May:
+      switch day {
+        case 3:
+          return "Constitution Memorial Day", true
+        case 4:
+          return "Greenery Day", true
+        case 5:
+          return "Children's Day", true
+      }
+    case time.July:
+      if isNthMonday(t, 3) && year != 2020 && year != 2021 { return "Marine Day", true }
+    case time.August:
+      if day == 11 && year >= 2016 && year != 2020 && year != 2021 { return "Mountain Day", true }
+    case time.September:
+      if isNthMonday(t, 3) { return "Respect for the Aged Day", true }
+      if day == equinox(year, 23.2488) { return "Autumnal Equinox Day", true }
+    case time.October:
+      if isNthMonday(t, 2) && year != 2020 && year != 2021 { return "Sports Day", true }
+    case time.November:
+      if day == 3 { return "Culture Day", true }
+      if day == 23 { return "Labor Thanksgiving Day", true }
+    case time.December:
+      if day == 23 && year >= 1989 && year <= 2018 { return "Emperor's Birthday", true }
+  }
+
+  return "", false
+}
+
+// specialHolidays are those of the enthronement in 2019 and those moved for
+// the Olympics in 2020 and 2021.
+var specialHolidays = map[model.Date]string{
+  model.NewDate(2019, time.May, 1):      "Enthronement Day",
+  model.NewDate(2019, time.October, 22): "Enthronement Ceremony Day",
+  model.NewDate(2020, time.July, 23):    "Marine Day",
+  model.NewDate(2020, time.July, 24):    "Sports Day",
+  model.NewDate(2020, time.August, 10):  "Mountain Day",
+  model.NewDate(2021, time.July, 22):    "Marine Day",
+  model.NewDate(2021, time.July, 23):    "Sports Day",
+  model.NewDate(2021, time.August, 8):   "Mountain Day",
+}
+
+func isNthMonday(t time.Time, n int) bool {
+  return t.Weekday() == time.Monday && (t.Day()-1)/7 == n-1
+}
+
+// equinox approximates the day of the equinox in March or September, with
+// the base of the month, for 1980 to 2099.
+func equinox(year int, base float64) int {
+  return int(base + 0.242194*float64(year-1980)) - (year-1980)/4
+}
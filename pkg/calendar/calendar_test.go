@@ -0,0 +1,58 @@
+package calendar_test
+
+import (
+  "testing"
+
+  "dunn-finance/pkg/calendar"
+  "dunn-finance/pkg/model"
+)
+
+func TestHoliday(t *testing.T) {
+  tests := []struct {
+    date string
+    name string
+  }{
+    {"20250101", "New Year's Day"},
+    {"20250113", "Coming of Age Day"},
+    {"20250224", "Substitute Holiday"},
+    {"20250320", "Vernal Equinox Day"},
+    {"20250506", "Substitute Holiday"},
+    {"20250721", "Marine Day"},
+    {"20250915", "Respect for the Aged Day"},
+    {"20250923", "Autumnal Equinox Day"},
+    {"20251013", "Sports Day"},
+    {"20251124", "Substitute Holiday"},
+    {"20260922", "Citizens' Holiday"},
+    {"20190430", "Citizens' Holiday"},
+    {"20190501", "Enthronement Day"},
+    {"20210809", "Substitute Holiday"},
+    {"20181224", "Substitute Holiday"},
+    {"20250718", ""},
+    {"20250811", "Mountain Day"},
+    {"20241223", ""},
+  }
+  for _, tt := range tests {
+    name, ok := calendar.Holiday(model.MustParseDate(tt.date))
+    if name != tt.name || ok != (tt.name != "") { t.Errorf("Holiday(%s) = %q, %t, want %q", tt.date, name, ok, tt.name) }
+  }
+}
+
+func TestIsTradingDay(t *testing.T) {
+  tests := []struct {
+    date string
+    want bool
+  }{
+    {"20250718", true},
+    {"20250719", false},
+    {"20250721", false},
+    {"20251231", false},
+    {"20260102", false},
+    {"20260105", true},
+    {"20251230", true},
+  }
+  for _, tt := range tests {
+    if got := calendar.IsTradingDay(model.MustParseDate(tt.date)); got != tt.want { t.Errorf("IsTradingDay(%s) = %t", tt.date, got) }
+  }
+
+  if got := calendar.NextTradingDay(model.MustParseDate("20251230")); got != model.MustParseDate("20260105") { t.Errorf("NextTradingDay = %s", got) }
+}
@@ -0,0 +1,87 @@
+package chart
+
+import (
+  "math"
+  "strconv"
+
+  "dunn-finance/pkg/model"
+)
+
+// niceTicks returns round values between lo and hi, about one per minGap
+// pixels of the given length.
+func niceTicks(lo float64, hi float64, length float64, minGap float64) (ticks []float64, step float64) {
+  count := max(math.Floor(length/minGap), 1)
+  raw := (hi - lo) / count
+  if raw <= 0 { return nil, 0 }
+
+  magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+  for _, m := range []float64{1, 2, 5, 10} {
+    if step = m * magnitude; step >= raw { break }
+  }
+  // Multiplying rather than adding steps keeps 0.1 + 0.2 from drifting, and
+  // adding 0 turns -0 into 0.
+  for k := math.Ceil(lo / step); k*step <= hi; k++ {
+    ticks = append(ticks, k*step+0)
+  }
+  return ticks, step
+}
+
+// formatTick prints v with as many decimals as the step needs.
+func formatTick(v float64, step float64) string {
+  decimals := max(0, int(-math.Floor(math.Log10(step))))
+  return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+// formatCompact prints large values such as volumes as 1.5M or 200K, with
+// the unit chosen by the largest value of the axis.
+func formatCompact(v float64, step float64, largest float64) string {
+  switch {
+    case v == 0:
+      return "0"
+    case largest >= 1e6:
+      return formatTick(v/1e6, step/1e6) + "M"
+    case largest >= 1e3:
+      return formatTick(v/1e3, step/1e3) + "K"
+    default:
+      return formatTick(v, step)
+  }
+}
+
+type dateTick struct {
+  index int
+  label string
+}
+
+// dateTicks labels the bars that start a month, every so many months to
+// keep the labels minGap pixels apart. Bars are placed by index, so days
+// without trading take no space. A range within a month or two is labelled
+// every few bars instead.
+func dateTicks(dates []model.Date, slot float64, minGap float64) []dateTick {
+  var starts []int
+  for i := 1; i < len(dates); i++ {
+    if dates[i].Time().Month() != dates[i-1].Time().Month() { starts = append(starts, i) }
+  }
+
+  if len(starts) >= 2 {
+    for _, step := range []int{1, 2, 3, 6, 12, 24, 60} {
+      var ticks []dateTick
+      spaced := true
+      for _, i := range starts {
+        t := dates[i].Time()
+        if (t.Year()*12+int(t.Month())-1)%step != 0 { continue }
+        if len(ticks) > 0 && float64(i-ticks[len(ticks)-1].index)*slot < minGap { spaced = false }
+        label := t.Format("2006/01")
+        if step >= 12 { label = t.Format("2006") }
+        ticks = append(ticks, dateTick{index: i, label: label})
+      }
+      if spaced { return ticks }
+    }
+  }
+
+  every := max(int(math.Ceil(minGap/slot)), 1)
+  var ticks []dateTick
+  for i := 0; i < len(dates); i += every {
+    ticks = append(ticks, dateTick{index: i, label: dates[i].Time().Format("01/02")})
+  }
+  return ticks
+}
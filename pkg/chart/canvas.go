@@ -0,0 +1,32 @@
+package chart
+
+import (
+  "image/color"
+  "io"
+)
+
+type anchor int
+
+const (
+  anchorStart anchor = iota
+  anchorMiddle
+  anchorEnd
+)
+
+// canvas is what the chart draws on. Coordinates are pixels from the top
+// left, and text is vertically centred on y.
+type canvas interface {
+  fillRect(x, y, w, h float64, c color.RGBA)
+  line(x1, y1, x2, y2 float64, c color.RGBA, width float64)
+  text(x, y float64, s string, c color.RGBA, a anchor)
+  encode(w io.Writer) error
+}
+
+// polyline draws the segments between consecutive points with a value,
+// leaving gaps where the value is missing.
+func polyline(c canvas, xs []float64, ys []*float64, col color.RGBA, width float64) {
+  for i := 1; i < len(xs); i++ {
+    if ys[i-1] == nil || ys[i] == nil { continue }
+    c.line(xs[i-1], *ys[i-1], xs[i], *ys[i], col, width)
+  }
+}
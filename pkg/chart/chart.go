@@ -0,0 +1,353 @@
+// Package chart renders candlestick charts of adjusted daily OHLCVs to SVG or
+// PNG, with a volume panel, the stored moving averages and indicator panels.
+package chart
+
+import (
+  "errors"
+  "fmt"
+  "image/color"
+  "io"
+  "math"
+  "path/filepath"
+  "strings"
+
+  "dunn-finance/pkg/indicator"
+  "dunn-finance/pkg/model"
+)
+
+var ErrNoBars = errors.New("no bars to chart")
+
+type Format string
+
+const (
+  FormatSVG Format = "svg"
+  FormatPNG Format = "png"
+)
+
+func ParseFormat(s string) (Format, error) {
+  switch f := Format(strings.ToLower(s)); f {
+    case FormatSVG, FormatPNG:
+      return f, nil
+    default:
+      return "", fmt.Errorf("unknown format %q: want svg or png", s)
+  }
+}
+
+// FormatOf returns the format of a file by its extension.
+func FormatOf(path string) (Format, error) {
+  return ParseFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// Indicator is a sub-panel computed from the close prices.
+type Indicator string
+
+const (
+  // IndicatorRSI is the 14 day RSI with guides at 30 and 70.
+  IndicatorRSI  Indicator = "rsi"
+  // IndicatorMACD is MACD 12/26 with a 9 day signal and their histogram.
+  IndicatorMACD Indicator = "macd"
+)
+
+// ParseIndicators parses a comma separated list such as "rsi,macd".
+func ParseIndicators(s string) ([]Indicator, error) {
+  var indicators []Indicator
+  for _, name := range strings.Split(s, ",") {
+    switch i := Indicator(strings.ToLower(strings.TrimSpace(name))); i {
+      case "":
+      case IndicatorRSI, IndicatorMACD:
+        indicators = append(indicators, i)
+      default:
+        return nil, fmt.Errorf("unknown indicator %q: want rsi or macd", name)
+    }
+  }
+  return indicators, nil
+}
+
+// Colors are the colors of rising and falling candles and volumes.
+type Colors struct {
+  Up   color.RGBA
+  Down color.RGBA
+}
+
+var (
+  red   = color.RGBA{0xd3, 0x2f, 0x2f, 0xff}
+  green = color.RGBA{0x2e, 0x7d, 0x32, 0xff}
+
+  // JapaneseColors is the convention of Japanese brokers: red up, green down.
+  JapaneseColors = Colors{Up: red, Down: green}
+  WesternColors  = Colors{Up: green, Down: red}
+)
+
+var (
+  background  = color.RGBA{0xff, 0xff, 0xff, 0xff}
+  foreground  = color.RGBA{0x33, 0x33, 0x33, 0xff}
+  gridColor   = color.RGBA{0xe6, 0xe6, 0xe6, 0xff}
+  frameColor  = color.RGBA{0xb0, 0xb0, 0xb0, 0xff}
+  dmaColors   = []color.RGBA{{0xf5, 0x7c, 0x00, 0xff}, {0x7b, 0x1f, 0xa2, 0xff}, {0x19, 0x76, 0xd2, 0xff}}
+  lineColor   = color.RGBA{0x19, 0x76, 0xd2, 0xff}
+  signalColor = color.RGBA{0xf5, 0x7c, 0x00, 0xff}
+)
+
+type Options struct {
+  // Width and Height in pixels, 1200 and 800 when zero.
+  Width      int
+  Height     int
+  // Title is drawn at the top left. PNG only draws ASCII.
+  Title      string
+  // Colors of the candles, JapaneseColors when zero.
+  Colors     Colors
+  Volume     bool
+  // DMA overlays the stored 5, 25 and 75 day moving averages.
+  DMA        bool
+  Indicators []Indicator
+}
+
+const (
+  marginLeft   = 10.0
+  marginRight  = 70.0
+  marginTop    = 30.0
+  marginBottom = 24.0
+  panelGap     = 10.0
+  yTickGap     = 40.0
+  xTickGap     = 80.0
+)
+
+// panel is a horizontal band of the chart with its own value scale.
+type panel struct {
+  top    float64
+  height float64
+  lo     float64
+  hi     float64
+}
+
+func (p *panel) y(v float64) float64 {
+  return p.top + p.height - (v-p.lo)/(p.hi-p.lo)*p.height
+}
+
+// ys maps values to y coordinates, keeping missing values nil.
+func (p *panel) ys(values []*float64) []*float64 {
+  result := make([]*float64, len(values))
+  for i, v := range values {
+    if v != nil {
+      y := p.y(*v)
+      result[i] = &y
+    }
+  }
+  return result
+}
+
+// Render draws the bars, oldest first, in the format.
+func Render(w io.Writer, format Format, bars []*model.AdjustedDailyOHLCV, opts Options) error {
+  if len(bars) == 0 { return ErrNoBars }
+  if opts.Width == 0 { opts.Width = 1200 }
+  if opts.Height == 0 { opts.Height = 800 }
+  if opts.Colors == (Colors{}) { opts.Colors = JapaneseColors }
+
+  var c canvas
+  switch format {
+    case FormatSVG:
+      c = newSVGCanvas(opts.Width, opts.Height)
+    case FormatPNG:
+      c = newPNGCanvas(opts.Width, opts.Height)
+    default:
+      return fmt.Errorf("unknown format %q", format)
+  }
+
+  c.fillRect(0, 0, float64(opts.Width), float64(opts.Height), background)
+  drawChart(c, bars, opts)
+
+  return c.encode(w)
+}
+
+// xAxis places the bars by index, so days without trading take no space.
+type xAxis struct {
+  xs    []float64
+  slot  float64
+  left  float64
+  right float64
+}
+
+func drawChart(c canvas, bars []*model.AdjustedDailyOHLCV, opts Options) {
+  x := &xAxis{left: marginLeft, right: float64(opts.Width) - marginRight}
+  x.slot = (x.right - x.left) / float64(len(bars))
+  x.xs = make([]float64, len(bars))
+  for i := range bars {
+    x.xs[i] = x.left + x.slot*(float64(i)+0.5)
+  }
+
+  // The price panel takes three shares of the height and every other panel one.
+  shares, panels := 3, 1+len(opts.Indicators)
+  if opts.Volume { panels++ }
+  shares += panels - 1
+  unit := (float64(opts.Height) - marginTop - marginBottom - panelGap*float64(panels-1)) / float64(shares)
+  top := marginTop
+  next := func(share int) *panel {
+    p := &panel{top: top, height: unit * float64(share)}
+    top += p.height + panelGap
+    return p
+  }
+
+  price := next(3)
+  var volume *panel
+  if opts.Volume { volume = next(1) }
+  indicatorPanels := make([]*panel, len(opts.Indicators))
+  for i := range opts.Indicators {
+    indicatorPanels[i] = next(1)
+  }
+  bottom := top - panelGap
+
+  if opts.Title != "" { c.text(marginLeft, marginTop/2, opts.Title, foreground, anchorStart) }
+
+  // Date grid and labels run through every panel.
+  dates := make([]model.Date, len(bars))
+  for i, bar := range bars {
+    dates[i] = bar.Yyyymmdd
+  }
+  for _, tick := range dateTicks(dates, x.slot, xTickGap) {
+    tx := x.xs[tick.index] - x.slot/2
+    c.line(tx, marginTop, tx, bottom, gridColor, 1)
+    c.text(tx, bottom+marginBottom/2, tick.label, foreground, anchorMiddle)
+  }
+
+  drawPrice(c, price, x, bars, opts)
+  if volume != nil { drawVolume(c, volume, x, bars, opts) }
+  closes := indicator.Closes(bars)
+  for i, ind := range opts.Indicators {
+    switch ind {
+      case IndicatorRSI:
+        drawRSI(c, indicatorPanels[i], x, closes)
+      case IndicatorMACD:
+        drawMACD(c, indicatorPanels[i], x, closes, opts)
+    }
+  }
+}
+
+// frame draws the border of a panel, its horizontal grid and the value labels
+// on the right.
+func frame(c canvas, p *panel, x *xAxis, format func(v float64, step float64) string) {
+  ticks, step := niceTicks(p.lo, p.hi, p.height, yTickGap)
+  for _, v := range ticks {
+    y := p.y(v)
+    c.line(x.left, y, x.right, y, gridColor, 1)
+    c.text(x.right+6, y, format(v, step), foreground, anchorStart)
+  }
+  c.line(x.left, p.top, x.right, p.top, frameColor, 1)
+  c.line(x.left, p.top+p.height, x.right, p.top+p.height, frameColor, 1)
+  c.line(x.left, p.top, x.left, p.top+p.height, frameColor, 1)
+  c.line(x.right, p.top, x.right, p.top+p.height, frameColor, 1)
+}
+
+// legend labels the series of a panel at its top left.
+func legend(c canvas, p *panel, x *xAxis, labels []string, colors []color.RGBA) {
+  lx := x.left + 6
+  for i, label := range labels {
+    c.text(lx, p.top+10, label, colors[i], anchorStart)
+    lx += float64(len(label))*7 + 12
+  }
+}
+
+// valueRange returns the lowest and highest values, padded by a twentieth of
+// their span so that nothing touches the frame.
+func valueRange(series ...[]*float64) (float64, float64) {
+  lo, hi := math.Inf(1), math.Inf(-1)
+  for _, values := range series {
+    for _, v := range values {
+      if v == nil { continue }
+      lo, hi = min(lo, *v), max(hi, *v)
+    }
+  }
+  if math.IsInf(lo, 1) { return 0, 1 }
+  if lo == hi { return lo - 1, hi + 1 }
+
+  pad := (hi - lo) / 20
+  return lo - pad, hi + pad
+}
+
+func column(bars []*model.AdjustedDailyOHLCV, field func(*model.AdjustedDailyOHLCV) *float64) []*float64 {
+  values := make([]*float64, len(bars))
+  for i, bar := range bars {
+    values[i] = field(bar)
+  }
+  return values
+}
+
+func candleColor(bar *model.AdjustedDailyOHLCV, colors Colors) color.RGBA {
+  if bar.OpenPrice != nil && bar.ClosePrice != nil && *bar.ClosePrice < *bar.OpenPrice { return colors.Down }
+  return colors.Up
+}
+
+func drawPrice(c canvas, p *panel, x *xAxis, bars []*model.AdjustedDailyOHLCV, opts Options) {
+  series := [][]*float64{
+    column(bars, func(b *model.AdjustedDailyOHLCV) *float64 { return b.HighPrice }),
+    column(bars, func(b *model.AdjustedDailyOHLCV) *float64 { return b.LowPrice }),
+  }
+  dmas := [][]*float64{
+    column(bars, func(b *model.AdjustedDailyOHLCV) *float64 { return b.DMAPrice5 }),
+    column(bars, func(b *model.AdjustedDailyOHLCV) *float64 { return b.DMAPrice25 }),
+    column(bars, func(b *model.AdjustedDailyOHLCV) *float64 { return b.DMAPrice75 }),
+  }
+  if opts.DMA { series = append(series, dmas...) }
+  p.lo, p.hi = valueRange(series...)
+  frame(c, p, x, formatTick)
+
+  body := max(x.slot*0.7, 1)
+  for i, bar := range bars {
+    if bar.OpenPrice == nil || bar.ClosePrice == nil { continue }
+    col := candleColor(bar, opts.Colors)
+    if bar.HighPrice != nil && bar.LowPrice != nil { c.line(x.xs[i], p.y(*bar.HighPrice), x.xs[i], p.y(*bar.LowPrice), col, 1) }
+    yOpen, yClose := p.y(*bar.OpenPrice), p.y(*bar.ClosePrice)
+    c.fillRect(x.xs[i]-body/2, min(yOpen, yClose), body, max(math.Abs(yOpen-yClose), 1), col)
+  }
+
+  if !opts.DMA { return }
+  for j := range dmas {
+    polyline(c, x.xs, p.ys(dmas[j]), dmaColors[j], 1.5)
+  }
+  legend(c, p, x, []string{"DMA5", "DMA25", "DMA75"}, dmaColors)
+}
+
+func drawVolume(c canvas, p *panel, x *xAxis, bars []*model.AdjustedDailyOHLCV, opts Options) {
+  _, hi := valueRange(column(bars, func(b *model.AdjustedDailyOHLCV) *float64 { return b.Volume }))
+  p.lo, p.hi = 0, max(hi, 1)
+  frame(c, p, x, func(v float64, step float64) string { return formatCompact(v, step, p.hi) })
+
+  body := max(x.slot*0.7, 1)
+  for i, bar := range bars {
+    if bar.Volume == nil { continue }
+    y := p.y(*bar.Volume)
+    c.fillRect(x.xs[i]-body/2, y, body, p.top+p.height-y, candleColor(bar, opts.Colors))
+  }
+  legend(c, p, x, []string{"Volume"}, []color.RGBA{foreground})
+}
+
+func drawRSI(c canvas, p *panel, x *xAxis, closes []*float64) {
+  p.lo, p.hi = 0, 100
+  frame(c, p, x, formatTick)
+  for _, guide := range []float64{30, 70} {
+    c.line(x.left, p.y(guide), x.right, p.y(guide), frameColor, 1)
+  }
+
+  polyline(c, x.xs, p.ys(indicator.RSI(closes, 14)), lineColor, 1.5)
+  legend(c, p, x, []string{"RSI 14"}, []color.RGBA{lineColor})
+}
+
+func drawMACD(c canvas, p *panel, x *xAxis, closes []*float64, opts Options) {
+  macd := indicator.MACD(closes, 12, 26, 9)
+  p.lo, p.hi = valueRange(macd.MACD, macd.Signal, macd.Histogram)
+  frame(c, p, x, formatTick)
+
+  if p.lo < 0 && p.hi > 0 { c.line(x.left, p.y(0), x.right, p.y(0), frameColor, 1) }
+  base := p.y(max(p.lo, 0))
+  body := max(x.slot*0.7, 1)
+  for i, h := range macd.Histogram {
+    if h == nil { continue }
+    col := opts.Colors.Up
+    if *h < 0 { col = opts.Colors.Down }
+    y := p.y(*h)
+    c.fillRect(x.xs[i]-body/2, min(y, base), body, math.Abs(y-base), col)
+  }
+
+  polyline(c, x.xs, p.ys(macd.MACD), lineColor, 1.5)
+  polyline(c, x.xs, p.ys(macd.Signal), signalColor, 1.5)
+  legend(c, p, x, []string{"MACD 12/26", "Signal 9"}, []color.RGBA{lineColor, signalColor})
+}
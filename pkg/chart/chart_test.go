@@ -0,0 +1,107 @@
+package chart
+
+import (
+  "bytes"
+  "encoding/xml"
+  "errors"
+  "image"
+  "image/color"
+  "image/png"
+  "io"
+  "slices"
+  "strings"
+  "testing"
+
+  "dunn-finance/pkg/csvreader"
+  "dunn-finance/pkg/model"
+)
+
+// loadBars returns the SBI fixture oldest first. Its oldest bars have no
+// moving averages yet.
+func loadBars(t *testing.T) []*model.AdjustedDailyOHLCV {
+  bars, err := csvreader.LoadAdjustedDailyOHLCVsFromCSV("5253", "../csvreader/testdata/sbi_timechart_5253_20250720.csv", csvreader.SBIFieldMap, true, 0, 0)
+  if err != nil { t.Fatal(err) }
+  slices.Reverse(bars)
+  return bars
+}
+
+var fullOptions = Options{Title: "5253 カバー", Volume: true, DMA: true, Indicators: []Indicator{IndicatorRSI, IndicatorMACD}}
+
+func TestRender_SVG(t *testing.T) {
+  var buf bytes.Buffer
+  if err := Render(&buf, FormatSVG, loadBars(t), fullOptions); err != nil { t.Fatal(err) }
+
+  decoder := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+  for {
+    _, err := decoder.Token()
+    if err == io.EOF { break }
+    if err != nil { t.Fatalf("Invalid SVG: %v", err) }
+  }
+
+  svg := buf.String()
+  for _, s := range []string{`width="1200" height="800"`, ">5253 カバー</text>", ">DMA75</text>", ">RSI 14</text>", ">MACD 12/26</text>", ">Volume</text>", ">2025/07</text>", `fill="#d32f2f"`, `fill="#2e7d32"`} {
+    if !strings.Contains(svg, s) { t.Errorf("Expected %s in the SVG", s) }
+  }
+}
+
+func TestRender_PNG(t *testing.T) {
+  opts := fullOptions
+  opts.Width, opts.Height, opts.Title, opts.Colors = 600, 400, "5253", WesternColors
+
+  var buf bytes.Buffer
+  if err := Render(&buf, FormatPNG, loadBars(t), opts); err != nil { t.Fatal(err) }
+
+  img, err := png.Decode(&buf)
+  if err != nil { t.Fatal(err) }
+  if img.Bounds() != image.Rect(0, 0, 600, 400) { t.Errorf("Unexpected size %v", img.Bounds()) }
+  if c := color.RGBAModel.Convert(img.At(0, 0)); c != background { t.Errorf("Expected a white background, got %v", c) }
+
+  found := map[color.RGBA]bool{}
+  for y := 0; y < 400; y++ {
+    for x := 0; x < 600; x++ {
+      found[color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)] = true
+    }
+  }
+  if !found[red] || !found[green] { t.Error("Expected both rising and falling candles") }
+}
+
+func TestRender_NoBars(t *testing.T) {
+  if err := Render(io.Discard, FormatSVG, nil, Options{}); !errors.Is(err, ErrNoBars) { t.Errorf("Expected ErrNoBars, got %v", err) }
+}
+
+func TestDateTicks_SkipNonTradingDays(t *testing.T) {
+  // Fri 2025-06-27 and Mon 2025-06-30 are neighbours, so the month changes
+  // two bars in, not after a weekend gap.
+  var dates []model.Date
+  for _, s := range []string{"20250626", "20250627", "20250630", "20250701", "20250702", "20250801", "20250901"} {
+    dates = append(dates, model.MustParseDate(s))
+  }
+
+  ticks := dateTicks(dates, 100, 80)
+  if len(ticks) != 3 || ticks[0].index != 3 || ticks[0].label != "2025/07" || ticks[2].label != "2025/09" { t.Errorf("Unexpected ticks: %+v", ticks) }
+
+  // Too narrow for every month or every other, so only the quarter.
+  ticks = dateTicks(dates, 10, 80)
+  if len(ticks) != 1 || ticks[0].label != "2025/07" { t.Errorf("Unexpected ticks: %+v", ticks) }
+
+  // Within a month, every few bars.
+  ticks = dateTicks(dates[:2], 50, 80)
+  if len(ticks) != 1 || ticks[0].label != "06/26" { t.Errorf("Unexpected ticks: %+v", ticks) }
+}
+
+func TestNiceTicks(t *testing.T) {
+  ticks, step := niceTicks(1312, 2478, 200, 40)
+  if step != 500 || !slices.Equal(ticks, []float64{1500, 2000}) { t.Errorf("got %v every %g", ticks, step) }
+  if s := formatCompact(2500000, 500000, 3e6); s != "2.5M" { t.Errorf("got %s, want 2.5M", s) }
+  if s := formatCompact(0, 500000, 3e6); s != "0" { t.Errorf("got %s, want 0", s) }
+
+  ticks, step = niceTicks(-0.25, 0.25, 200, 40)
+  if s := formatTick(ticks[2], step); s != "0.0" { t.Errorf("got %s, want 0.0 without a sign", s) }
+}
+
+func TestParseIndicators(t *testing.T) {
+  indicators, err := ParseIndicators("RSI, macd")
+  if err != nil || !slices.Equal(indicators, []Indicator{IndicatorRSI, IndicatorMACD}) { t.Errorf("got %v, %v", indicators, err) }
+  if _, err := ParseIndicators("bollinger"); err == nil { t.Error("Expected an error for an unknown indicator") }
+  if f, err := FormatOf("out/5253.PNG"); err != nil || f != FormatPNG { t.Errorf("got %s, %v", f, err) }
+}
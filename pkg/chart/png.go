@@ -0,0 +1,79 @@
+package chart
+
+import (
+  "image"
+  "image/color"
+  "image/draw"
+  "image/png"
+  "io"
+  "math"
+
+  "golang.org/x/image/font"
+  "golang.org/x/image/font/basicfont"
+  "golang.org/x/image/math/fixed"
+  "golang.org/x/image/vector"
+)
+
+type pngCanvas struct {
+  img        *image.RGBA
+  rasterizer *vector.Rasterizer
+}
+
+func newPNGCanvas(width int, height int) *pngCanvas {
+  return &pngCanvas{img: image.NewRGBA(image.Rect(0, 0, width, height)), rasterizer: vector.NewRasterizer(0, 0)}
+}
+
+// Rectangles are axis aligned, so they are snapped to whole pixels to stay
+// crisp. A rectangle thinner than a pixel still covers one.
+func (p *pngCanvas) fillRect(x, y, w, h float64, c color.RGBA) {
+  x0, y0 := int(math.Round(x)), int(math.Round(y))
+  x1, y1 := max(int(math.Round(x+w)), x0+1), max(int(math.Round(y+h)), y0+1)
+  draw.Draw(p.img, image.Rect(x0, y0, x1, y1), image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+// line rasterizes the stroke as an anti-aliased quadrilateral. The
+// rasterizer only covers the bounding box of the line, as clearing one the
+// size of the image for every line would dominate the rendering.
+func (p *pngCanvas) line(x1, y1, x2, y2 float64, c color.RGBA, width float64) {
+  dx, dy := x2-x1, y2-y1
+  length := math.Hypot(dx, dy)
+  if length == 0 { return }
+  // Offsets perpendicular to the line, half the width on each side.
+  nx, ny := -dy/length*width/2, dx/length*width/2
+
+  minX := int(math.Floor(min(x1, x2) - width))
+  minY := int(math.Floor(min(y1, y2) - width))
+  maxX := int(math.Ceil(max(x1, x2) + width))
+  maxY := int(math.Ceil(max(y1, y2) + width))
+
+  r := p.rasterizer
+  r.Reset(maxX-minX, maxY-minY)
+  ox, oy := float64(minX), float64(minY)
+  r.MoveTo(float32(x1+nx-ox), float32(y1+ny-oy))
+  r.LineTo(float32(x2+nx-ox), float32(y2+ny-oy))
+  r.LineTo(float32(x2-nx-ox), float32(y2-ny-oy))
+  r.LineTo(float32(x1-nx-ox), float32(y1-ny-oy))
+  r.ClosePath()
+  r.Draw(p.img, image.Rect(minX, minY, maxX, maxY), image.NewUniform(c), image.Point{})
+}
+
+// Text is drawn with the built-in 7x13 bitmap font, which only has ASCII.
+func (p *pngCanvas) text(x, y float64, s string, c color.RGBA, a anchor) {
+  face := basicfont.Face7x13
+  d := &font.Drawer{Dst: p.img, Src: image.NewUniform(c), Face: face}
+  w := float64(d.MeasureString(s)) / 64
+  switch a {
+    case anchorMiddle:
+      x -= w / 2
+    case anchorEnd:
+      x -= w
+  }
+  metrics := face.Metrics()
+  baseline := y + float64(metrics.Ascent.Round()-metrics.Descent.Round())/2
+  d.Dot = fixed.P(int(math.Round(x)), int(math.Round(baseline)))
+  d.DrawString(s)
+}
+
+func (p *pngCanvas) encode(w io.Writer) error {
+  return png.Encode(w, p.img)
+}
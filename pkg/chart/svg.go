@@ -0,0 +1,45 @@
+package chart
+
+import (
+  "bytes"
+  "encoding/xml"
+  "fmt"
+  "image/color"
+  "io"
+)
+
+type svgCanvas struct {
+  width  int
+  height int
+  body   bytes.Buffer
+}
+
+func newSVGCanvas(width int, height int) *svgCanvas {
+  return &svgCanvas{width: width, height: height}
+}
+
+func hex(c color.RGBA) string {
+  return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func (s *svgCanvas) fillRect(x, y, w, h float64, c color.RGBA) {
+  fmt.Fprintf(&s.body, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n", x, y, w, h, hex(c))
+}
+
+func (s *svgCanvas) line(x1, y1, x2, y2 float64, c color.RGBA, width float64) {
+  fmt.Fprintf(&s.body, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="%.1f"/>`+"\n", x1, y1, x2, y2, hex(c), width)
+}
+
+func (s *svgCanvas) text(x, y float64, str string, c color.RGBA, a anchor) {
+  anchors := map[anchor]string{anchorStart: "start", anchorMiddle: "middle", anchorEnd: "end"}
+  fmt.Fprintf(&s.body, `<text x="%.1f" y="%.1f" fill="%s" text-anchor="%s" dominant-baseline="middle">`, x, y, hex(c), anchors[a])
+  xml.EscapeText(&s.body, []byte(str))
+  s.body.WriteString("</text>\n")
+}
+
+func (s *svgCanvas) encode(w io.Writer) error {
+  if _, err := fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="11">`+"\n", s.width, s.height, s.width, s.height); err != nil { return err }
+  if _, err := s.body.WriteTo(w); err != nil { return err }
+  _, err := io.WriteString(w, "</svg>\n")
+  return err
+}
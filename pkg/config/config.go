@@ -0,0 +1,179 @@
+// Package config is the configuration shared by the dunn subcommands. It is
+// read from a YAML file, and every setting can be overridden by the env var
+// in its env tag. Flags of the subcommands override both.
+package config
+
+import (
+  "errors"
+  "fmt"
+  "os"
+  "path/filepath"
+  "reflect"
+  "strconv"
+  "strings"
+
+  "gopkg.in/yaml.v3"
+)
+
+// PathEnv points to the config file instead of DefaultPath.
+const PathEnv = "DUNN_CONFIG"
+
+type Config struct {
+  DB      DB      `yaml:"db"`
+  Data    Data    `yaml:"data"`
+  Browser Browser `yaml:"browser"`
+  JQuants JQuants `yaml:"jquants"`
+  Serve   Serve   `yaml:"serve"`
+  Alerts  Alerts  `yaml:"alerts"`
+  Daemon  Daemon  `yaml:"daemon"`
+}
+
+type DB struct {
+  Driver string `yaml:"driver" env:"DUNN_DB_DRIVER"`
+  // Path is the SQLite file, or the DSN of PostgreSQL.
+  Path   string `yaml:"path" env:"DUNN_DB_PATH"`
+}
+
+type Data struct {
+  // CSVDir is where the CSV files to import are downloaded.
+  CSVDir    string `yaml:"csv_dir" env:"DUNN_CSV_DIR"`
+  ExportDir string `yaml:"export_dir" env:"DUNN_EXPORT_DIR"`
+  ReportDir string `yaml:"report_dir" env:"DUNN_REPORT_DIR"`
+}
+
+type Browser struct {
+  // Site is the default site to fetch from, e.g. sbisec or kabutan.
+  Site        string `yaml:"site" env:"DUNN_BROWSER_SITE"`
+  // Fetcher is http, browser, or auto for http with a browser fallback.
+  Fetcher     string `yaml:"fetcher" env:"DUNN_BROWSER_FETCHER"`
+  // Bin is the Chrome or Chromium binary. Empty downloads one.
+  Bin         string `yaml:"bin" env:"DUNN_BROWSER_BIN"`
+  Headless    bool   `yaml:"headless" env:"DUNN_BROWSER_HEADLESS"`
+  UserDataDir string `yaml:"user_data_dir" env:"DUNN_BROWSER_USER_DATA_DIR"`
+}
+
+type JQuants struct {
+  // BaseURL is empty for jquants.DefaultBaseURL.
+  BaseURL string `yaml:"base_url" env:"DUNN_JQUANTS_BASE_URL"`
+}
+
+type Serve struct {
+  Addr     string `yaml:"addr" env:"DUNN_SERVE_ADDR"`
+  GRPCAddr string `yaml:"grpc_addr" env:"DUNN_GRPC_ADDR"`
+}
+
+type Alerts struct {
+  File     string   `yaml:"file" env:"DUNN_ALERT_FILE"`
+  Webhook  string   `yaml:"webhook" env:"DUNN_ALERT_WEBHOOK"`
+  SMTPAddr string   `yaml:"smtp_addr" env:"DUNN_ALERT_SMTP_ADDR"`
+  MailFrom string   `yaml:"mail_from" env:"DUNN_ALERT_MAIL_FROM"`
+  // MailTo is comma separated in the env var.
+  MailTo   []string `yaml:"mail_to" env:"DUNN_ALERT_MAIL_TO"`
+}
+
+type Daemon struct {
+  Jobs []Job `yaml:"jobs"`
+}
+
+// Job is a pipeline dunn daemon runs on a schedule.
+type Job struct {
+  Name      string   `yaml:"name"`
+  // Schedule is a cron expression in JST, e.g. "45 15 * * 1-5".
+  Schedule  string   `yaml:"schedule"`
+  // AllDays runs the job on the days the exchange is closed too.
+  AllDays   bool     `yaml:"all_days"`
+  // Steps run in order: fetch, import, indicators and alerts.
+  Steps     []string `yaml:"steps"`
+  // Codes and Watchlist are what fetch fetches, import imports, and
+  // indicators and alerts cover when the job fetches or imports nothing.
+  Codes     []string `yaml:"codes"`
+  Watchlist string   `yaml:"watchlist"`
+  // Source is what fetch fetches from, browser.site when empty.
+  Source    string   `yaml:"source"`
+}
+
+func Default() *Config {
+  return &Config{
+    DB:      DB{Driver: "sqlite3"},
+    Browser: Browser{Site: "sbisec", Fetcher: "auto", Headless: true},
+    Serve:   Serve{Addr: ":8080", GRPCAddr: ":9090"},
+  }
+}
+
+// DefaultPath is $DUNN_CONFIG, or config.yaml under the user config dir.
+func DefaultPath() (string, error) {
+  if path := os.Getenv(PathEnv); path != "" { return path, nil }
+
+  dir, err := os.UserConfigDir()
+  if err != nil { return "", err }
+
+  return filepath.Join(dir, "dunn-finance", "config.yaml"), nil
+}
+
+// Load reads the file at path over Default and applies the env vars. An
+// empty path reads DefaultPath, which may be missing; a given path must exist.
+func Load(path string) (*Config, error) {
+  cfg := Default()
+
+  optional := path == ""
+  if optional {
+    var err error
+    if path, err = DefaultPath(); err != nil { return nil, err }
+    optional = os.Getenv(PathEnv) == ""
+  }
+
+  data, err := os.ReadFile(path)
+  switch {
+    case err == nil:
+      if err := yaml.Unmarshal(data, cfg); err != nil { return nil, fmt.Errorf("failed to parse %s: %w", path, err) }
+    case optional && errors.Is(err, os.ErrNotExist):
+    default:
+      return nil, err
+  }
+
+  if err := applyEnv(reflect.ValueOf(cfg).Elem()); err != nil { return nil, err }
+  cfg.expandHome()
+
+  return cfg, nil
+}
+
+func applyEnv(v reflect.Value) error {
+  for i := 0; i < v.NumField(); i++ {
+    field, value := v.Type().Field(i), v.Field(i)
+    if field.Type.Kind() == reflect.Struct {
+      if err := applyEnv(value); err != nil { return err }
+      continue
+    }
+
+    name := field.Tag.Get("env")
+    s, ok := os.LookupEnv(name)
+    if name == "" || !ok { continue }
+
+    switch value.Interface().(type) {
+      case string:
+        value.SetString(s)
+      case bool:
+        b, err := strconv.ParseBool(s)
+        if err != nil { return fmt.Errorf("%s: %w", name, err) }
+        value.SetBool(b)
+      case []string:
+        var items []string
+        for _, item := range strings.Split(s, ",") {
+          if item = strings.TrimSpace(item); item != "" { items = append(items, item) }
+        }
+        value.Set(reflect.ValueOf(items))
+    }
+  }
+
+  return nil
+}
+
+// expandHome turns a leading ~/ of the paths into the home directory.
+func (c *Config) expandHome() {
+  home, err := os.UserHomeDir()
+  if err != nil { return }
+
+  for _, path := range []*string{&c.DB.Path, &c.Data.CSVDir, &c.Data.ExportDir, &c.Data.ReportDir, &c.Browser.Bin, &c.Browser.UserDataDir, &c.Alerts.File} {
+    if strings.HasPrefix(*path, "~/") { *path = filepath.Join(home, (*path)[2:]) }
+  }
+}
@@ -0,0 +1,78 @@
+package config_test
+
+import (
+  "os"
+  "path/filepath"
+  "slices"
+  "testing"
+
+  "dunn-finance/pkg/config"
+)
+
+func writeConfig(t *testing.T, content string) string {
+  path := filepath.Join(t.TempDir(), "config.yaml")
+  if err := os.WriteFile(path, []byte(content), 0o600); err != nil { t.Fatal(err) }
+  return path
+}
+
+func TestLoad_File_Success(t *testing.T) {
+  t.Setenv("HOME", "/home/dunn")
+  path := writeConfig(t, `
+db:
+  path: ~/dunn.db
+data:
+  csv_dir: /data/sbi
+browser:
+  headless: false
+  bin: /usr/bin/chromium
+alerts:
+  mail_to: [me@example.com]
+daemon:
+  jobs:
+    - name: eod
+      schedule: 45 15 * * 1-5
+      steps: [fetch, import, indicators, alerts]
+      watchlist: core
+`)
+
+  cfg, err := config.Load(path)
+  if err != nil { t.Fatal(err) }
+  if cfg.DB.Driver != "sqlite3" || cfg.DB.Path != "/home/dunn/dunn.db" { t.Errorf("Unexpected db: %+v", cfg.DB) }
+  if cfg.Data.CSVDir != "/data/sbi" { t.Errorf("Unexpected data: %+v", cfg.Data) }
+  if cfg.Browser.Headless || cfg.Browser.Bin != "/usr/bin/chromium" || cfg.Browser.Fetcher != "auto" { t.Errorf("Unexpected browser: %+v", cfg.Browser) }
+  if cfg.Serve.Addr != ":8080" { t.Errorf("Expected the default addr, got %q", cfg.Serve.Addr) }
+  if !slices.Equal(cfg.Alerts.MailTo, []string{"me@example.com"}) { t.Errorf("Unexpected mail to: %v", cfg.Alerts.MailTo) }
+  if len(cfg.Daemon.Jobs) != 1 || cfg.Daemon.Jobs[0].Schedule != "45 15 * * 1-5" || len(cfg.Daemon.Jobs[0].Steps) != 4 || cfg.Daemon.Jobs[0].Watchlist != "core" { t.Errorf("Unexpected jobs: %+v", cfg.Daemon.Jobs) }
+}
+
+func TestLoad_Env_Overrides(t *testing.T) {
+  path := writeConfig(t, "db:\n  driver: sqlite3\n  path: /data/dunn.db\n")
+  t.Setenv("DUNN_DB_DRIVER", "postgres")
+  t.Setenv("DUNN_DB_PATH", "postgres://localhost/dunn")
+  t.Setenv("DUNN_BROWSER_HEADLESS", "false")
+  t.Setenv("DUNN_ALERT_MAIL_TO", "a@example.com, b@example.com")
+
+  cfg, err := config.Load(path)
+  if err != nil { t.Fatal(err) }
+  if cfg.DB.Driver != "postgres" || cfg.DB.Path != "postgres://localhost/dunn" { t.Errorf("Unexpected db: %+v", cfg.DB) }
+  if cfg.Browser.Headless { t.Error("Expected headless to be overridden") }
+  if !slices.Equal(cfg.Alerts.MailTo, []string{"a@example.com", "b@example.com"}) { t.Errorf("Unexpected mail to: %v", cfg.Alerts.MailTo) }
+
+  t.Setenv("DUNN_BROWSER_HEADLESS", "maybe")
+  if _, err := config.Load(path); err == nil { t.Error("Expected an invalid bool to fail") }
+}
+
+func TestLoad_Missing(t *testing.T) {
+  t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+  t.Setenv("HOME", t.TempDir())
+
+  cfg, err := config.Load("")
+  if err != nil { t.Fatalf("A missing default file should be fine: %v", err) }
+  if cfg.DB.Driver != "sqlite3" || !cfg.Browser.Headless { t.Errorf("Expected the defaults, got %+v", cfg) }
+
+  if _, err := config.Load(filepath.Join(t.TempDir(), "nothing.yaml")); err == nil { t.Error("Expected a missing given file to fail") }
+  t.Setenv(config.PathEnv, filepath.Join(t.TempDir(), "nothing.yaml"))
+  if _, err := config.Load(""); err == nil { t.Errorf("Expected a missing %s file to fail", config.PathEnv) }
+
+  if _, err := config.Load(writeConfig(t, "db: [")); err == nil { t.Error("Expected invalid YAML to fail") }
+}
@@ -0,0 +1,184 @@
+package credentials
+
+import (
+  "crypto/aes"
+  "crypto/cipher"
+  "crypto/rand"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "os"
+  "path/filepath"
+  "strings"
+
+  "golang.org/x/crypto/scrypt"
+)
+
+const (
+  PassphraseEnv = "DUNN_CREDS_PASSPHRASE"
+  PathEnv       = "DUNN_CREDS_PATH"
+
+  fileVersion = 1
+  saltSize    = 16
+  keySize     = 32
+)
+
+var (
+  ErrNotFound        = errors.New("credential not found")
+  ErrWrongPassphrase = errors.New("wrong passphrase or corrupted credential file")
+)
+
+type Credential struct {
+  UserID   string `json:"user_id"`
+  Password string `json:"password"`
+  PIN      string `json:"pin,omitempty"` // Trading PIN. Only needed for orders.
+}
+
+// Store is a credential file encrypted with AES-256-GCM.
+// The key is derived from the passphrase with scrypt.
+type Store struct {
+  Path       string
+  Passphrase []byte
+}
+
+type envelope struct {
+  Version    int    `json:"version"`
+  Salt       []byte `json:"salt"`
+  Nonce      []byte `json:"nonce"`
+  Ciphertext []byte `json:"ciphertext"`
+}
+
+// DefaultPath is $DUNN_CREDS_PATH, or credentials.enc under the user config dir.
+func DefaultPath() (string, error) {
+  if path := os.Getenv(PathEnv); path != "" { return path, nil }
+
+  dir, err := os.UserConfigDir()
+  if err != nil { return "", err }
+
+  return filepath.Join(dir, "dunn-finance", "credentials.enc"), nil
+}
+
+func (s *Store) Set(site string, cred *Credential) error {
+  creds, err := s.load()
+  if err != nil { return err }
+
+  creds[site] = cred
+
+  return s.save(creds)
+}
+
+func (s *Store) Get(site string) (*Credential, error) {
+  creds, err := s.load()
+  if err != nil { return nil, err }
+
+  cred, ok := creds[site]
+  if !ok { return nil, fmt.Errorf("%w: %s", ErrNotFound, site) }
+
+  return cred, nil
+}
+
+func (s *Store) Delete(site string) error {
+  creds, err := s.load()
+  if err != nil { return err }
+
+  if _, ok := creds[site]; !ok { return fmt.Errorf("%w: %s", ErrNotFound, site) }
+  delete(creds, site)
+
+  return s.save(creds)
+}
+
+// load returns an empty map when the file does not exist yet.
+func (s *Store) load() (map[string]*Credential, error) {
+  creds := make(map[string]*Credential)
+
+  b, err := os.ReadFile(s.Path)
+  if errors.Is(err, os.ErrNotExist) { return creds, nil }
+  if err != nil { return nil, err }
+
+  var env envelope
+  if err := json.Unmarshal(b, &env); err != nil { return nil, fmt.Errorf("failed to read credential file: %w", err) }
+  if env.Version != fileVersion { return nil, fmt.Errorf("unsupported credential file version: %d", env.Version) }
+
+  aead, err := newAEAD(s.Passphrase, env.Salt)
+  if err != nil { return nil, err }
+
+  plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, nil)
+  if err != nil { return nil, ErrWrongPassphrase }
+
+  if err := json.Unmarshal(plaintext, &creds); err != nil { return nil, err }
+
+  return creds, nil
+}
+
+func (s *Store) save(creds map[string]*Credential) error {
+  plaintext, err := json.Marshal(creds)
+  if err != nil { return err }
+
+  salt := make([]byte, saltSize)
+  if _, err := rand.Read(salt); err != nil { return err }
+
+  aead, err := newAEAD(s.Passphrase, salt)
+  if err != nil { return err }
+
+  nonce := make([]byte, aead.NonceSize())
+  if _, err := rand.Read(nonce); err != nil { return err }
+
+  b, err := json.Marshal(&envelope{
+    Version:    fileVersion,
+    Salt:       salt,
+    Nonce:      nonce,
+    Ciphertext: aead.Seal(nil, nonce, plaintext, nil),
+  })
+  if err != nil { return err }
+
+  if err := os.MkdirAll(filepath.Dir(s.Path), 0o700); err != nil { return err }
+
+  // Write to a temporary file first so a crash never leaves a truncated store.
+  tmp := s.Path + ".tmp"
+  if err := os.WriteFile(tmp, b, 0o600); err != nil { return err }
+
+  return os.Rename(tmp, s.Path)
+}
+
+func newAEAD(passphrase []byte, salt []byte) (cipher.AEAD, error) {
+  if len(passphrase) == 0 { return nil, errors.New("passphrase is empty") }
+
+  key, err := scrypt.Key(passphrase, salt, 1<<15, 8, 1, keySize)
+  if err != nil { return nil, err }
+
+  block, err := aes.NewCipher(key)
+  if err != nil { return nil, err }
+
+  return cipher.NewGCM(block)
+}
+
+// FromEnv reads DUNN_<SITE>_USER_ID, DUNN_<SITE>_PASSWORD and DUNN_<SITE>_PIN.
+func FromEnv(site string) (*Credential, error) {
+  prefix := "DUNN_" + strings.ToUpper(site) + "_"
+  cred := &Credential{
+    UserID:   os.Getenv(prefix + "USER_ID"),
+    Password: os.Getenv(prefix + "PASSWORD"),
+    PIN:      os.Getenv(prefix + "PIN"),
+  }
+  if cred.UserID == "" || cred.Password == "" {
+    return nil, fmt.Errorf("%w: %sUSER_ID and %sPASSWORD are not set", ErrNotFound, prefix, prefix)
+  }
+
+  return cred, nil
+}
+
+// Lookup returns the credential for site from the encrypted store when
+// DUNN_CREDS_PASSPHRASE is set and the store has it, otherwise from env vars.
+func Lookup(site string) (*Credential, error) {
+  if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+    path, err := DefaultPath()
+    if err != nil { return nil, err }
+
+    store := &Store{Path: path, Passphrase: []byte(passphrase)}
+    cred, err := store.Get(site)
+    if err == nil { return cred, nil }
+    if !errors.Is(err, ErrNotFound) { return nil, err }
+  }
+
+  return FromEnv(site)
+}
@@ -0,0 +1,115 @@
+package credentials_test
+
+import (
+  "errors"
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+
+  "dunn-finance/pkg/credentials"
+)
+
+func newStore(t *testing.T) *credentials.Store {
+  return &credentials.Store{
+    Path:       filepath.Join(t.TempDir(), "credentials.enc"),
+    Passphrase: []byte("correct horse battery staple"),
+  }
+}
+
+func TestStore_SetGet_Success(t *testing.T) {
+  store := newStore(t)
+  cred := &credentials.Credential{UserID: "user", Password: "secret-password", PIN: "1234"}
+
+  if err := store.Set("sbisec", cred); err != nil { t.Fatal(err) }
+
+  actual, err := store.Get("sbisec")
+  if err != nil { t.Fatal(err) }
+  if *actual != *cred { t.Errorf("got %+v, want %+v", *actual, *cred) }
+
+  b, err := os.ReadFile(store.Path)
+  if err != nil { t.Fatal(err) }
+  if strings.Contains(string(b), "secret-password") { t.Errorf("Password is stored in plain text") }
+
+  info, err := os.Stat(store.Path)
+  if err != nil { t.Fatal(err) }
+  if info.Mode().Perm() != 0o600 { t.Errorf("Expected file mode 0600, got %o", info.Mode().Perm()) }
+}
+
+func TestStore_Get_Failure(t *testing.T) {
+  t.Run("not stored site", func(t *testing.T) {
+    store := newStore(t)
+    if err := store.Set("sbisec", &credentials.Credential{UserID: "user", Password: "pw"}); err != nil { t.Fatal(err) }
+
+    _, err := store.Get("rakuten")
+    if !errors.Is(err, credentials.ErrNotFound) { t.Errorf("Expected ErrNotFound, got %v", err) }
+  })
+
+  t.Run("wrong passphrase", func(t *testing.T) {
+    store := newStore(t)
+    if err := store.Set("sbisec", &credentials.Credential{UserID: "user", Password: "pw"}); err != nil { t.Fatal(err) }
+
+    wrong := &credentials.Store{Path: store.Path, Passphrase: []byte("wrong")}
+    _, err := wrong.Get("sbisec")
+    if !errors.Is(err, credentials.ErrWrongPassphrase) { t.Errorf("Expected ErrWrongPassphrase, got %v", err) }
+  })
+
+  t.Run("tampered file", func(t *testing.T) {
+    store := newStore(t)
+    if err := store.Set("sbisec", &credentials.Credential{UserID: "user", Password: "pw"}); err != nil { t.Fatal(err) }
+
+    b, err := os.ReadFile(store.Path)
+    if err != nil { t.Fatal(err) }
+    // Flip a character inside the base64 ciphertext.
+    i := strings.Index(string(b), `"ciphertext":"`) + len(`"ciphertext":"`)
+    if b[i] == 'A' { b[i] = 'B' } else { b[i] = 'A' }
+    if err := os.WriteFile(store.Path, b, 0o600); err != nil { t.Fatal(err) }
+
+    _, err = store.Get("sbisec")
+    if !errors.Is(err, credentials.ErrWrongPassphrase) { t.Errorf("Expected ErrWrongPassphrase, got %v", err) }
+  })
+}
+
+func TestStore_Delete_Success(t *testing.T) {
+  store := newStore(t)
+  if err := store.Set("sbisec", &credentials.Credential{UserID: "user", Password: "pw"}); err != nil { t.Fatal(err) }
+
+  if err := store.Delete("sbisec"); err != nil { t.Fatal(err) }
+
+  _, err := store.Get("sbisec")
+  if !errors.Is(err, credentials.ErrNotFound) { t.Errorf("Expected ErrNotFound, got %v", err) }
+}
+
+func TestLookup_FromEnv_Success(t *testing.T) {
+  t.Setenv(credentials.PassphraseEnv, "")
+  t.Setenv("DUNN_SBISEC_USER_ID", "env-user")
+  t.Setenv("DUNN_SBISEC_PASSWORD", "env-password")
+
+  cred, err := credentials.Lookup("sbisec")
+  if err != nil { t.Fatal(err) }
+  if cred.UserID != "env-user" { t.Errorf("got %s, want env-user", cred.UserID) }
+  if cred.Password != "env-password" { t.Errorf("got %s, want env-password", cred.Password) }
+}
+
+func TestLookup_FromStore_Success(t *testing.T) {
+  store := newStore(t)
+  if err := store.Set("sbisec", &credentials.Credential{UserID: "store-user", Password: "pw"}); err != nil { t.Fatal(err) }
+
+  t.Setenv(credentials.PathEnv, store.Path)
+  t.Setenv(credentials.PassphraseEnv, string(store.Passphrase))
+  t.Setenv("DUNN_SBISEC_USER_ID", "env-user")
+  t.Setenv("DUNN_SBISEC_PASSWORD", "env-password")
+
+  cred, err := credentials.Lookup("sbisec")
+  if err != nil { t.Fatal(err) }
+  if cred.UserID != "store-user" { t.Errorf("got %s, want store-user", cred.UserID) }
+}
+
+func TestLookup_Failure(t *testing.T) {
+  t.Setenv(credentials.PassphraseEnv, "")
+  t.Setenv("DUNN_SBISEC_USER_ID", "")
+  t.Setenv("DUNN_SBISEC_PASSWORD", "")
+
+  _, err := credentials.Lookup("sbisec")
+  if !errors.Is(err, credentials.ErrNotFound) { t.Errorf("Expected ErrNotFound, got %v", err) }
+}
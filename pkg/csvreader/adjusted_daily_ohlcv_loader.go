@@ -2,30 +2,73 @@ package csvreader
 
 import (
   "encoding/csv"
+  "fmt"
+  "io"
   "os"
   "reflect"
   "strconv"
   "strings"
-  "time"
 
   "dunn-finance/pkg/model"
 )
 
+// SBIFieldMap maps the columns of the SBI Securities time chart CSV to the
+// fields of model.AdjustedDailyOHLCV.
+var SBIFieldMap = map[int]string{
+  0: "Yyyymmdd",
+  1: "OpenPrice",
+  2: "HighPrice",
+  3: "LowPrice",
+  4: "ClosePrice",
+  5: "DMAPrice5",
+  6: "DMAPrice25",
+  7: "DMAPrice75",
+  8: "VMAP",
+  9: "Volume",
+  10: "VMA5",
+  11: "VMA25",
+}
 
-func stringToFloat(s string) *float64 {
-  if s == "--" { return nil }
+// parseFloat reads a cell such as "2,189". "--" and an empty cell are
+// missing values, not zero.
+func parseFloat(s string) (*float64, error) {
+  s = strings.TrimSpace(s)
+  if s == "--" || s == "" { return nil, nil }
   s = strings.ReplaceAll(s, ",", "")
-  f, _ := strconv.ParseFloat(s, 64)
+  f, err := strconv.ParseFloat(s, 64)
+  if err != nil { return nil, err }
+
+  return &f, nil
+}
+
+var dateType = reflect.TypeOf(model.Date{})
+
+// Row is one CSV record with the line it starts on, counting the header as
+// line 1. Cells that failed to parse are left unset and listed in Errs.
+type Row struct {
+  Line  int
+  OHLCV *model.AdjustedDailyOHLCV
+  Errs  []*CellError
+}
 
-  return &f
+// CellError is a cell that could not be parsed into its field.
+type CellError struct {
+  Field string
+  Value string
+  Err   error
 }
 
+func (e *CellError) Error() string {
+  return fmt.Sprintf("%s %q: %v", e.Field, e.Value, e.Err)
+}
 
-func parseDateToYyyymmdd(s string) string {
-  t, _ := time.Parse("2025/07/13", s)
-  return t.Format("20060102")
+func (e *CellError) Unwrap() error {
+  return e.Err
 }
 
+// LoadAdjustedDailyOHLCVsFromCSV returns the records, failing on the first
+// cell that does not parse. Use LoadAdjustedDailyOHLCVRowsFromCSV to get
+// every row with its problems instead.
 func LoadAdjustedDailyOHLCVsFromCSV(
   code string,
   path string,
@@ -34,6 +77,26 @@ func LoadAdjustedDailyOHLCVsFromCSV(
   offset int,
   limit int,
 ) ([]*model.AdjustedDailyOHLCV, error) {
+  rows, err := LoadAdjustedDailyOHLCVRowsFromCSV(code, path, fieldMap, isSkipHeader, offset, limit)
+  if err != nil { return nil, err }
+
+  result := make([]*model.AdjustedDailyOHLCV, 0, len(rows))
+  for _, row := range rows {
+    if len(row.Errs) > 0 { return nil, fmt.Errorf("%s line %d: %w", path, row.Line, row.Errs[0]) }
+    result = append(result, row.OHLCV)
+  }
+
+  return result, nil
+}
+
+func LoadAdjustedDailyOHLCVRowsFromCSV(
+  code string,
+  path string,
+  fieldMap map[int]string,
+  isSkipHeader bool,
+  offset int,
+  limit int,
+) ([]*Row, error) {
   f, err := os.Open(path)
   if err != nil { return nil ,err }
   defer f.Close()
@@ -43,12 +106,13 @@ func LoadAdjustedDailyOHLCVsFromCSV(
     _, _ = r.Read()
   }
 
-  var result []*model.AdjustedDailyOHLCV
+  var result []*Row
   rowIndex := 0
   readCount := 0
   for {
     csvRow, err := r.Read()
-    if err != nil { break }
+    if err == io.EOF { break }
+    if err != nil { return nil, fmt.Errorf("%s: %w", path, err) }
 
     if rowIndex < offset {
       rowIndex++
@@ -56,18 +120,22 @@ func LoadAdjustedDailyOHLCVsFromCSV(
     }
     if limit > 0 && readCount >= limit { break }
 
-
-    ohlcv := &model.AdjustedDailyOHLCV{Code: code}
-    ohlcvVal := reflect.ValueOf(ohlcv).Elem()
+    line, _ := r.FieldPos(0)
+    row := &Row{Line: line, OHLCV: &model.AdjustedDailyOHLCV{Code: code}}
+    ohlcvVal := reflect.ValueOf(row.OHLCV).Elem()
 
     for i, csvVal := range csvRow {
       fieldName := fieldMap[i]
       ohlcvField := ohlcvVal.FieldByName(fieldName)
       if !ohlcvField.IsValid() || !ohlcvField.CanSet() { continue }
 
-      if fieldName == "Yyyymmdd" {
-        t, err := time.Parse("2006/01/02", csvVal)
-        if err == nil { ohlcvField.SetString(t.Format("20060102")) }
+      if ohlcvField.Type() == dateType {
+        d, err := model.ParseDate(csvVal)
+        if err != nil {
+          row.Errs = append(row.Errs, &CellError{Field: fieldName, Value: csvVal, Err: err})
+          continue
+        }
+        ohlcvField.Set(reflect.ValueOf(d))
 
         continue
       }
@@ -77,12 +145,17 @@ func LoadAdjustedDailyOHLCVsFromCSV(
           ohlcvField.SetString(csvVal)
         case reflect.Ptr:
           if ohlcvField.Type().Elem().Kind() == reflect.Float64 {
-            ohlcvField.Set(reflect.ValueOf(stringToFloat(csvVal)))
+            f, err := parseFloat(csvVal)
+            if err != nil {
+              row.Errs = append(row.Errs, &CellError{Field: fieldName, Value: csvVal, Err: err})
+              continue
+            }
+            ohlcvField.Set(reflect.ValueOf(f))
           }
       }
     }
 
-    result = append(result, ohlcv)
+    result = append(result, row)
     rowIndex++
     readCount++
   }
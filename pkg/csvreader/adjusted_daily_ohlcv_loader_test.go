@@ -1,9 +1,14 @@
 package csvreader_test
 
 import (
+  "errors"
+  "os"
+  "path/filepath"
+  "strings"
   "testing"
 
   "dunn-finance/pkg/csvreader"
+  "dunn-finance/pkg/model"
 )
 
 var fieldMap = map[int]string{
@@ -27,7 +32,7 @@ func TestLoadAdjustedDailyOHLCVsFromCSV_Success(t *testing.T) {
 
   // validate record of 2023/03/30
   record := records[565]
-  if record.Yyyymmdd != "20230330" { t.Errorf("Expected: 20230330, but got: %s", record.Yyyymmdd) }
+  if record.Yyyymmdd.String() != "20230330" { t.Errorf("Expected: 20230330, but got: %s", record.Yyyymmdd) }
   if record.Code != "5253" { t.Errorf("Expected: 5253, but got: %s", record.Code) }
   if *record.OpenPrice != 1465 { t.Errorf("Expected: 1465, but got: %f", *record.OpenPrice) }
   if *record.HighPrice != 1486 { t.Errorf("Expected: 1486, but got: %f", *record.HighPrice) }
@@ -46,12 +51,41 @@ func TestLoadAdjustedDailyOHLCVsFromCSV_with_offset_limit_Success(t *testing.T)
   records, err := csvreader.LoadAdjustedDailyOHLCVsFromCSV("5253", "testdata/sbi_timechart_5253_20250720.csv", fieldMap, true, 9, 5)
   if err != nil { t.Fatal(err) }
   if len(records) != 5 { t.Errorf("Expected record length: 5, but is %d", len(records)) }
-  if records[0].Yyyymmdd != "20250707" { t.Errorf("Expected: 20250707, but got %s", records[0].Yyyymmdd) }
-  if records[4].Yyyymmdd != "20250701" { t.Errorf("Expected: 20250701, but got %s", records[1].Yyyymmdd) }
+  if records[0].Yyyymmdd.String() != "20250707" { t.Errorf("Expected: 20250707, but got %s", records[0].Yyyymmdd) }
+  if records[4].Yyyymmdd.String() != "20250701" { t.Errorf("Expected: 20250701, but got %s", records[1].Yyyymmdd) }
 
   records, err = csvreader.LoadAdjustedDailyOHLCVsFromCSV("5253", "testdata/sbi_timechart_5253_20250720.csv", fieldMap, true, 14, 5)
   if err != nil { t.Fatal(err) }
   if len(records) != 5 { t.Errorf("Expected record length: 5, but is %d", len(records)) }
-  if records[0].Yyyymmdd != "20250630" { t.Errorf("Expected: 20250630, but got %s", records[0].Yyyymmdd) }
-  if records[4].Yyyymmdd != "20250624" { t.Errorf("Expected: 20250624, but got %s", records[1].Yyyymmdd) }
+  if records[0].Yyyymmdd.String() != "20250630" { t.Errorf("Expected: 20250630, but got %s", records[0].Yyyymmdd) }
+  if records[4].Yyyymmdd.String() != "20250624" { t.Errorf("Expected: 20250624, but got %s", records[1].Yyyymmdd) }
+}
+
+func TestLoadAdjustedDailyOHLCVsFromCSV_MalformedDate_Failure(t *testing.T) {
+  path := filepath.Join(t.TempDir(), "broken.csv")
+  content := "日付,始値\n2025/07/18,100\n2025/02/30,101\n"
+  if err := os.WriteFile(path, []byte(content), 0o600); err != nil { t.Fatal(err) }
+
+  _, err := csvreader.LoadAdjustedDailyOHLCVsFromCSV("5253", path, fieldMap, true, 0, 0)
+  if !errors.Is(err, model.ErrInvalidDate) { t.Errorf("Expected ErrInvalidDate, got %v", err) }
+}
+
+func TestLoadAdjustedDailyOHLCVRowsFromCSV_CellErrors(t *testing.T) {
+  path := filepath.Join(t.TempDir(), "garbled.csv")
+  content := "日付,始値,高値\n2025/07/18,\"2,189\",--\n2025/07/17,2l73,\n"
+  if err := os.WriteFile(path, []byte(content), 0o600); err != nil { t.Fatal(err) }
+
+  rows, err := csvreader.LoadAdjustedDailyOHLCVRowsFromCSV("5253", path, fieldMap, true, 0, 0)
+  if err != nil { t.Fatal(err) }
+  if len(rows) != 2 { t.Fatalf("want 2 rows, got %d", len(rows)) }
+
+  if rows[0].Line != 2 || len(rows[0].Errs) != 0 || *rows[0].OHLCV.OpenPrice != 2189 || rows[0].OHLCV.HighPrice != nil { t.Errorf("Unexpected first row: %+v", rows[0]) }
+
+  // A garbled cell must not become a zero price.
+  second := rows[1]
+  if second.Line != 3 || len(second.Errs) != 1 || second.Errs[0].Field != "OpenPrice" { t.Errorf("Unexpected second row: %+v", second) }
+  if second.OHLCV.OpenPrice != nil { t.Errorf("Expected no open price, got %f", *second.OHLCV.OpenPrice) }
+  if second.OHLCV.HighPrice != nil { t.Errorf("Expected an empty cell to be missing, got %f", *second.OHLCV.HighPrice) }
+
+  if _, err := csvreader.LoadAdjustedDailyOHLCVsFromCSV("5253", path, fieldMap, true, 0, 0); err == nil || !strings.Contains(err.Error(), "line 3") { t.Errorf("Expected an error on line 3, got %v", err) }
 }
@@ -0,0 +1,37 @@
+package csvreader
+
+import (
+  "bufio"
+  "fmt"
+  "os"
+  "path/filepath"
+  "regexp"
+)
+
+var (
+  // sbi_timechart_5253_20250720.csv
+  fileNameCodePattern = regexp.MustCompile(`(?:^|[_\-])(\d{3}[0-9A-Z])(?:[_\-.]|$)`)
+  // 銘柄コード: 5253, コード,5253, etc.
+  contentCodePattern = regexp.MustCompile(`コード\s*[:：,]?\s*"?(\d{3}[0-9A-Z])\b`)
+)
+
+const codeDetectLines = 5
+
+// DetectCode infers the stock code from the file name, or from the first
+// lines of the file when the name has none.
+func DetectCode(path string) (string, error) {
+  name := filepath.Base(path)
+  if m := fileNameCodePattern.FindStringSubmatch(name); m != nil { return m[1], nil }
+
+  f, err := os.Open(path)
+  if err != nil { return "", err }
+  defer f.Close()
+
+  scanner := bufio.NewScanner(f)
+  for i := 0; i < codeDetectLines && scanner.Scan(); i++ {
+    if m := contentCodePattern.FindStringSubmatch(scanner.Text()); m != nil { return m[1], nil }
+  }
+  if err := scanner.Err(); err != nil { return "", err }
+
+  return "", fmt.Errorf("failed to detect stock code from %s", path)
+}
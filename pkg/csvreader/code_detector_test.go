@@ -0,0 +1,41 @@
+package csvreader_test
+
+import (
+  "os"
+  "path/filepath"
+  "testing"
+
+  "dunn-finance/pkg/csvreader"
+)
+
+func TestDetectCode_FromFileName_Success(t *testing.T) {
+  tests := map[string]string{
+    "testdata/sbi_timechart_5253_20250720.csv": "5253",
+    "/tmp/sbi_timechart_130A_20250720.csv":     "130A",
+    "7203.csv":                                 "7203",
+  }
+
+  for path, expected := range tests {
+    actual, err := csvreader.DetectCode(path)
+    if err != nil { t.Errorf("%s: %v", path, err); continue }
+    if actual != expected { t.Errorf("%s: got %s, want %s", path, actual, expected) }
+  }
+}
+
+func TestDetectCode_FromContent_Success(t *testing.T) {
+  path := filepath.Join(t.TempDir(), "export.csv")
+  content := "銘柄コード: 6758 ソニーグループ\n日付,始値,高値,安値,終値\n"
+  if err := os.WriteFile(path, []byte(content), 0o644); err != nil { t.Fatal(err) }
+
+  actual, err := csvreader.DetectCode(path)
+  if err != nil { t.Fatal(err) }
+  if actual != "6758" { t.Errorf("got %s, want 6758", actual) }
+}
+
+func TestDetectCode_Failure(t *testing.T) {
+  path := filepath.Join(t.TempDir(), "export.csv")
+  if err := os.WriteFile(path, []byte("日付,始値,高値,安値,終値\n"), 0o644); err != nil { t.Fatal(err) }
+
+  _, err := csvreader.DetectCode(path)
+  if err == nil { t.Errorf("Expected error, got nil") }
+}
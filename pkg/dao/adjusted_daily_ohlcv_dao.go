@@ -1,18 +1,32 @@
 package dao
 
 import (
-  "database/sql"
+  "context"
+  "fmt"
+  "iter"
+  "strings"
 
   "dunn-finance/pkg/model"
   "dunn-finance/pkg/database"
 )
 
 type AdjustedDailyOHLCVDAO struct {
-  DB database.DBConnector
+  Repository[model.AdjustedDailyOHLCV]
 }
 
-func (dao *AdjustedDailyOHLCVDAO) Create(ohlcv *model.AdjustedDailyOHLCV) error {
-  _, err := dao.DB.Exec(
+func NewAdjustedDailyOHLCVDAO(db database.Querier) *AdjustedDailyOHLCVDAO {
+  return &AdjustedDailyOHLCVDAO{Repository[model.AdjustedDailyOHLCV]{DB: db, Table: "adjusted_daily_ohlcvs"}}
+}
+
+func (dao *AdjustedDailyOHLCVDAO) Create(ctx context.Context, ohlcv *model.AdjustedDailyOHLCV) error {
+  return dao.Upsert(ctx, ohlcv)
+}
+
+// UpsertPrices writes only the prices and volume, keeping the moving averages
+// already stored for the row. It is for sources that do not publish them.
+func (dao *AdjustedDailyOHLCVDAO) UpsertPrices(ctx context.Context, ohlcv *model.AdjustedDailyOHLCV) error {
+  _, err := dao.DB.ExecContext(
+    ctx,
     `
     INSERT INTO adjusted_daily_ohlcvs (
       yyyymmdd,
@@ -21,26 +35,16 @@ func (dao *AdjustedDailyOHLCVDAO) Create(ohlcv *model.AdjustedDailyOHLCV) error
       high_price,
       low_price,
       close_price,
-      dma_price_5,
-      dma_price_25,
-      dma_price_75,
-      vmap,
       volume,
-      vma_5,
-      vma_25
-    ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+      import_id
+    ) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
     ON CONFLICT(yyyymmdd, code) DO UPDATE SET
-      open_price   = excluded.open_price,
-      high_price   = excluded.high_price,
-      low_price    = excluded.low_price,
-      close_price  = excluded.close_price,
-      dma_price_5  = excluded.dma_price_5,
-      dma_price_25 = excluded.dma_price_25,
-      dma_price_75 = excluded.dma_price_75,
-      vmap         = excluded.vmap,
-      volume       = excluded.volume,
-      vma_5        = excluded.vma_5,
-      vma_25       = excluded.vma_25
+      open_price  = excluded.open_price,
+      high_price  = excluded.high_price,
+      low_price   = excluded.low_price,
+      close_price = excluded.close_price,
+      volume      = excluded.volume,
+      import_id   = excluded.import_id
     `,
     ohlcv.Yyyymmdd,
     ohlcv.Code,
@@ -48,125 +52,186 @@ func (dao *AdjustedDailyOHLCVDAO) Create(ohlcv *model.AdjustedDailyOHLCV) error
     ohlcv.HighPrice,
     ohlcv.LowPrice,
     ohlcv.ClosePrice,
+    ohlcv.Volume,
+    ohlcv.ImportID,
+  )
+
+  return err
+}
+
+// UpdateMovingAverages writes only the moving averages of an existing row.
+func (dao *AdjustedDailyOHLCVDAO) UpdateMovingAverages(ctx context.Context, ohlcv *model.AdjustedDailyOHLCV) error {
+  _, err := dao.DB.ExecContext(
+    ctx,
+    `
+    UPDATE adjusted_daily_ohlcvs SET
+      dma_price_5  = ?,
+      dma_price_25 = ?,
+      dma_price_75 = ?,
+      vma_5        = ?,
+      vma_25       = ?
+    WHERE code = ? AND yyyymmdd = ?
+    `,
     ohlcv.DMAPrice5,
     ohlcv.DMAPrice25,
     ohlcv.DMAPrice75,
-    ohlcv.VMAP,
-    ohlcv.Volume,
     ohlcv.VMA5,
     ohlcv.VMA25,
+    ohlcv.Code,
+    ohlcv.Yyyymmdd,
   )
 
   return err
 }
 
-func (dao *AdjustedDailyOHLCVDAO) Find(code string, yyyymmdd string) (*model.AdjustedDailyOHLCV, error) {
-  row := dao.DB.QueryRow(`
-    SELECT
-      yyyymmdd,
-      code,
-      open_price,
-      high_price,
-      low_price,
-      close_price,
-      dma_price_5,
-      dma_price_25,
-      dma_price_75,
-      vmap,
-      volume,
-      vma_5,
-      vma_25
-    FROM adjusted_daily_ohlcvs
-    WHERE code = ? AND yyyymmdd = ?
-  `, code, yyyymmdd)
+func (dao *AdjustedDailyOHLCVDAO) Find(ctx context.Context, code string, yyyymmdd model.Date) (*model.AdjustedDailyOHLCV, error) {
+  return dao.Repository.Find(ctx, yyyymmdd, code)
+}
 
-  var (
-    openPrice, highPrice, lowPrice, closePrice sql.NullFloat64
-    dmaPrice5, dmaPrice25, dmaPrice75 sql.NullFloat64
-    vmap, volume, vma5, vma25 sql.NullFloat64
-  )
-  var ohlcv model.AdjustedDailyOHLCV
-
-  err := row.Scan(
-    &ohlcv.Yyyymmdd,
-    &ohlcv.Code,
-    &openPrice,
-    &highPrice,
-    &lowPrice,
-    &closePrice,
-    &dmaPrice5,
-    &dmaPrice25,
-    &dmaPrice75,
-    &vmap,
-    &volume,
-    &vma5,
-    &vma25,
-  )
+func (dao *AdjustedDailyOHLCVDAO) FindByDateRange(ctx context.Context, code string, fromYyyymmdd model.Date, toYyyymmdd model.Date) ([]*model.AdjustedDailyOHLCV, error) {
+  return dao.FindMany(ctx, "WHERE code = ? AND yyyymmdd BETWEEN ? AND ? ORDER BY yyyymmdd", code, fromYyyymmdd, toYyyymmdd)
+}
+
+// FindByDate returns the cross-section of every code on one date.
+func (dao *AdjustedDailyOHLCVDAO) FindByDate(ctx context.Context, yyyymmdd model.Date) ([]*model.AdjustedDailyOHLCV, error) {
+  return dao.FindMany(ctx, "WHERE yyyymmdd = ? ORDER BY code", yyyymmdd)
+}
+
+// FindByCodesAndDateRange returns the bars of each code ordered by date.
+// Codes without bars in the range are missing from the map.
+func (dao *AdjustedDailyOHLCVDAO) FindByCodesAndDateRange(ctx context.Context, codes []string, fromYyyymmdd model.Date, toYyyymmdd model.Date) (map[string][]*model.AdjustedDailyOHLCV, error) {
+  result := make(map[string][]*model.AdjustedDailyOHLCV, len(codes))
+  for ohlcv, err := range dao.IterateByCodesAndDateRange(ctx, codes, fromYyyymmdd, toYyyymmdd) {
+    if err != nil { return nil, err }
+    result[ohlcv.Code] = append(result[ohlcv.Code], ohlcv)
+  }
+
+  return result, nil
+}
+
+// IterateByCodesAndDateRange streams the bars ordered by code and date.
+func (dao *AdjustedDailyOHLCVDAO) IterateByCodesAndDateRange(ctx context.Context, codes []string, fromYyyymmdd model.Date, toYyyymmdd model.Date) iter.Seq2[*model.AdjustedDailyOHLCV, error] {
+  if len(codes) == 0 { return func(yield func(*model.AdjustedDailyOHLCV, error) bool) {} }
+
+  clause := fmt.Sprintf("WHERE code IN (%s) AND yyyymmdd BETWEEN ? AND ? ORDER BY code, yyyymmdd", Placeholders(len(codes)))
+
+  return dao.Iterate(ctx, clause, append(stringsToArgs(codes), fromYyyymmdd, toYyyymmdd)...)
+}
+
+// IterateFiltered streams the bars ordered by code and date. Empty codes and
+// a zero bound leave that filter out, so no arguments stream the whole table.
+func (dao *AdjustedDailyOHLCVDAO) IterateFiltered(ctx context.Context, codes []string, fromYyyymmdd model.Date, toYyyymmdd model.Date) iter.Seq2[*model.AdjustedDailyOHLCV, error] {
+  var conditions []string
+  var args []any
+  if len(codes) > 0 {
+    conditions = append(conditions, fmt.Sprintf("code IN (%s)", Placeholders(len(codes))))
+    args = append(args, stringsToArgs(codes)...)
+  }
+  if !fromYyyymmdd.IsZero() {
+    conditions = append(conditions, "yyyymmdd >= ?")
+    args = append(args, fromYyyymmdd)
+  }
+  if !toYyyymmdd.IsZero() {
+    conditions = append(conditions, "yyyymmdd <= ?")
+    args = append(args, toYyyymmdd)
+  }
+
+  clause := "ORDER BY code, yyyymmdd"
+  if len(conditions) > 0 { clause = "WHERE " + strings.Join(conditions, " AND ") + " " + clause }
+
+  return dao.Iterate(ctx, clause, args...)
+}
+
+// OHLCVMatrix aligns bars of several codes on the union of their dates.
+// Bars[i][j] is the bar of Codes[j] on Dates[i], or nil when it has none.
+type OHLCVMatrix struct {
+  Dates []model.Date
+  Codes []string
+  Bars  [][]*model.AdjustedDailyOHLCV
+}
+
+func (dao *AdjustedDailyOHLCVDAO) FindMatrix(ctx context.Context, codes []string, fromYyyymmdd model.Date, toYyyymmdd model.Date) (*OHLCVMatrix, error) {
+  codeIndex := make(map[string]int, len(codes))
+  for j, code := range codes {
+    codeIndex[code] = j
+  }
+
+  matrix := &OHLCVMatrix{Codes: codes}
+  if len(codes) == 0 { return matrix, nil }
+
+  dateIndex := make(map[model.Date]int)
+  clause := fmt.Sprintf("WHERE code IN (%s) AND yyyymmdd BETWEEN ? AND ? ORDER BY yyyymmdd, code", Placeholders(len(codes)))
+  for ohlcv, err := range dao.Iterate(ctx, clause, append(stringsToArgs(codes), fromYyyymmdd, toYyyymmdd)...) {
+    if err != nil { return nil, err }
+
+    i, ok := dateIndex[ohlcv.Yyyymmdd]
+    if !ok {
+      i = len(matrix.Dates)
+      dateIndex[ohlcv.Yyyymmdd] = i
+      matrix.Dates = append(matrix.Dates, ohlcv.Yyyymmdd)
+      matrix.Bars = append(matrix.Bars, make([]*model.AdjustedDailyOHLCV, len(codes)))
+    }
+    matrix.Bars[i][codeIndex[ohlcv.Code]] = ohlcv
+  }
+
+  return matrix, nil
+}
+
+// FindLatestN returns the latest n bars of each code, oldest first.
+// All codes are returned when codes is empty.
+func (dao *AdjustedDailyOHLCVDAO) FindLatestN(ctx context.Context, codes []string, n int) (map[string][]*model.AdjustedDailyOHLCV, error) {
+  where := ""
+  args := stringsToArgs(codes)
+  if len(codes) > 0 { where = fmt.Sprintf("WHERE code IN (%s)", Placeholders(len(codes))) }
+  args = append(args, n)
+
+  columns := strings.Join(dao.Columns(), ", ")
+  query := fmt.Sprintf(`
+    SELECT %s FROM (
+      SELECT %s, ROW_NUMBER() OVER (PARTITION BY code ORDER BY yyyymmdd DESC) AS rn
+      FROM %s
+      %s
+    ) AS ranked
+    WHERE rn <= ?
+    ORDER BY code, yyyymmdd
+  `, columns, columns, dao.Table, where)
+
+  rows, err := dao.DB.QueryContext(ctx, query, args...)
   if err != nil { return nil, err }
 
-  toPointer := func (n sql.NullFloat64) *float64 {
-    if n.Valid { return &n.Float64 }
-    return nil
+  ohlcvs, err := dao.ScanRows(rows)
+  if err != nil { return nil, err }
+
+  result := make(map[string][]*model.AdjustedDailyOHLCV)
+  for _, ohlcv := range ohlcvs {
+    result[ohlcv.Code] = append(result[ohlcv.Code], ohlcv)
   }
-  ohlcv.OpenPrice = toPointer(openPrice)
-  ohlcv.HighPrice = toPointer(highPrice)
-  ohlcv.LowPrice = toPointer(lowPrice)
-  ohlcv.ClosePrice = toPointer(closePrice)
-  ohlcv.DMAPrice5 = toPointer(dmaPrice5)
-  ohlcv.DMAPrice25 = toPointer(dmaPrice25)
-  ohlcv.DMAPrice75 = toPointer(dmaPrice75)
-  ohlcv.VMAP = toPointer(vmap)
-  ohlcv.Volume = toPointer(volume)
-  ohlcv.VMA5 = toPointer(vma5)
-  ohlcv.VMA25 = toPointer(vma25)
-
-  return &ohlcv, nil
-}
-
-func (dao *AdjustedDailyOHLCVDAO) FindByDateRange(code string, fromYyyymmdd string, toYyyymmdd string) ([]*model.AdjustedDailyOHLCV, error) {
-  rows, err := dao.DB.Query(`
-    SELECT
-      yyyymmdd,
-      code,
-      open_price,
-      high_price,
-      low_price,
-      close_price,
-      dma_price_5,
-      dma_price_25,
-      dma_price_75,
-      vmap,
-      volume,
-      vma_5,
-      vma_25
-    FROM adjusted_daily_ohlcvs
-    WHERE code = ? AND yyyymmdd BETWEEN ? AND ?
-    ORDER BY yyyymmdd
-  `, code, fromYyyymmdd, toYyyymmdd)
+
+  return result, nil
+}
+
+// LatestDates returns the latest date stored for each code.
+func (dao *AdjustedDailyOHLCVDAO) LatestDates(ctx context.Context) (map[string]model.Date, error) {
+  rows, err := dao.DB.QueryContext(ctx, "SELECT code, MAX(yyyymmdd) FROM adjusted_daily_ohlcvs GROUP BY code")
   if err != nil { return nil, err }
   defer rows.Close()
 
-  var results []*model.AdjustedDailyOHLCV
+  result := make(map[string]model.Date)
   for rows.Next() {
-    var ohlcv model.AdjustedDailyOHLCV
-    err := rows.Scan(
-      &ohlcv.Yyyymmdd,
-      &ohlcv.Code,
-      &ohlcv.OpenPrice,
-      &ohlcv.HighPrice,
-      &ohlcv.LowPrice,
-      &ohlcv.ClosePrice,
-      &ohlcv.DMAPrice5,
-      &ohlcv.DMAPrice25,
-      &ohlcv.DMAPrice75,
-      &ohlcv.VMAP,
-      &ohlcv.Volume,
-      &ohlcv.VMA5,
-      &ohlcv.VMA25,
-    )
-    if err != nil { return nil, err }
-    results = append(results, &ohlcv)
+    var code string
+    var yyyymmdd model.Date
+    if err := rows.Scan(&code, &yyyymmdd); err != nil { return nil, err }
+    result[code] = yyyymmdd
+  }
+
+  return result, rows.Err()
+}
+
+func stringsToArgs(values []string) []any {
+  args := make([]any, len(values))
+  for i, v := range values {
+    args[i] = v
   }
 
-  return results, nil
+  return args
 }
@@ -1,6 +1,8 @@
 package dao_test
 
 import (
+  "context"
+  "errors"
   "reflect"
   "testing"
 
@@ -32,7 +34,7 @@ CREATE TABLE adjusted_daily_ohlcvs (
 func NewAdjustedDailyOHLCV(overrides ...func(*model.AdjustedDailyOHLCV)) *model.AdjustedDailyOHLCV {
   floatToPointer := func(v float64) *float64 { return &v }
   o := &model.AdjustedDailyOHLCV{
-    Yyyymmdd:   "20250706",
+    Yyyymmdd:   model.MustParseDate("20250706"),
     Code:       "1234",
     OpenPrice:  floatToPointer(1000.0),
     HighPrice:  floatToPointer(1050.0),
@@ -57,32 +59,48 @@ func NewAdjustedDailyOHLCV(overrides ...func(*model.AdjustedDailyOHLCV)) *model.
 func TestAdjustedDaliyOhlcvDao_Create_Success(t *testing.T) {
   db := dao.PrepareTestDB(t)
 
-  stockDao := dao.StockDAO{DB: db}
+  stockDao := dao.NewStockDAO(db)
   stock := &model.Stock{Code: "1234", Name: "テスト会社"}
-  err := stockDao.Create(stock)
+  err := stockDao.Create(context.Background(), stock)
   if err != nil { t.Errorf("Failed to create stock record: %v", err) }
 
-  ohlcvDao := dao.AdjustedDailyOHLCVDAO{DB: db}
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(db)
 
   ohlcv := NewAdjustedDailyOHLCV()
-  err = ohlcvDao.Create(ohlcv)
+  err = ohlcvDao.Create(context.Background(), ohlcv)
   if err != nil { t.Errorf("Failed to create adjusted daily ohlcv record: %v", err) }
 }
 
+func TestAdjustedDailyOhlcvDao_Create_ZeroDate_Failure(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+
+  if err := dao.NewStockDAO(db).Create(ctx, &model.Stock{Code: "1234", Name: "テスト会社"}); err != nil { t.Fatal(err) }
+
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(db)
+  ohlcv := NewAdjustedDailyOHLCV(func(o *model.AdjustedDailyOHLCV) { o.Yyyymmdd = model.Date{} })
+  if err := ohlcvDao.Create(ctx, ohlcv); !errors.Is(err, model.ErrInvalidDate) { t.Errorf("Expected ErrInvalidDate, got %v", err) }
+  if err := ohlcvDao.UpsertPrices(ctx, ohlcv); !errors.Is(err, model.ErrInvalidDate) { t.Errorf("Expected ErrInvalidDate, got %v", err) }
+
+  count, err := ohlcvDao.Count(ctx, "")
+  if err != nil { t.Fatal(err) }
+  if count != 0 { t.Errorf("Expected nothing stored, got %d rows", count) }
+}
+
 func TestAdjustedDaliyOhlcvDao_Find_Success(t *testing.T) {
   db := dao.PrepareTestDB(t)
 
-  stockDao := dao.StockDAO{DB: db}
+  stockDao := dao.NewStockDAO(db)
   stock := &model.Stock{Code: "1234", Name: "テスト会社"}
-  err := stockDao.Create(stock)
+  err := stockDao.Create(context.Background(), stock)
   if err != nil { t.Errorf("Failed to create stock record: %v", err) }
 
-  ohlcvDao := dao.AdjustedDailyOHLCVDAO{DB: db}
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(db)
   actualOhlcv := NewAdjustedDailyOHLCV()
-  err = ohlcvDao.Create(actualOhlcv)
+  err = ohlcvDao.Create(context.Background(), actualOhlcv)
   if err != nil { t.Errorf("Failed to create adjusted daily ohlcv record: %v", err) }
 
-  expectedOhlcv, _ := ohlcvDao.Find("1234", "20250706")
+  expectedOhlcv, _ := ohlcvDao.Find(context.Background(), "1234", model.MustParseDate("20250706"))
 
   vExpected := reflect.ValueOf(expectedOhlcv).Elem()
   vActual := reflect.ValueOf(actualOhlcv).Elem()
@@ -114,23 +132,183 @@ func TestAdjustedDaliyOhlcvDao_Find_Success(t *testing.T) {
 
 func TestAdjustedDailyOhlcvDao_FindByDateRange_Success(t *testing.T) {
   db := dao.PrepareTestDB(t)
-  ohlcvDao := dao.AdjustedDailyOHLCVDAO{DB: db}
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(db)
 
-  stockDao := dao.StockDAO{DB: db}
+  stockDao := dao.NewStockDAO(db)
   stock := &model.Stock{Code: "1234", Name: "テスト会社"}
-  err := stockDao.Create(stock)
+  err := stockDao.Create(context.Background(), stock)
   if err != nil { t.Errorf("Failed to create stock record: %v", err) }
 
   path := "testdata/adjusted_daily_ohlcvs.csv"
   records, err := dao.LoadOhlcvCSV(path)
   if err != nil { t.Fatalf("Load CSV: %v", err) }
   for _, rec := range records {
-    if err := ohlcvDao.Create(rec); err != nil {
+    if err := ohlcvDao.Create(context.Background(), rec); err != nil {
       t.Fatalf("Insert AdjustedDailyOHLCV: %v", err)
     }
   }
 
-  got, err := ohlcvDao.FindByDateRange("1234", "20250702", "20250704")
+  got, err := ohlcvDao.FindByDateRange(context.Background(), "1234", model.MustParseDate("20250702"), model.MustParseDate("20250704"))
   if err != nil { t.Fatalf("FindByDateRange: %v", err) }
   if len(got) != 3 { t.Errorf("want 3 records, got %d", len(got)) }
 }
+
+// prepareMultiCodeDB loads the test CSV for 1234, and for 5678 without its
+// first 3 days so that the dates of the two codes do not fully overlap.
+func prepareMultiCodeDB(t *testing.T) *dao.AdjustedDailyOHLCVDAO {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+
+  stockDao := dao.NewStockDAO(db)
+  for _, code := range []string{"1234", "5678", "9999"} {
+    if err := stockDao.Create(ctx, &model.Stock{Code: code, Name: "テスト会社"}); err != nil { t.Fatalf("Failed to create stock record: %v", err) }
+  }
+
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(db)
+  records, err := dao.LoadOhlcvCSV("testdata/adjusted_daily_ohlcvs.csv")
+  if err != nil { t.Fatalf("Load CSV: %v", err) }
+  for i, rec := range records {
+    if err := ohlcvDao.Create(ctx, rec); err != nil { t.Fatalf("Insert AdjustedDailyOHLCV: %v", err) }
+    if i < 3 { continue }
+
+    other := *rec
+    other.Code = "5678"
+    if err := ohlcvDao.Create(ctx, &other); err != nil { t.Fatalf("Insert AdjustedDailyOHLCV: %v", err) }
+  }
+
+  return ohlcvDao
+}
+
+func TestAdjustedDailyOhlcvDao_FindByDate_Success(t *testing.T) {
+  ohlcvDao := prepareMultiCodeDB(t)
+
+  got, err := ohlcvDao.FindByDate(context.Background(), model.MustParseDate("20250704"))
+  if err != nil { t.Fatalf("FindByDate: %v", err) }
+  if len(got) != 2 { t.Fatalf("want 2 records, got %d", len(got)) }
+  if got[0].Code != "1234" || got[1].Code != "5678" { t.Errorf("Unexpected codes: %s, %s", got[0].Code, got[1].Code) }
+}
+
+func TestAdjustedDailyOhlcvDao_FindByCodesAndDateRange_Success(t *testing.T) {
+  ohlcvDao := prepareMultiCodeDB(t)
+
+  got, err := ohlcvDao.FindByCodesAndDateRange(context.Background(), []string{"1234", "5678", "9999"}, model.MustParseDate("20250701"), model.MustParseDate("20250705"))
+  if err != nil { t.Fatalf("FindByCodesAndDateRange: %v", err) }
+  if len(got["1234"]) != 5 { t.Errorf("want 5 records for 1234, got %d", len(got["1234"])) }
+  if len(got["5678"]) != 2 { t.Errorf("want 2 records for 5678, got %d", len(got["5678"])) }
+  if _, ok := got["9999"]; ok { t.Errorf("Expected no records for 9999") }
+  if got["1234"][0].Yyyymmdd.String() != "20250701" { t.Errorf("Expected: 20250701, but got: %s", got["1234"][0].Yyyymmdd) }
+}
+
+func TestAdjustedDailyOhlcvDao_FindMatrix_Success(t *testing.T) {
+  ohlcvDao := prepareMultiCodeDB(t)
+
+  matrix, err := ohlcvDao.FindMatrix(context.Background(), []string{"5678", "1234"}, model.MustParseDate("20250703"), model.MustParseDate("20250705"))
+  if err != nil { t.Fatalf("FindMatrix: %v", err) }
+
+  expectedDates := []model.Date{model.MustParseDate("20250703"), model.MustParseDate("20250704"), model.MustParseDate("20250705")}
+  if !reflect.DeepEqual(matrix.Dates, expectedDates) { t.Fatalf("got %v, want %v", matrix.Dates, expectedDates) }
+  if matrix.Bars[0][0] != nil { t.Errorf("Expected no bar for 5678 on 20250703") }
+  if matrix.Bars[0][1] == nil || matrix.Bars[0][1].Code != "1234" { t.Errorf("Expected bar of 1234 on 20250703") }
+  if matrix.Bars[2][0] == nil || matrix.Bars[2][0].Code != "5678" { t.Errorf("Expected bar of 5678 on 20250705") }
+}
+
+func TestAdjustedDailyOhlcvDao_FindLatestN_Success(t *testing.T) {
+  ohlcvDao := prepareMultiCodeDB(t)
+
+  got, err := ohlcvDao.FindLatestN(context.Background(), nil, 3)
+  if err != nil { t.Fatalf("FindLatestN: %v", err) }
+  if len(got) != 2 { t.Fatalf("want 2 codes, got %d", len(got)) }
+  for _, code := range []string{"1234", "5678"} {
+    bars := got[code]
+    if len(bars) != 3 { t.Fatalf("want 3 records for %s, got %d", code, len(bars)) }
+    if bars[0].Yyyymmdd.String() != "20250712" || bars[2].Yyyymmdd.String() != "20250714" { t.Errorf("Unexpected dates for %s: %s..%s", code, bars[0].Yyyymmdd, bars[2].Yyyymmdd) }
+  }
+
+  only, err := ohlcvDao.FindLatestN(context.Background(), []string{"5678"}, 1)
+  if err != nil { t.Fatalf("FindLatestN: %v", err) }
+  if len(only) != 1 || len(only["5678"]) != 1 { t.Errorf("Unexpected result: %v", only) }
+}
+
+func TestAdjustedDailyOhlcvDao_UpdateMovingAverages_Success(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+  if err := dao.NewStockDAO(db).Create(ctx, &model.Stock{Code: "1234", Name: "テスト会社"}); err != nil { t.Fatal(err) }
+
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(db)
+  if err := ohlcvDao.Create(ctx, NewAdjustedDailyOHLCV(func(o *model.AdjustedDailyOHLCV) { o.DMAPrice75, o.VMA25 = nil, nil })); err != nil { t.Fatal(err) }
+
+  dma75, vma25 := 980.0, 120000.0
+  update := NewAdjustedDailyOHLCV(func(o *model.AdjustedDailyOHLCV) {
+    o.ClosePrice = nil
+    o.DMAPrice75, o.VMA25 = &dma75, &vma25
+  })
+  if err := ohlcvDao.UpdateMovingAverages(ctx, update); err != nil { t.Fatal(err) }
+
+  got, err := ohlcvDao.Find(ctx, "1234", model.MustParseDate("20250706"))
+  if err != nil { t.Fatal(err) }
+  if *got.DMAPrice75 != 980 || *got.VMA25 != 120000 { t.Errorf("Unexpected averages: %v %v", *got.DMAPrice75, *got.VMA25) }
+  if got.ClosePrice == nil || *got.ClosePrice != 1020 { t.Errorf("Expected the close to be kept, got %v", got.ClosePrice) }
+}
+
+func TestAdjustedDailyOhlcvDao_LatestDates_Success(t *testing.T) {
+  ohlcvDao := prepareMultiCodeDB(t)
+  ctx := context.Background()
+
+  extra := NewAdjustedDailyOHLCV(func(o *model.AdjustedDailyOHLCV) { o.Code = "5678"; o.Yyyymmdd = model.MustParseDate("20250715") })
+  if err := ohlcvDao.Create(ctx, extra); err != nil { t.Fatal(err) }
+
+  got, err := ohlcvDao.LatestDates(ctx)
+  if err != nil { t.Fatalf("LatestDates: %v", err) }
+  expected := map[string]model.Date{"1234": model.MustParseDate("20250714"), "5678": model.MustParseDate("20250715")}
+  if !reflect.DeepEqual(got, expected) { t.Errorf("got %v, want %v", got, expected) }
+}
+
+func TestAdjustedDailyOhlcvDao_IterateByCodesAndDateRange_Break(t *testing.T) {
+  ohlcvDao := prepareMultiCodeDB(t)
+  ctx := context.Background()
+
+  count := 0
+  for ohlcv, err := range ohlcvDao.IterateByCodesAndDateRange(ctx, []string{"1234", "5678"}, model.MustParseDate("20250701"), model.MustParseDate("20250731")) {
+    if err != nil { t.Fatal(err) }
+    if ohlcv.Code != "1234" { t.Errorf("Expected 1234 first, got %s", ohlcv.Code) }
+    count++
+    if count == 2 { break }
+  }
+
+  // Rows are closed on break, so the single in-memory connection is free again.
+  if _, err := ohlcvDao.FindByDate(ctx, model.MustParseDate("20250701")); err != nil { t.Errorf("Query after break failed: %v", err) }
+}
+
+func TestAdjustedDailyOhlcvDao_IterateFiltered(t *testing.T) {
+  ohlcvDao := prepareMultiCodeDB(t)
+  ctx := context.Background()
+
+  tests := []struct {
+    name  string
+    codes []string
+    from  string
+    to    string
+    want  int
+  }{
+    {"no filter", nil, "", "", 25},
+    {"codes only", []string{"5678"}, "", "", 11},
+    {"from only", nil, "20250713", "", 4},
+    {"codes and range", []string{"1234"}, "20250702", "20250704", 3},
+  }
+
+  bound := func(s string) model.Date {
+    if s == "" { return model.Date{} }
+    return model.MustParseDate(s)
+  }
+
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      count := 0
+      for _, err := range ohlcvDao.IterateFiltered(ctx, tt.codes, bound(tt.from), bound(tt.to)) {
+        if err != nil { t.Fatal(err) }
+        count++
+      }
+      if count != tt.want { t.Errorf("want %d rows, got %d", tt.want, count) }
+    })
+  }
+}
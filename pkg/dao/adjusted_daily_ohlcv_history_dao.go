@@ -0,0 +1,23 @@
+package dao
+
+import (
+  "context"
+
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/model"
+)
+
+// AdjustedDailyOHLCVHistoryDAO reads the old values that the
+// adjusted_daily_ohlcvs_keep_history trigger saves. Nothing writes here.
+type AdjustedDailyOHLCVHistoryDAO struct {
+  Repository[model.AdjustedDailyOHLCVHistory]
+}
+
+func NewAdjustedDailyOHLCVHistoryDAO(db database.Querier) *AdjustedDailyOHLCVHistoryDAO {
+  return &AdjustedDailyOHLCVHistoryDAO{Repository[model.AdjustedDailyOHLCVHistory]{DB: db, Table: "adjusted_daily_ohlcvs_history"}}
+}
+
+// FindRevisions returns the replaced values of a bar, oldest first.
+func (dao *AdjustedDailyOHLCVHistoryDAO) FindRevisions(ctx context.Context, code string, yyyymmdd model.Date) ([]*model.AdjustedDailyOHLCVHistory, error) {
+  return dao.FindMany(ctx, "WHERE code = ? AND yyyymmdd = ? ORDER BY id", code, yyyymmdd)
+}
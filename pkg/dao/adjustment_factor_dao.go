@@ -0,0 +1,24 @@
+package dao
+
+import (
+  "context"
+
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/model"
+)
+
+type AdjustmentFactorDAO struct {
+  Repository[model.AdjustmentFactor]
+}
+
+func NewAdjustmentFactorDAO(db database.Querier) *AdjustmentFactorDAO {
+  return &AdjustmentFactorDAO{Repository[model.AdjustmentFactor]{DB: db, Table: "adjustment_factors"}}
+}
+
+func (dao *AdjustmentFactorDAO) Create(ctx context.Context, factor *model.AdjustmentFactor) error {
+  return dao.Upsert(ctx, factor)
+}
+
+func (dao *AdjustmentFactorDAO) FindByCode(ctx context.Context, code string) ([]*model.AdjustmentFactor, error) {
+  return dao.FindMany(ctx, "WHERE code = ? ORDER BY yyyymmdd", code)
+}
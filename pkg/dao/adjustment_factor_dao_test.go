@@ -0,0 +1,38 @@
+package dao_test
+
+import (
+  "context"
+  "testing"
+
+  _ "github.com/mattn/go-sqlite3"
+
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/model"
+)
+
+func TestAdjustmentFactorDao_Create_FindByCode_Success(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  stockDao := dao.NewStockDAO(db)
+  for _, code := range []string{"1234", "9999"} {
+    if err := stockDao.Create(context.Background(), &model.Stock{Code: code, Name: "テスト会社"}); err != nil { t.Fatalf("Failed to create stock record: %v", err) }
+  }
+
+  factorDao := dao.NewAdjustmentFactorDAO(db)
+
+  factors := []*model.AdjustmentFactor{
+    {Yyyymmdd: model.MustParseDate("20240328"), Code: "1234", Factor: 0.5},
+    {Yyyymmdd: model.MustParseDate("20230101"), Code: "1234", Factor: 0.25},
+    {Yyyymmdd: model.MustParseDate("20240328"), Code: "9999", Factor: 0.1},
+  }
+  for _, factor := range factors {
+    if err := factorDao.Create(context.Background(), factor); err != nil { t.Fatalf("Failed to create adjustment factor: %v", err) }
+  }
+  // Upsert overwrites the factor of the same day.
+  if err := factorDao.Create(context.Background(), &model.AdjustmentFactor{Yyyymmdd: model.MustParseDate("20240328"), Code: "1234", Factor: 0.2}); err != nil { t.Fatal(err) }
+
+  got, err := factorDao.FindByCode(context.Background(), "1234")
+  if err != nil { t.Fatal(err) }
+  if len(got) != 2 { t.Fatalf("want 2 records, got %d", len(got)) }
+  if got[0].Yyyymmdd.String() != "20230101" { t.Errorf("Expected: 20230101, but got: %s", got[0].Yyyymmdd) }
+  if got[1].Factor != 0.2 { t.Errorf("Expected: 0.2, but got: %f", got[1].Factor) }
+}
@@ -0,0 +1,47 @@
+package dao
+
+import (
+  "context"
+  "database/sql"
+  "errors"
+
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/model"
+)
+
+type AlertDAO struct {
+  Repository[model.Alert]
+}
+
+func NewAlertDAO(db database.Querier) *AlertDAO {
+  return &AlertDAO{Repository[model.Alert]{DB: db, Table: "alerts"}}
+}
+
+// Record inserts alert and sets its ID. It reports false and leaves the ID
+// zero when the rule already triggered for the code and date.
+func (dao *AlertDAO) Record(ctx context.Context, alert *model.Alert) (bool, error) {
+  err := dao.DB.QueryRowContext(
+    ctx,
+    `
+    INSERT INTO alerts (rule_id, code, yyyymmdd, message, triggered_at) VALUES (?, ?, ?, ?, ?)
+    ON CONFLICT(rule_id, code, yyyymmdd) DO NOTHING
+    RETURNING id
+    `,
+    alert.RuleID,
+    alert.Code,
+    alert.Yyyymmdd,
+    alert.Message,
+    alert.TriggeredAt,
+  ).Scan(&alert.ID)
+  if errors.Is(err, sql.ErrNoRows) { return false, nil }
+  if err != nil { return false, err }
+
+  return true, nil
+}
+
+// FindRecent returns the latest alerts, newest first. An empty code returns
+// those of every code.
+func (dao *AlertDAO) FindRecent(ctx context.Context, code string, limit int) ([]*model.Alert, error) {
+  if code == "" { return dao.FindMany(ctx, "ORDER BY yyyymmdd DESC, id DESC LIMIT ?", limit) }
+  return dao.FindMany(ctx, "WHERE code = ? ORDER BY yyyymmdd DESC, id DESC LIMIT ?", code, limit)
+}
@@ -0,0 +1,62 @@
+package dao_test
+
+import (
+  "context"
+  "testing"
+  "time"
+
+  _ "github.com/mattn/go-sqlite3"
+
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/model"
+)
+
+func TestAlertRuleDao_FindEnabled_Success(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+  ruleDao := dao.NewAlertRuleDAO(db)
+
+  rules := []*model.AlertRule{
+    {Code: "5253", Expression: "close crosses_above 2200", Enabled: true},
+    {Code: "7203", Expression: "close > 3000", Enabled: true},
+    {Expression: "close below dma75", Enabled: true},
+    {Code: "5253", Expression: "volume > 3x vma25", Enabled: true},
+  }
+  for _, rule := range rules {
+    rule.CreatedAt = time.Now()
+    if err := ruleDao.Create(ctx, rule); err != nil { t.Fatalf("Failed to create rule: %v", err) }
+  }
+  if n, err := ruleDao.SetEnabled(ctx, rules[3].ID, false); err != nil || n != 1 { t.Fatalf("Failed to disable: %d %v", n, err) }
+
+  enabled, err := ruleDao.FindEnabled(ctx, "5253")
+  if err != nil { t.Fatal(err) }
+  if len(enabled) != 2 || enabled[0].ID != rules[0].ID || enabled[1].ID != rules[2].ID { t.Errorf("Unexpected rules: %+v", enabled) }
+
+  got, err := ruleDao.Find(ctx, rules[3].ID)
+  if err != nil { t.Fatal(err) }
+  if got.Enabled || got.Expression != "volume > 3x vma25" { t.Errorf("Unexpected rule: %+v", got) }
+}
+
+func TestAlertDao_Record_Once(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+
+  rule := &model.AlertRule{Code: "5253", Expression: "close > 2200", Enabled: true, CreatedAt: time.Now()}
+  if err := dao.NewAlertRuleDAO(db).Create(ctx, rule); err != nil { t.Fatal(err) }
+
+  alertDao := dao.NewAlertDAO(db)
+  first := &model.Alert{RuleID: rule.ID, Code: "5253", Yyyymmdd: model.MustParseDate("20250718"), Message: "first", TriggeredAt: time.Now()}
+  if recorded, err := alertDao.Record(ctx, first); err != nil || !recorded || first.ID == 0 { t.Fatalf("Failed to record: %t %v", recorded, err) }
+  again := &model.Alert{RuleID: rule.ID, Code: "5253", Yyyymmdd: model.MustParseDate("20250718"), Message: "again", TriggeredAt: time.Now()}
+  if recorded, err := alertDao.Record(ctx, again); err != nil || recorded || again.ID != 0 { t.Errorf("Expected the same day to be skipped: %t %v", recorded, err) }
+  next := &model.Alert{RuleID: rule.ID, Code: "5253", Yyyymmdd: model.MustParseDate("20250722"), Message: "next", TriggeredAt: time.Now()}
+  if _, err := alertDao.Record(ctx, next); err != nil { t.Fatal(err) }
+
+  recent, err := alertDao.FindRecent(ctx, "5253", 10)
+  if err != nil { t.Fatal(err) }
+  if len(recent) != 2 || recent[0].Message != "next" || recent[1].Message != "first" { t.Errorf("Unexpected alerts: %+v", recent) }
+
+  // Deleting the rule deletes its alerts.
+  if _, err := dao.NewAlertRuleDAO(db).Delete(ctx, rule.ID); err != nil { t.Fatal(err) }
+  if count, _ := alertDao.Count(ctx, ""); count != 0 { t.Errorf("want no alerts, got %d", count) }
+}
@@ -0,0 +1,54 @@
+package dao
+
+import (
+  "context"
+
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/model"
+)
+
+type AlertRuleDAO struct {
+  Repository[model.AlertRule]
+}
+
+func NewAlertRuleDAO(db database.Querier) *AlertRuleDAO {
+  return &AlertRuleDAO{Repository[model.AlertRule]{DB: db, Table: "alert_rules"}}
+}
+
+// Create inserts rule and sets its ID, which the database generates.
+func (dao *AlertRuleDAO) Create(ctx context.Context, rule *model.AlertRule) error {
+  return dao.DB.QueryRowContext(
+    ctx,
+    "INSERT INTO alert_rules (code, expression, note, enabled, created_at) VALUES (?, ?, ?, ?, ?) RETURNING id",
+    rule.Code,
+    rule.Expression,
+    rule.Note,
+    rule.Enabled,
+    rule.CreatedAt,
+  ).Scan(&rule.ID)
+}
+
+func (dao *AlertRuleDAO) Find(ctx context.Context, id int64) (*model.AlertRule, error) {
+  return dao.Repository.Find(ctx, id)
+}
+
+func (dao *AlertRuleDAO) FindAll(ctx context.Context) ([]*model.AlertRule, error) {
+  return dao.FindMany(ctx, "ORDER BY id")
+}
+
+// FindEnabled returns the enabled rules of the code and those of every code.
+func (dao *AlertRuleDAO) FindEnabled(ctx context.Context, code string) ([]*model.AlertRule, error) {
+  return dao.FindMany(ctx, "WHERE enabled AND (code = ? OR code = '') ORDER BY id", code)
+}
+
+func (dao *AlertRuleDAO) SetEnabled(ctx context.Context, id int64, enabled bool) (int64, error) {
+  res, err := dao.DB.ExecContext(ctx, "UPDATE alert_rules SET enabled = ? WHERE id = ?", enabled, id)
+  if err != nil { return 0, err }
+
+  return res.RowsAffected()
+}
+
+// Delete removes the rule and, by the foreign key, its alerts.
+func (dao *AlertRuleDAO) Delete(ctx context.Context, id int64) (int64, error) {
+  return dao.Repository.Delete(ctx, id)
+}
@@ -0,0 +1,55 @@
+package dao
+
+import (
+  "context"
+
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/model"
+)
+
+type ImportDAO struct {
+  Repository[model.Import]
+}
+
+func NewImportDAO(db database.Querier) *ImportDAO {
+  return &ImportDAO{Repository[model.Import]{DB: db, Table: "imports"}}
+}
+
+// Create inserts imp and sets its ID, which the database generates.
+func (dao *ImportDAO) Create(ctx context.Context, imp *model.Import) error {
+  return dao.DB.QueryRowContext(
+    ctx,
+    `
+    INSERT INTO imports (
+      source,
+      file_path,
+      file_hash,
+      code,
+      imported_at,
+      rows_read,
+      rows_written
+    ) VALUES (?, ?, ?, ?, ?, ?, ?)
+    RETURNING id
+    `,
+    imp.Source,
+    imp.FilePath,
+    imp.FileHash,
+    imp.Code,
+    imp.ImportedAt,
+    imp.RowsRead,
+    imp.RowsWritten,
+  ).Scan(&imp.ID)
+}
+
+func (dao *ImportDAO) SetRowCounts(ctx context.Context, id int64, rowsRead int, rowsWritten int) error {
+  _, err := dao.DB.ExecContext(ctx, "UPDATE imports SET rows_read = ?, rows_written = ? WHERE id = ?", rowsRead, rowsWritten, id)
+  return err
+}
+
+func (dao *ImportDAO) Find(ctx context.Context, id int64) (*model.Import, error) {
+  return dao.Repository.Find(ctx, id)
+}
+
+func (dao *ImportDAO) FindByCode(ctx context.Context, code string) ([]*model.Import, error) {
+  return dao.FindMany(ctx, "WHERE code = ? ORDER BY id", code)
+}
@@ -0,0 +1,96 @@
+package dao_test
+
+import (
+  "context"
+  "testing"
+  "time"
+
+  _ "github.com/mattn/go-sqlite3"
+
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/model"
+)
+
+func TestImportDao_Create_Find_Success(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+  importDao := dao.NewImportDAO(db)
+
+  importedAt := time.Date(2025, 7, 10, 9, 0, 0, 0, time.UTC)
+  first := &model.Import{Source: "csv", FilePath: "/data/1234.csv", FileHash: "abc", Code: "1234", ImportedAt: importedAt, RowsRead: 10}
+  second := &model.Import{Source: "jquants", Code: "1234", ImportedAt: importedAt}
+  for _, imp := range []*model.Import{first, second} {
+    if err := importDao.Create(ctx, imp); err != nil { t.Fatalf("Failed to create import: %v", err) }
+  }
+  if first.ID == 0 || second.ID <= first.ID { t.Fatalf("IDs not assigned in order: %d, %d", first.ID, second.ID) }
+
+  if err := importDao.SetRowCounts(ctx, first.ID, 10, 9); err != nil { t.Fatal(err) }
+
+  got, err := importDao.Find(ctx, first.ID)
+  if err != nil { t.Fatal(err) }
+  if got.FilePath != "/data/1234.csv" || got.FileHash != "abc" || got.RowsWritten != 9 { t.Errorf("Unexpected import: %+v", got) }
+  if !got.ImportedAt.Equal(importedAt) { t.Errorf("want %v, got %v", importedAt, got.ImportedAt) }
+
+  imports, err := importDao.FindByCode(ctx, "1234")
+  if err != nil { t.Fatal(err) }
+  if len(imports) != 2 || imports[1].Source != "jquants" { t.Errorf("Unexpected imports: %+v", imports) }
+}
+
+func TestAdjustedDailyOhlcvHistory_KeepsReplacedValues(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+
+  if err := dao.NewStockDAO(db).Create(ctx, &model.Stock{Code: "1234", Name: "テスト会社"}); err != nil { t.Fatal(err) }
+
+  importDao := dao.NewImportDAO(db)
+  var imports []*model.Import
+  for _, path := range []string{"first.csv", "second.csv", "third.csv"} {
+    imp := &model.Import{Source: "csv", FilePath: path, Code: "1234", ImportedAt: time.Now()}
+    if err := importDao.Create(ctx, imp); err != nil { t.Fatal(err) }
+    imports = append(imports, imp)
+  }
+
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(db)
+  write := func(imp *model.Import, closePrice float64) {
+    ohlcv := NewAdjustedDailyOHLCV(func(o *model.AdjustedDailyOHLCV) {
+      o.ClosePrice = &closePrice
+      o.ImportID = &imp.ID
+    })
+    if err := ohlcvDao.Create(ctx, ohlcv); err != nil { t.Fatalf("Upsert AdjustedDailyOHLCV: %v", err) }
+  }
+  write(imports[0], 1020)
+  write(imports[1], 1030)
+  // The same values again only move import_id.
+  write(imports[2], 1030)
+
+  current, err := ohlcvDao.Find(ctx, "1234", model.MustParseDate("20250706"))
+  if err != nil { t.Fatal(err) }
+  if current.ImportID == nil || *current.ImportID != imports[2].ID { t.Errorf("want import %d, got %v", imports[2].ID, current.ImportID) }
+
+  revisions, err := dao.NewAdjustedDailyOHLCVHistoryDAO(db).FindRevisions(ctx, "1234", model.MustParseDate("20250706"))
+  if err != nil { t.Fatal(err) }
+  if len(revisions) != 1 { t.Fatalf("want 1 revision, got %d", len(revisions)) }
+
+  rev := revisions[0]
+  if *rev.ClosePrice != 1020 { t.Errorf("want old close 1020, got %f", *rev.ClosePrice) }
+  if *rev.ImportID != imports[0].ID || *rev.ReplacedByImportID != imports[1].ID { t.Errorf("Unexpected imports: %d -> %d", *rev.ImportID, *rev.ReplacedByImportID) }
+  if rev.ReplacedAt.IsZero() { t.Error("replaced_at is not set") }
+}
+
+func TestAdjustedDailyOhlcvHistory_UpsertPrices(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+
+  if err := dao.NewStockDAO(db).Create(ctx, &model.Stock{Code: "1234", Name: "テスト会社"}); err != nil { t.Fatal(err) }
+
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(db)
+  if err := ohlcvDao.Create(ctx, NewAdjustedDailyOHLCV()); err != nil { t.Fatal(err) }
+
+  closePrice := 999.0
+  if err := ohlcvDao.UpsertPrices(ctx, NewAdjustedDailyOHLCV(func(o *model.AdjustedDailyOHLCV) { o.ClosePrice = &closePrice })); err != nil { t.Fatal(err) }
+
+  revisions, err := dao.NewAdjustedDailyOHLCVHistoryDAO(db).FindRevisions(ctx, "1234", model.MustParseDate("20250706"))
+  if err != nil { t.Fatal(err) }
+  if len(revisions) != 1 || *revisions[0].ClosePrice != 1020 { t.Fatalf("Unexpected revisions: %+v", revisions) }
+  if revisions[0].ImportID != nil { t.Errorf("Untracked writes have no import, got %d", *revisions[0].ImportID) }
+}
@@ -0,0 +1,73 @@
+package dao
+
+import (
+  "context"
+  "time"
+
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/model"
+)
+
+type JobDAO struct {
+  Repository[model.Job]
+}
+
+func NewJobDAO(db database.Querier) *JobDAO {
+  return &JobDAO{Repository[model.Job]{DB: db, Table: "jobs"}}
+}
+
+// Start inserts job and sets its ID, which the database generates.
+func (dao *JobDAO) Start(ctx context.Context, job *model.Job) error {
+  return dao.DB.QueryRowContext(
+    ctx,
+    "INSERT INTO jobs (name, scheduled_at, started_at, status, summary, error) VALUES (?, ?, ?, ?, ?, ?) RETURNING id",
+    job.Name,
+    job.ScheduledAt,
+    job.StartedAt,
+    job.Status,
+    job.Summary,
+    job.Error,
+  ).Scan(&job.ID)
+}
+
+// Finish writes the outcome of job: its status, summary, error and
+// FinishedAt.
+func (dao *JobDAO) Finish(ctx context.Context, job *model.Job) error {
+  _, err := dao.DB.ExecContext(
+    ctx,
+    "UPDATE jobs SET finished_at = ?, status = ?, summary = ?, error = ? WHERE id = ?",
+    job.FinishedAt,
+    job.Status,
+    job.Summary,
+    job.Error,
+    job.ID,
+  )
+
+  return err
+}
+
+// FindLast returns the latest run of the job with the status. It returns
+// sql.ErrNoRows when there is none.
+func (dao *JobDAO) FindLast(ctx context.Context, name string, status string) (*model.Job, error) {
+  var job model.Job
+  err := dao.DB.QueryRowContext(ctx, dao.SelectSQL()+" WHERE name = ? AND status = ? ORDER BY started_at DESC, id DESC LIMIT 1", name, status).Scan(dao.pointers(&job)...)
+  if err != nil { return nil, err }
+
+  return &job, nil
+}
+
+// FindRecent returns the latest runs, newest first. An empty name returns
+// those of every job.
+func (dao *JobDAO) FindRecent(ctx context.Context, name string, limit int) ([]*model.Job, error) {
+  if name == "" { return dao.FindMany(ctx, "ORDER BY started_at DESC, id DESC LIMIT ?", limit) }
+  return dao.FindMany(ctx, "WHERE name = ? ORDER BY started_at DESC, id DESC LIMIT ?", name, limit)
+}
+
+// FailRunning marks the runs left running, by a daemon that stopped
+// without finishing them, as failed with the message.
+func (dao *JobDAO) FailRunning(ctx context.Context, message string, now time.Time) (int64, error) {
+  res, err := dao.DB.ExecContext(ctx, "UPDATE jobs SET finished_at = ?, status = ?, error = ? WHERE status = ?", now, model.JobFailed, message, model.JobRunning)
+  if err != nil { return 0, err }
+
+  return res.RowsAffected()
+}
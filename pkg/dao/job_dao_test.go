@@ -0,0 +1,47 @@
+package dao_test
+
+import (
+  "context"
+  "database/sql"
+  "errors"
+  "testing"
+  "time"
+
+  _ "github.com/mattn/go-sqlite3"
+
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/model"
+)
+
+func TestJobDao_StartFinish_Success(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+  jobDao := dao.NewJobDAO(db)
+
+  if _, err := jobDao.FindLast(ctx, "eod", model.JobSucceeded); !errors.Is(err, sql.ErrNoRows) { t.Fatalf("Expected sql.ErrNoRows, got %v", err) }
+
+  scheduledAt := time.Date(2025, time.July, 18, 15, 45, 0, 0, time.UTC)
+  jobs := []*model.Job{
+    {Name: "eod", ScheduledAt: scheduledAt, StartedAt: scheduledAt, Status: model.JobRunning},
+    {Name: "eod", ScheduledAt: scheduledAt.AddDate(0, 0, 3), StartedAt: scheduledAt.AddDate(0, 0, 3), Status: model.JobRunning},
+    {Name: "weekly", ScheduledAt: scheduledAt, StartedAt: scheduledAt, Status: model.JobRunning},
+  }
+  for _, job := range jobs {
+    if err := jobDao.Start(ctx, job); err != nil || job.ID == 0 { t.Fatalf("Failed to start: %v", err) }
+  }
+
+  finishedAt := scheduledAt.Add(time.Minute)
+  jobs[0].FinishedAt, jobs[0].Status, jobs[0].Summary = &finishedAt, model.JobSucceeded, "import: 3 files"
+  if err := jobDao.Finish(ctx, jobs[0]); err != nil { t.Fatal(err) }
+
+  last, err := jobDao.FindLast(ctx, "eod", model.JobSucceeded)
+  if err != nil { t.Fatal(err) }
+  if last.ID != jobs[0].ID || last.Summary != "import: 3 files" || last.FinishedAt == nil || !last.FinishedAt.Equal(finishedAt) { t.Errorf("Unexpected job: %+v", last) }
+
+  if n, err := jobDao.FailRunning(ctx, "interrupted", finishedAt); err != nil || n != 2 { t.Fatalf("Failed to fail running jobs: %d %v", n, err) }
+
+  recent, err := jobDao.FindRecent(ctx, "eod", 10)
+  if err != nil { t.Fatal(err) }
+  if len(recent) != 2 || recent[0].ID != jobs[1].ID { t.Fatalf("Unexpected jobs: %+v", recent) }
+  if recent[0].Status != model.JobFailed || recent[0].Error != "interrupted" || recent[0].FinishedAt == nil { t.Errorf("Unexpected job: %+v", recent[0]) }
+}
@@ -0,0 +1,68 @@
+package dao_test
+
+import (
+  "context"
+  "testing"
+
+  _ "github.com/lib/pq"
+
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/model"
+)
+
+// These tests run the DAOs against PostgreSQL. They are skipped unless
+// DUNN_TEST_POSTGRES_DSN points at a running server.
+
+func TestPostgres_StockDao_Success(t *testing.T) {
+  db := dao.PreparePostgresTestDB(t)
+  ctx := context.Background()
+  stockDao := dao.NewStockDAO(db)
+
+  if err := stockDao.Create(ctx, &model.Stock{Code: "1234", Name: "テスト会社"}); err != nil { t.Fatalf("Failed to create stock record: %v", err) }
+
+  stock, err := stockDao.Find(ctx, "1234")
+  if err != nil { t.Fatal(err) }
+  if stock.Name != "テスト会社" { t.Errorf("got %s, want テスト会社", stock.Name) }
+}
+
+func TestPostgres_AdjustedDailyOhlcvDao_Success(t *testing.T) {
+  db := dao.PreparePostgresTestDB(t)
+  ctx := context.Background()
+
+  stockDao := dao.NewStockDAO(db)
+  if err := stockDao.Create(ctx, &model.Stock{Code: "1234", Name: "テスト会社"}); err != nil { t.Fatalf("Failed to create stock record: %v", err) }
+
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(db)
+  records, err := dao.LoadOhlcvCSV("testdata/adjusted_daily_ohlcvs.csv")
+  if err != nil { t.Fatalf("Load CSV: %v", err) }
+  for _, rec := range records {
+    if err := ohlcvDao.Create(ctx, rec); err != nil { t.Fatalf("Insert AdjustedDailyOHLCV: %v", err) }
+  }
+
+  // ON CONFLICT ... excluded behaves the same as on SQLite.
+  updated := NewAdjustedDailyOHLCV(func(o *model.AdjustedDailyOHLCV) { o.Yyyymmdd = model.MustParseDate("20250702") })
+  if err := ohlcvDao.Create(ctx, updated); err != nil { t.Fatalf("Upsert AdjustedDailyOHLCV: %v", err) }
+
+  got, err := ohlcvDao.Find(ctx, "1234", model.MustParseDate("20250702"))
+  if err != nil { t.Fatal(err) }
+  if *got.ClosePrice != *updated.ClosePrice { t.Errorf("got %f, want %f", *got.ClosePrice, *updated.ClosePrice) }
+
+  ranged, err := ohlcvDao.FindByDateRange(ctx, "1234", model.MustParseDate("20250702"), model.MustParseDate("20250704"))
+  if err != nil { t.Fatalf("FindByDateRange: %v", err) }
+  if len(ranged) != 3 { t.Errorf("want 3 records, got %d", len(ranged)) }
+}
+
+func TestPostgres_WithTx_Rollback(t *testing.T) {
+  db := dao.PreparePostgresTestDB(t)
+  ctx := context.Background()
+
+  _ = db.WithTx(ctx, func(tx database.Querier) error {
+    stockDao := dao.NewStockDAO(tx)
+    if err := stockDao.Create(ctx, &model.Stock{Code: "1234", Name: "テスト会社"}); err != nil { return err }
+    return context.Canceled
+  })
+
+  stockDao := dao.NewStockDAO(db)
+  if _, err := stockDao.Find(ctx, "1234"); err == nil { t.Errorf("Expected the stock to be rolled back") }
+}
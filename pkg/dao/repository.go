@@ -0,0 +1,228 @@
+package dao
+
+import (
+  "context"
+  "database/sql"
+  "fmt"
+  "iter"
+  "reflect"
+  "strings"
+  "sync"
+
+  "dunn-finance/pkg/database"
+)
+
+// Repository generates the SQL for a model struct from its db tags and scans
+// rows back into it. Fields tagged `db:"name,pk"` form the primary key, in
+// field order. Fields without a db tag are ignored.
+//
+//   type Stock struct {
+//     Code string `db:"code,pk"`
+//     Name string `db:"name"`
+//   }
+type Repository[T any] struct {
+  DB    database.Querier
+  Table string
+}
+
+type column struct {
+  name  string
+  index []int
+  pk    bool
+}
+
+type tableInfo struct {
+  columns []column
+}
+
+var tableInfos sync.Map // reflect.Type -> *tableInfo
+
+func infoOf[T any]() *tableInfo {
+  typ := reflect.TypeOf((*T)(nil)).Elem()
+  if info, ok := tableInfos.Load(typ); ok { return info.(*tableInfo) }
+
+  info := &tableInfo{}
+  for i := 0; i < typ.NumField(); i++ {
+    field := typ.Field(i)
+    tag, ok := field.Tag.Lookup("db")
+    if !ok || tag == "-" { continue }
+
+    parts := strings.Split(tag, ",")
+    col := column{name: parts[0], index: field.Index}
+    for _, opt := range parts[1:] {
+      if opt == "pk" { col.pk = true }
+    }
+    info.columns = append(info.columns, col)
+  }
+  tableInfos.Store(typ, info)
+
+  return info
+}
+
+// Columns returns the column names in field order.
+func (r *Repository[T]) Columns() []string {
+  var names []string
+  for _, col := range infoOf[T]().columns {
+    names = append(names, col.name)
+  }
+
+  return names
+}
+
+func (r *Repository[T]) keyColumns() []string {
+  var names []string
+  for _, col := range infoOf[T]().columns {
+    if col.pk { names = append(names, col.name) }
+  }
+
+  return names
+}
+
+// SelectSQL is "SELECT <columns> FROM <table>", for queries that the
+// generated methods do not cover. Scan the result with ScanRows.
+func (r *Repository[T]) SelectSQL() string {
+  return fmt.Sprintf("SELECT %s FROM %s", strings.Join(r.Columns(), ", "), r.Table)
+}
+
+func (r *Repository[T]) Insert(ctx context.Context, m *T) error {
+  _, err := r.DB.ExecContext(ctx, r.insertSQL(), r.values(m)...)
+  return err
+}
+
+// Upsert inserts m, or updates every non-key column of the existing row.
+func (r *Repository[T]) Upsert(ctx context.Context, m *T) error {
+  var sets []string
+  for _, col := range infoOf[T]().columns {
+    if col.pk { continue }
+    sets = append(sets, fmt.Sprintf("%s = excluded.%s", col.name, col.name))
+  }
+
+  query := fmt.Sprintf("%s ON CONFLICT(%s) DO UPDATE SET %s", r.insertSQL(), strings.Join(r.keyColumns(), ", "), strings.Join(sets, ", "))
+  _, err := r.DB.ExecContext(ctx, query, r.values(m)...)
+
+  return err
+}
+
+// Find returns the row with the primary key values in field order.
+// It returns sql.ErrNoRows when there is none.
+func (r *Repository[T]) Find(ctx context.Context, keys ...any) (*T, error) {
+  where, err := r.keyWhere(keys)
+  if err != nil { return nil, err }
+
+  row := r.DB.QueryRowContext(ctx, r.SelectSQL()+" WHERE "+where, keys...)
+  var m T
+  if err := row.Scan(r.pointers(&m)...); err != nil { return nil, err }
+
+  return &m, nil
+}
+
+// FindMany runs SELECT with the clause appended, e.g.
+// FindMany(ctx, "WHERE code = ? ORDER BY yyyymmdd", code).
+func (r *Repository[T]) FindMany(ctx context.Context, clause string, args ...any) ([]*T, error) {
+  rows, err := r.DB.QueryContext(ctx, r.SelectSQL()+" "+clause, args...)
+  if err != nil { return nil, err }
+
+  return r.ScanRows(rows)
+}
+
+func (r *Repository[T]) Delete(ctx context.Context, keys ...any) (int64, error) {
+  where, err := r.keyWhere(keys)
+  if err != nil { return 0, err }
+
+  res, err := r.DB.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", r.Table, where), keys...)
+  if err != nil { return 0, err }
+
+  return res.RowsAffected()
+}
+
+// Count runs SELECT COUNT(*) with the clause appended, e.g. "WHERE code = ?".
+func (r *Repository[T]) Count(ctx context.Context, clause string, args ...any) (int64, error) {
+  var count int64
+  err := r.DB.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s %s", r.Table, clause), args...).Scan(&count)
+
+  return count, err
+}
+
+// Iterate is FindMany without loading every row into memory. Rows are closed
+// when the loop ends, including on break.
+//
+//   for ohlcv, err := range repo.Iterate(ctx, "WHERE code = ?", code) { ... }
+func (r *Repository[T]) Iterate(ctx context.Context, clause string, args ...any) iter.Seq2[*T, error] {
+  return func(yield func(*T, error) bool) {
+    rows, err := r.DB.QueryContext(ctx, r.SelectSQL()+" "+clause, args...)
+    if err != nil {
+      yield(nil, err)
+      return
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+      var m T
+      if err := rows.Scan(r.pointers(&m)...); err != nil {
+        yield(nil, err)
+        return
+      }
+      if !yield(&m, nil) { return }
+    }
+    if err := rows.Err(); err != nil { yield(nil, err) }
+  }
+}
+
+// ScanRows scans rows selected with SelectSQL and closes them.
+func (r *Repository[T]) ScanRows(rows *sql.Rows) ([]*T, error) {
+  defer rows.Close()
+
+  var results []*T
+  for rows.Next() {
+    var m T
+    if err := rows.Scan(r.pointers(&m)...); err != nil { return nil, err }
+    results = append(results, &m)
+  }
+
+  return results, rows.Err()
+}
+
+func (r *Repository[T]) insertSQL() string {
+  columns := r.Columns()
+
+  return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", r.Table, strings.Join(columns, ", "), Placeholders(len(columns)))
+}
+
+// Placeholders returns "?, ?, ?" for an IN clause of n values.
+func Placeholders(n int) string {
+  return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func (r *Repository[T]) keyWhere(keys []any) (string, error) {
+  keyColumns := r.keyColumns()
+  if len(keys) != len(keyColumns) { return "", fmt.Errorf("%s: want %d key values (%v), got %d", r.Table, len(keyColumns), keyColumns, len(keys)) }
+
+  conditions := make([]string, len(keyColumns))
+  for i, name := range keyColumns {
+    conditions[i] = name + " = ?"
+  }
+
+  return strings.Join(conditions, " AND "), nil
+}
+
+func (r *Repository[T]) values(m *T) []any {
+  v := reflect.ValueOf(m).Elem()
+  var values []any
+  for _, col := range infoOf[T]().columns {
+    values = append(values, v.FieldByIndex(col.index).Interface())
+  }
+
+  return values
+}
+
+// pointers returns the scan destinations. Pointer fields such as *float64
+// are scanned as **float64, which database/sql sets to nil on NULL.
+func (r *Repository[T]) pointers(m *T) []any {
+  v := reflect.ValueOf(m).Elem()
+  var pointers []any
+  for _, col := range infoOf[T]().columns {
+    pointers = append(pointers, v.FieldByIndex(col.index).Addr().Interface())
+  }
+
+  return pointers
+}
@@ -0,0 +1,72 @@
+package dao_test
+
+import (
+  "context"
+  "database/sql"
+  "errors"
+  "reflect"
+  "testing"
+
+  _ "github.com/mattn/go-sqlite3"
+
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/model"
+)
+
+func TestRepository_Columns(t *testing.T) {
+  repo := &dao.Repository[model.AdjustedDailyOHLCV]{Table: "adjusted_daily_ohlcvs"}
+
+  expected := []string{
+    "yyyymmdd", "code", "open_price", "high_price", "low_price", "close_price",
+    "dma_price_5", "dma_price_25", "dma_price_75", "vmap", "volume", "vma_5", "vma_25", "import_id",
+  }
+  if actual := repo.Columns(); !reflect.DeepEqual(actual, expected) { t.Errorf("got %v, want %v", actual, expected) }
+}
+
+func TestRepository_CRUD_Success(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+  if err := dao.NewStockDAO(db).Create(ctx, &model.Stock{Code: "1234", Name: "テスト会社"}); err != nil { t.Fatal(err) }
+
+  repo := &dao.Repository[model.AdjustedDailyOHLCV]{DB: db, Table: "adjusted_daily_ohlcvs"}
+
+  // Nil pointer fields round-trip as NULL.
+  first := NewAdjustedDailyOHLCV(func(o *model.AdjustedDailyOHLCV) { o.Yyyymmdd = model.MustParseDate("20250701"); o.DMAPrice75 = nil })
+  second := NewAdjustedDailyOHLCV(func(o *model.AdjustedDailyOHLCV) { o.Yyyymmdd = model.MustParseDate("20250702") })
+  for _, o := range []*model.AdjustedDailyOHLCV{first, second} {
+    if err := repo.Upsert(ctx, o); err != nil { t.Fatalf("Upsert: %v", err) }
+  }
+
+  found, err := repo.Find(ctx, "20250701", "1234")
+  if err != nil { t.Fatalf("Find: %v", err) }
+  if found.DMAPrice75 != nil { t.Errorf("Expected: nil, but got: %f", *found.DMAPrice75) }
+  if *found.ClosePrice != *first.ClosePrice { t.Errorf("got %f, want %f", *found.ClosePrice, *first.ClosePrice) }
+
+  updatedClose := 2000.0
+  first.ClosePrice = &updatedClose
+  if err := repo.Upsert(ctx, first); err != nil { t.Fatalf("Upsert: %v", err) }
+
+  many, err := repo.FindMany(ctx, "WHERE code = ? ORDER BY yyyymmdd DESC", "1234")
+  if err != nil { t.Fatalf("FindMany: %v", err) }
+  if len(many) != 2 { t.Fatalf("want 2 records, got %d", len(many)) }
+  if many[1].Yyyymmdd.String() != "20250701" || *many[1].ClosePrice != updatedClose { t.Errorf("Unexpected record: %+v", *many[1]) }
+
+  count, err := repo.Count(ctx, "WHERE code = ?", "1234")
+  if err != nil { t.Fatalf("Count: %v", err) }
+  if count != 2 { t.Errorf("want 2, got %d", count) }
+
+  deleted, err := repo.Delete(ctx, "20250701", "1234")
+  if err != nil { t.Fatalf("Delete: %v", err) }
+  if deleted != 1 { t.Errorf("want 1 deleted row, got %d", deleted) }
+
+  _, err = repo.Find(ctx, "20250701", "1234")
+  if !errors.Is(err, sql.ErrNoRows) { t.Errorf("Expected sql.ErrNoRows, got %v", err) }
+}
+
+func TestRepository_Find_Failure(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  repo := &dao.Repository[model.AdjustedDailyOHLCV]{DB: db, Table: "adjusted_daily_ohlcvs"}
+
+  _, err := repo.Find(context.Background(), "20250701")
+  if err == nil { t.Errorf("Expected error for missing key value, got nil") }
+}
@@ -1,29 +1,38 @@
 package dao
 
 import (
+  "context"
+
   "dunn-finance/pkg/model"
   "dunn-finance/pkg/database"
 )
 
 type StockDAO struct {
-  DB database.DBConnector
+  Repository[model.Stock]
 }
 
-func (dao *StockDAO) Create(stock *model.Stock) error {
-  _, err := dao.DB.Exec(
-    "INSERT INTO stocks (code, name) VALUES (?, ?)",
-    stock.Code, stock.Name,
-  )
+// NewStockDAO reads and writes the codes table of configs/sql.
+func NewStockDAO(db database.Querier) *StockDAO {
+  return &StockDAO{Repository[model.Stock]{DB: db, Table: "codes"}}
+}
 
+func (dao *StockDAO) Create(ctx context.Context, stock *model.Stock) error {
+  return dao.Insert(ctx, stock)
+}
+
+// Register adds the code with an empty name unless it exists, so that its
+// bars satisfy the foreign keys to codes.
+func (dao *StockDAO) Register(ctx context.Context, code string) error {
+  _, err := dao.DB.ExecContext(ctx, "INSERT INTO codes (code, name) VALUES (?, '') ON CONFLICT(code) DO NOTHING", code)
   return err
 }
 
-func (dao *StockDAO) Find(code string) (*model.Stock, error) {
-  row := dao.DB.QueryRow("SELECT code, name FROM stocks WHERE code = ?", code)
-  var stock model.Stock
-  if err := row.Scan(&stock.Code, &stock.Name); err != nil {
-    return nil, err
-  }
+func (dao *StockDAO) Find(ctx context.Context, code string) (*model.Stock, error) {
+  return dao.Repository.Find(ctx, code)
+}
 
-  return &stock, nil
+// FindPage returns stocks ordered by code, skipping offset and returning at
+// most limit.
+func (dao *StockDAO) FindPage(ctx context.Context, limit int, offset int) ([]*model.Stock, error) {
+  return dao.FindMany(ctx, "ORDER BY code LIMIT ? OFFSET ?", limit, offset)
 }
@@ -1,12 +1,15 @@
 package dao_test
 
 import (
+  "context"
+  "database/sql"
+  "errors"
   "testing"
 
   _ "github.com/mattn/go-sqlite3"
 
-  // "dunn-finance/pkg/database"
   "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/database"
   "dunn-finance/pkg/model"
 )
 
@@ -18,10 +21,10 @@ func TestStockDao_Create_Success(t *testing.T) {
   // defer db.Close()
   // db.Exec(createStocksSQL)
 
-  stockDao := dao.StockDAO{DB: db}
+  stockDao := dao.NewStockDAO(db)
   stock := &model.Stock{Code: "1234", Name: "テスト会社"}
 
-  err := stockDao.Create(stock)
+  err := stockDao.Create(context.Background(), stock)
   if err != nil { t.Errorf("Failed to create stock record: %v", err) }
 }
 
@@ -31,15 +34,63 @@ func TestStockDao_Find_Success(t *testing.T) {
   // db := manager.GetDBInstance()
   // defer db.Close()
   // db.Exec(createStocksSQL)
-  stockDao := dao.StockDAO{DB: db}
+  stockDao := dao.NewStockDAO(db)
 
   code := "1234"
   name := "テスト会社"
   stock := &model.Stock{Code: code, Name: name}
-  err := stockDao.Create(stock)
+  err := stockDao.Create(context.Background(), stock)
   if err != nil { t.Fatalf("Failed to create stock record: %v", err)}
 
-  actualStock, _ := stockDao.Find(code)
+  actualStock, _ := stockDao.Find(context.Background(), code)
   if code != actualStock.Code { t.Errorf("got %s, want %s", actualStock.Code, code) }
   if name != actualStock.Name { t.Errorf("got %s, want %s", actualStock.Name, name) }
 }
+
+func TestStockDao_Create_InTx_Rollback(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+
+  err := db.WithTx(ctx, func(tx database.Querier) error {
+    stockDao := dao.NewStockDAO(tx)
+    if err := stockDao.Create(ctx, &model.Stock{Code: "1234", Name: "テスト会社"}); err != nil { return err }
+    return errors.New("abort")
+  })
+  if err == nil { t.Fatalf("Expected error, got nil") }
+
+  stockDao := dao.NewStockDAO(db)
+  _, err = stockDao.Find(ctx, "1234")
+  if !errors.Is(err, sql.ErrNoRows) { t.Errorf("Expected sql.ErrNoRows after rollback, got %v", err) }
+}
+
+func TestStockDao_FindPage_Success(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+  stockDao := dao.NewStockDAO(db)
+
+  for _, code := range []string{"9984", "1234", "7203"} {
+    if err := stockDao.Create(ctx, &model.Stock{Code: code, Name: "テスト会社"}); err != nil { t.Fatal(err) }
+  }
+
+  page, err := stockDao.FindPage(ctx, 2, 1)
+  if err != nil { t.Fatal(err) }
+  if len(page) != 2 || page[0].Code != "7203" || page[1].Code != "9984" { t.Errorf("Unexpected page: %+v", page) }
+}
+
+func TestStockDao_Register_KeepsName(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+  stockDao := dao.NewStockDAO(db)
+
+  if err := stockDao.Create(ctx, &model.Stock{Code: "7203", Name: "トヨタ自動車"}); err != nil { t.Fatal(err) }
+  for _, code := range []string{"7203", "5253", "5253"} {
+    if err := stockDao.Register(ctx, code); err != nil { t.Fatalf("Failed to register %s: %v", code, err) }
+  }
+
+  toyota, err := stockDao.Find(ctx, "7203")
+  if err != nil { t.Fatal(err) }
+  if toyota.Name != "トヨタ自動車" { t.Errorf("Expected the name kept, got %q", toyota.Name) }
+  cover, err := stockDao.Find(ctx, "5253")
+  if err != nil { t.Fatal(err) }
+  if cover.Name != "" { t.Errorf("Expected an empty name, got %q", cover.Name) }
+}
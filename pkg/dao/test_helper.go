@@ -2,11 +2,17 @@ package dao
 
 import (
   "encoding/csv"
+  "fmt"
   "io"
   "os"
+  "path/filepath"
+  "runtime"
   "strconv"
+  "strings"
   "testing"
+  "time"
 
+  schema "dunn-finance/configs/sql"
   "dunn-finance/pkg/database"
   "dunn-finance/pkg/model"
 )
@@ -16,35 +22,59 @@ var TestManager = &database.DBManager{
   DSN: ":memory:",
 }
 
-var createStocksTableSql = "CREATE TABLE stocks (code TEXT PRIMARY KEY, name TEXT)"
-var createAdjustedDailyOhlcvsTableSql = `
-  CREATE TABLE adjusted_daily_ohlcvs (
-    yyyymmdd     INTEGER NOT NULL,
-    code         TEXT NOT NULL,
-    open_price   REAL,
-    high_price   REAL,
-    low_price    REAL,
-    close_price  REAL,
-    dma_price_5  REAL,
-    dma_price_25 REAL,
-    dma_price_75 REAL,
-    vmap         REAL,
-    volume       REAL,
-    vma_5        REAL,
-    vma_25       REAL,
-    PRIMARY KEY (code, yyyymmdd),
-    FOREIGN KEY (code) REFERENCES codes(code)
-  );`
-
+// PrepareTestDB returns a fresh in-memory DB per test with the tables of
+// configs/sql. Foreign keys are enforced, so codes must be created before
+// their rows.
 func PrepareTestDB(t *testing.T) database.DBConnector {
-  db := TestManager.GetDBInstance()
+  manager := &database.DBManager{Driver: TestManager.Driver, DSN: TestManager.DSN}
+  db := manager.GetDBInstance()
   t.Cleanup(func() { db.Close() })
 
-  if  _, err := db.Exec(createStocksTableSql); err != nil {
-    t.Fatalf("Failed to create test stocks table: %v", err)
+  for _, name := range schema.Files {
+    execSchemaFile(t, db, "sqlite3", name)
+  }
+
+  return db
+}
+
+// execSchemaFile runs a file of configs/sql, so tests use the same tables and
+// triggers as the databases do.
+func execSchemaFile(t *testing.T, db database.DBConnector, dialect string, name string) {
+  _, self, _, _ := runtime.Caller(0)
+  path := filepath.Join(filepath.Dir(self), "..", "..", "configs", "sql", dialect, name)
+  query, err := os.ReadFile(path)
+  if err != nil { t.Fatalf("Failed to read %s: %v", path, err) }
+  if _, err := db.Exec(string(query)); err != nil { t.Fatalf("Failed to run %s: %v", name, err) }
+}
+
+// PostgresTestDSNEnv points the PostgreSQL tests at a running server,
+// e.g. "postgres://postgres@localhost:5432/postgres?sslmode=disable".
+const PostgresTestDSNEnv = "DUNN_TEST_POSTGRES_DSN"
+
+// PreparePostgresTestDB creates the tables in a schema of its own and drops
+// it after the test. The test is skipped when DUNN_TEST_POSTGRES_DSN is unset.
+func PreparePostgresTestDB(t *testing.T) database.DBConnector {
+  dsn := os.Getenv(PostgresTestDSNEnv)
+  if dsn == "" { t.Skipf("%s is not set", PostgresTestDSNEnv) }
+
+  admin := (&database.DBManager{Driver: "postgres", DSN: dsn}).GetDBInstance()
+  t.Cleanup(func() { admin.Close() })
+  if err := admin.Ping(); err != nil { t.Skipf("PostgreSQL is not available: %v", err) }
+
+  schemaName := fmt.Sprintf("dunn_test_%d", time.Now().UnixNano())
+  if _, err := admin.Exec("CREATE SCHEMA " + schemaName); err != nil { t.Fatalf("Failed to create schema: %v", err) }
+  t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schemaName + " CASCADE") })
+
+  separator := " "
+  if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+    separator = "&"
+    if !strings.Contains(dsn, "?") { separator = "?" }
   }
-  if  _, err := db.Exec(createAdjustedDailyOhlcvsTableSql); err != nil {
-    t.Fatalf("Failed to create test adjusted_daily_ohlcvs table: %v", err)
+  db := (&database.DBManager{Driver: "postgres", DSN: dsn + separator + "search_path=" + schemaName}).GetDBInstance()
+  t.Cleanup(func() { db.Close() })
+
+  for _, name := range schema.Files {
+    execSchemaFile(t, db, "postgres", name)
   }
 
   return db
@@ -71,8 +101,11 @@ func LoadOhlcvCSV(path string) ([]*model.AdjustedDailyOHLCV, error) {
       return &v
     }
 
+    yyyymmdd, err := model.ParseDate(rec[0])
+    if err != nil { return nil, err }
+
     records = append(records, &model.AdjustedDailyOHLCV{
-			Yyyymmdd:   rec[0],
+			Yyyymmdd:   yyyymmdd,
 			Code:       rec[1],
 			OpenPrice:  parseFloat(rec[2]),
 			HighPrice:  parseFloat(rec[3]),
@@ -0,0 +1,58 @@
+package dao
+
+import (
+  "context"
+
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/model"
+)
+
+type WatchlistCodeDAO struct {
+  Repository[model.WatchlistCode]
+}
+
+func NewWatchlistCodeDAO(db database.Querier) *WatchlistCodeDAO {
+  return &WatchlistCodeDAO{Repository[model.WatchlistCode]{DB: db, Table: "watchlist_codes"}}
+}
+
+// Add puts a code on its list. A code already on the list keeps its added
+// date, and only its note is replaced when the new one is not empty.
+func (dao *WatchlistCodeDAO) Add(ctx context.Context, member *model.WatchlistCode) error {
+  _, err := dao.DB.ExecContext(
+    ctx,
+    `
+    INSERT INTO watchlist_codes (watchlist_id, code, note, added_at) VALUES (?, ?, ?, ?)
+    ON CONFLICT(watchlist_id, code) DO UPDATE SET note = CASE WHEN excluded.note = '' THEN watchlist_codes.note ELSE excluded.note END
+    `,
+    member.WatchlistID,
+    member.Code,
+    member.Note,
+    member.AddedAt,
+  )
+  return err
+}
+
+func (dao *WatchlistCodeDAO) Remove(ctx context.Context, watchlistID int64, code string) (int64, error) {
+  return dao.Delete(ctx, watchlistID, code)
+}
+
+// FindByWatchlist returns the codes of a list in code order.
+func (dao *WatchlistCodeDAO) FindByWatchlist(ctx context.Context, watchlistID int64) ([]*model.WatchlistCode, error) {
+  return dao.FindMany(ctx, "WHERE watchlist_id = ? ORDER BY code", watchlistID)
+}
+
+// Codes returns the codes on the list of the name in code order, or
+// sql.ErrNoRows when there is no such list.
+func (dao *WatchlistCodeDAO) Codes(ctx context.Context, name string) ([]string, error) {
+  list, err := NewWatchlistDAO(dao.DB).FindByName(ctx, name)
+  if err != nil { return nil, err }
+  members, err := dao.FindByWatchlist(ctx, list.ID)
+  if err != nil { return nil, err }
+
+  codes := make([]string, 0, len(members))
+  for _, member := range members {
+    codes = append(codes, member.Code)
+  }
+
+  return codes, nil
+}
@@ -0,0 +1,49 @@
+package dao
+
+import (
+  "context"
+
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/model"
+)
+
+type WatchlistDAO struct {
+  Repository[model.Watchlist]
+}
+
+func NewWatchlistDAO(db database.Querier) *WatchlistDAO {
+  return &WatchlistDAO{Repository[model.Watchlist]{DB: db, Table: "watchlists"}}
+}
+
+// Create inserts list and sets its ID, which the database generates.
+func (dao *WatchlistDAO) Create(ctx context.Context, list *model.Watchlist) error {
+  return dao.DB.QueryRowContext(
+    ctx,
+    "INSERT INTO watchlists (name, notes, created_at) VALUES (?, ?, ?) RETURNING id",
+    list.Name,
+    list.Notes,
+    list.CreatedAt,
+  ).Scan(&list.ID)
+}
+
+// FindByName returns sql.ErrNoRows when there is no list of the name.
+func (dao *WatchlistDAO) FindByName(ctx context.Context, name string) (*model.Watchlist, error) {
+  var list model.Watchlist
+  if err := dao.DB.QueryRowContext(ctx, dao.SelectSQL()+" WHERE name = ?", name).Scan(dao.pointers(&list)...); err != nil { return nil, err }
+
+  return &list, nil
+}
+
+func (dao *WatchlistDAO) FindAll(ctx context.Context) ([]*model.Watchlist, error) {
+  return dao.FindMany(ctx, "ORDER BY name")
+}
+
+func (dao *WatchlistDAO) Update(ctx context.Context, list *model.Watchlist) error {
+  _, err := dao.DB.ExecContext(ctx, "UPDATE watchlists SET name = ?, notes = ? WHERE id = ?", list.Name, list.Notes, list.ID)
+  return err
+}
+
+// Delete removes the list and, by the foreign key, its codes.
+func (dao *WatchlistDAO) Delete(ctx context.Context, id int64) (int64, error) {
+  return dao.Repository.Delete(ctx, id)
+}
@@ -0,0 +1,78 @@
+package dao_test
+
+import (
+  "context"
+  "database/sql"
+  "errors"
+  "slices"
+  "testing"
+  "time"
+
+  _ "github.com/mattn/go-sqlite3"
+
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/model"
+)
+
+func TestWatchlistDao_Members_Success(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+  listDao := dao.NewWatchlistDAO(db)
+  codeDao := dao.NewWatchlistCodeDAO(db)
+
+  createdAt := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+  holdings := &model.Watchlist{Name: "holdings", Notes: "NISA", CreatedAt: createdAt}
+  banks := &model.Watchlist{Name: "banks", CreatedAt: createdAt}
+  for _, list := range []*model.Watchlist{holdings, banks} {
+    if err := listDao.Create(ctx, list); err != nil { t.Fatalf("Failed to create watchlist: %v", err) }
+  }
+  if err := listDao.Create(ctx, &model.Watchlist{Name: "banks", CreatedAt: createdAt}); err == nil { t.Error("Expected a duplicate name to fail") }
+
+  addedAt := time.Date(2025, 7, 2, 9, 0, 0, 0, time.UTC)
+  for _, member := range []*model.WatchlistCode{
+    {WatchlistID: holdings.ID, Code: "7203", Note: "core", AddedAt: addedAt},
+    {WatchlistID: holdings.ID, Code: "1234", AddedAt: addedAt},
+    {WatchlistID: banks.ID, Code: "8306", AddedAt: addedAt},
+    // Adding again keeps the added date and the note.
+    {WatchlistID: holdings.ID, Code: "7203", AddedAt: addedAt.AddDate(0, 0, 7)},
+  } {
+    if err := codeDao.Add(ctx, member); err != nil { t.Fatalf("Failed to add %s: %v", member.Code, err) }
+  }
+
+  members, err := codeDao.FindByWatchlist(ctx, holdings.ID)
+  if err != nil { t.Fatal(err) }
+  if len(members) != 2 || members[1].Code != "7203" || members[1].Note != "core" || !members[1].AddedAt.Equal(addedAt) { t.Errorf("Unexpected members: %+v", members) }
+
+  codes, err := codeDao.Codes(ctx, "holdings")
+  if err != nil { t.Fatal(err) }
+  if !slices.Equal(codes, []string{"1234", "7203"}) { t.Errorf("Unexpected codes: %v", codes) }
+  if _, err := codeDao.Codes(ctx, "nothing"); !errors.Is(err, sql.ErrNoRows) { t.Errorf("Expected sql.ErrNoRows, got %v", err) }
+
+  if n, err := codeDao.Remove(ctx, holdings.ID, "1234"); err != nil || n != 1 { t.Errorf("Failed to remove: %d %v", n, err) }
+  if codes, _ := codeDao.Codes(ctx, "holdings"); !slices.Equal(codes, []string{"7203"}) { t.Errorf("Unexpected codes after remove: %v", codes) }
+}
+
+func TestWatchlistDao_Update_Delete_Success(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+  listDao := dao.NewWatchlistDAO(db)
+  codeDao := dao.NewWatchlistCodeDAO(db)
+
+  list := &model.Watchlist{Name: "tech", CreatedAt: time.Now()}
+  if err := listDao.Create(ctx, list); err != nil { t.Fatal(err) }
+  if err := codeDao.Add(ctx, &model.WatchlistCode{WatchlistID: list.ID, Code: "6758", AddedAt: time.Now()}); err != nil { t.Fatal(err) }
+
+  list.Name, list.Notes = "semiconductors", "Tokyo Electron and peers"
+  if err := listDao.Update(ctx, list); err != nil { t.Fatal(err) }
+  got, err := listDao.FindByName(ctx, "semiconductors")
+  if err != nil { t.Fatal(err) }
+  if got.ID != list.ID || got.Notes != "Tokyo Electron and peers" { t.Errorf("Unexpected watchlist: %+v", got) }
+  if _, err := listDao.FindByName(ctx, "tech"); !errors.Is(err, sql.ErrNoRows) { t.Errorf("Expected the old name to be gone, got %v", err) }
+
+  if n, err := listDao.Delete(ctx, list.ID); err != nil || n != 1 { t.Fatalf("Failed to delete: %d %v", n, err) }
+  if count, err := codeDao.Count(ctx, ""); err != nil || count != 0 { t.Errorf("Expected the codes to be deleted with the list, got %d %v", count, err) }
+
+  lists, err := listDao.FindAll(ctx)
+  if err != nil { t.Fatal(err) }
+  if len(lists) != 0 { t.Errorf("Unexpected lists: %+v", lists) }
+}
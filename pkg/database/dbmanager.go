@@ -1,24 +1,51 @@
 package database
 
 import (
+  "context"
   "database/sql"
+  "fmt"
   "log"
-
+  "strconv"
+  "strings"
   "sync"
+  "time"
 )
 
+// Querier is what the DAOs run statements against.
+// Both DBConnector and the transaction given to WithTx satisfy it.
+type Querier interface {
+  ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+  QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+  QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
 type DBConnector interface {
+  Querier
+
   Ping() error
   Close() error
   GetRawDB() *sql.DB
+  Dialect() Dialect
 
   Exec(query string, args ...any) (sql.Result, error)
   QueryRow(query string, args ...any) *sql.Row
   Query(query string, args ...any) (*sql.Rows, error)
+
+  // WithTx runs fn in a transaction. It commits when fn returns nil and
+  // rolls back when fn returns an error or panics.
+  WithTx(ctx context.Context, fn func(tx Querier) error) error
 }
 
+// SQLConnector rebinds every query to its dialect, so the DAOs can keep
+// writing ? placeholders on any backend.
 type SQLConnector struct {
-  db *sql.DB
+  db      *sql.DB
+  dialect Dialect
+}
+
+func (c *SQLConnector) Dialect() Dialect {
+  if c.dialect == nil { return SQLiteDialect{} }
+  return c.dialect
 }
 
 func (c *SQLConnector) Ping() error {
@@ -34,47 +61,154 @@ func (c *SQLConnector) GetRawDB() *sql.DB {
 }
 
 func (c *SQLConnector) Exec(query string, args ...any) (sql.Result, error) {
-  return c.db.Exec(query, args...)
+  return c.db.Exec(c.Dialect().Rebind(query), args...)
 }
 
 func (c *SQLConnector) QueryRow(query string, args ...any) *sql.Row {
-  return c.db.QueryRow(query, args...)
+  return c.db.QueryRow(c.Dialect().Rebind(query), args...)
 }
 
 func (c *SQLConnector) Query(query string, args ...any) (*sql.Rows, error) {
-  return c.db.Query(query, args...)
+  return c.db.Query(c.Dialect().Rebind(query), args...)
+}
+
+func (c *SQLConnector) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+  return c.db.ExecContext(ctx, c.Dialect().Rebind(query), args...)
+}
+
+func (c *SQLConnector) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+  return c.db.QueryRowContext(ctx, c.Dialect().Rebind(query), args...)
+}
+
+func (c *SQLConnector) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+  return c.db.QueryContext(ctx, c.Dialect().Rebind(query), args...)
+}
+
+func (c *SQLConnector) WithTx(ctx context.Context, fn func(tx Querier) error) error {
+  tx, err := c.db.BeginTx(ctx, nil)
+  if err != nil { return err }
+
+  defer func() {
+    if p := recover(); p != nil {
+      _ = tx.Rollback()
+      panic(p)
+    }
+  }()
+
+  if err := fn(&txQuerier{tx: tx, dialect: c.Dialect()}); err != nil {
+    if rbErr := tx.Rollback(); rbErr != nil { return fmt.Errorf("%w (rollback failed: %v)", err, rbErr) }
+    return err
+  }
+
+  return tx.Commit()
+}
+
+// txQuerier rebinds the queries of a transaction like SQLConnector does.
+type txQuerier struct {
+  tx      *sql.Tx
+  dialect Dialect
+}
+
+func (q *txQuerier) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+  return q.tx.ExecContext(ctx, q.dialect.Rebind(query), args...)
 }
 
+func (q *txQuerier) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+  return q.tx.QueryRowContext(ctx, q.dialect.Rebind(query), args...)
+}
+
+func (q *txQuerier) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+  return q.tx.QueryContext(ctx, q.dialect.Rebind(query), args...)
+}
+
+// DBManager owns one connection pool per manager, so managers with
+// different DSNs never share a connection.
+//
+// For the sqlite3 driver, every connection is opened with WAL journaling,
+// busy_timeout and foreign_keys=ON. An in-memory database lives only as long
+// as its connection, so it is limited to a single connection.
 type DBManager struct {
   Driver string
   DSN    string
-  mu     sync.Mutex
+
+  // Pool tuning. Zero keeps the database/sql default.
+  MaxOpenConns    int
+  MaxIdleConns    int
+  ConnMaxLifetime time.Duration
+
+  // How long SQLite waits on a locked database. Defaults to 5 seconds.
+  BusyTimeout time.Duration
+
+  mu   sync.Mutex
+  conn DBConnector
 }
 
-var (
-  dbInstance DBConnector
-  once       sync.Once
-)
+const defaultBusyTimeout = 5 * time.Second
 
 func (m *DBManager) GetDBInstance() DBConnector {
   m.mu.Lock()
   defer m.mu.Unlock()
 
-  if dbInstance != nil {
-    if err := dbInstance.Ping(); err == nil {
-      return dbInstance
+  if m.conn != nil {
+    if err := m.conn.Ping(); err == nil {
+      return m.conn
     }
 
     // Ping failure -> DB is closed
-    _ = dbInstance.Close()
-    dbInstance = nil
+    _ = m.conn.Close()
+    m.conn = nil
   }
 
-  conn, err := sql.Open(m.Driver, m.DSN)
+  conn, err := m.open()
   if err != nil {
     log.Fatalf("Failed to re-connect: %v", err)
   }
-  dbInstance = &SQLConnector{db: conn}
+  m.conn = &SQLConnector{db: conn, dialect: DialectFor(m.Driver)}
+
+  return m.conn
+}
+
+func (m *DBManager) open() (*sql.DB, error) {
+  dsn := m.DSN
+  if m.Driver == "sqlite3" { dsn = m.sqliteDSN() }
+
+  conn, err := sql.Open(m.Driver, dsn)
+  if err != nil { return nil, err }
+
+  if m.MaxOpenConns > 0 { conn.SetMaxOpenConns(m.MaxOpenConns) }
+  if m.MaxIdleConns > 0 { conn.SetMaxIdleConns(m.MaxIdleConns) }
+  if m.ConnMaxLifetime > 0 { conn.SetConnMaxLifetime(m.ConnMaxLifetime) }
+
+  if m.Driver == "sqlite3" && isSQLiteMemory(m.DSN) {
+    // Every new connection would see a different empty database.
+    conn.SetMaxOpenConns(1)
+    conn.SetMaxIdleConns(1)
+    conn.SetConnMaxLifetime(0)
+    conn.SetConnMaxIdleTime(0)
+  }
+
+  return conn, nil
+}
+
+// sqliteDSN adds the connection parameters understood by go-sqlite3, so they
+// apply to every connection in the pool and not only to the one a PRAGMA
+// statement happens to run on.
+func (m *DBManager) sqliteDSN() string {
+  busyTimeout := m.BusyTimeout
+  if busyTimeout <= 0 { busyTimeout = defaultBusyTimeout }
+
+  params := []string{
+    "_busy_timeout=" + strconv.FormatInt(busyTimeout.Milliseconds(), 10),
+    "_foreign_keys=on",
+  }
+  if !isSQLiteMemory(m.DSN) { params = append(params, "_journal_mode=WAL") }
+
+  separator := "?"
+  if strings.Contains(m.DSN, "?") { separator = "&" }
+
+  return m.DSN + separator + strings.Join(params, "&")
+}
 
-  return dbInstance
+func isSQLiteMemory(dsn string) bool {
+  return strings.HasPrefix(dsn, ":memory:") || strings.Contains(dsn, "mode=memory")
 }
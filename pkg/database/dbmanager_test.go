@@ -1,8 +1,12 @@
 package database_test
 
 import (
+  "context"
+  "errors"
   "fmt"
+  "path/filepath"
   "testing"
+  "time"
 
   _ "github.com/mattn/go-sqlite3"
 
@@ -104,3 +108,140 @@ func TestSQLite3_QueryRow_Failure(t *testing.T) {
     t.Errorf("Expected error for invalid SQL, got nil")
   }
 }
+
+func TestSQLite3_WithTx_Commit_Success(t *testing.T) {
+  manager := &database.DBManager{ Driver: "sqlite3", DSN: ":memory:", }
+  db := manager.GetDBInstance()
+  defer db.Close()
+
+  ctx := context.Background()
+  if _, err := db.ExecContext(ctx, "CREATE TABLE dummy (id INTEGER)"); err != nil { t.Fatal(err) }
+
+  err := db.WithTx(ctx, func(tx database.Querier) error {
+    _, err := tx.ExecContext(ctx, "INSERT INTO dummy (id) VALUES (1)")
+    return err
+  })
+  if err != nil { t.Fatalf("WithTx failed: %v", err) }
+
+  var count int
+  if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM dummy").Scan(&count); err != nil { t.Fatal(err) }
+  if count != 1 { t.Errorf("Expected 1 committed row, got %d", count) }
+}
+
+func TestSQLite3_WithTx_Rollback_Success(t *testing.T) {
+  manager := &database.DBManager{ Driver: "sqlite3", DSN: ":memory:", }
+  db := manager.GetDBInstance()
+  defer db.Close()
+
+  ctx := context.Background()
+  if _, err := db.ExecContext(ctx, "CREATE TABLE dummy (id INTEGER)"); err != nil { t.Fatal(err) }
+
+  errExpected := errors.New("abort")
+  err := db.WithTx(ctx, func(tx database.Querier) error {
+    if _, err := tx.ExecContext(ctx, "INSERT INTO dummy (id) VALUES (1)"); err != nil { return err }
+    return errExpected
+  })
+  if !errors.Is(err, errExpected) { t.Errorf("Expected the error from fn, got %v", err) }
+
+  func() {
+    defer func() { _ = recover() }()
+    _ = db.WithTx(ctx, func(tx database.Querier) error {
+      if _, err := tx.ExecContext(ctx, "INSERT INTO dummy (id) VALUES (2)"); err != nil { return err }
+      panic("boom")
+    })
+  }()
+
+  var count int
+  if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM dummy").Scan(&count); err != nil { t.Fatal(err) }
+  if count != 0 { t.Errorf("Expected all rows to be rolled back, got %d", count) }
+}
+
+func TestSQLite3_QueryContext_Canceled(t *testing.T) {
+  manager := &database.DBManager{ Driver: "sqlite3", DSN: ":memory:", }
+  db := manager.GetDBInstance()
+  defer db.Close()
+
+  ctx, cancel := context.WithCancel(context.Background())
+  cancel()
+
+  _, err := db.QueryContext(ctx, "SELECT 1")
+  if !errors.Is(err, context.Canceled) { t.Errorf("Expected context.Canceled, got %v", err) }
+}
+
+func TestSQLite3_TwoDSNs_Isolated(t *testing.T) {
+  dir := t.TempDir()
+  testManager := &database.DBManager{ Driver: "sqlite3", DSN: filepath.Join(dir, "test.db"), }
+  prodManager := &database.DBManager{ Driver: "sqlite3", DSN: filepath.Join(dir, "prod.db"), }
+
+  testDB := testManager.GetDBInstance()
+  defer testDB.Close()
+  prodDB := prodManager.GetDBInstance()
+  defer prodDB.Close()
+
+  if testDB == prodDB { t.Fatalf("Expected different connections for different DSNs") }
+
+  if _, err := testDB.Exec("CREATE TABLE dummy (id INTEGER)"); err != nil { t.Fatal(err) }
+
+  var count int
+  err := prodDB.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'dummy'").Scan(&count)
+  if err != nil { t.Fatal(err) }
+  if count != 0 { t.Errorf("Expected table created in test DB not to exist in prod DB") }
+}
+
+func TestSQLite3_InMemory_Isolated(t *testing.T) {
+  manager1 := &database.DBManager{ Driver: "sqlite3", DSN: ":memory:", }
+  manager2 := &database.DBManager{ Driver: "sqlite3", DSN: ":memory:", }
+
+  db1 := manager1.GetDBInstance()
+  defer db1.Close()
+  db2 := manager2.GetDBInstance()
+  defer db2.Close()
+
+  if _, err := db1.Exec("CREATE TABLE dummy (id INTEGER)"); err != nil { t.Fatal(err) }
+  if _, err := db2.Exec("CREATE TABLE dummy (id INTEGER)"); err != nil {
+    t.Errorf("Expected a separate in-memory DB per manager, but got: %v", err)
+  }
+}
+
+func TestSQLite3_Pragmas_Success(t *testing.T) {
+  manager := &database.DBManager{
+    Driver: "sqlite3",
+    DSN: filepath.Join(t.TempDir(), "test.db"),
+    MaxOpenConns: 3,
+    BusyTimeout: 2 * time.Second,
+  }
+  db := manager.GetDBInstance()
+  defer db.Close()
+
+  var journalMode string
+  if err := db.QueryRow("PRAGMA journal_mode").Scan(&journalMode); err != nil { t.Fatal(err) }
+  if journalMode != "wal" { t.Errorf("Expected journal_mode wal, got %s", journalMode) }
+
+  var busyTimeout int
+  if err := db.QueryRow("PRAGMA busy_timeout").Scan(&busyTimeout); err != nil { t.Fatal(err) }
+  if busyTimeout != 2000 { t.Errorf("Expected busy_timeout 2000, got %d", busyTimeout) }
+
+  var foreignKeys int
+  if err := db.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil { t.Fatal(err) }
+  if foreignKeys != 1 { t.Errorf("Expected foreign_keys 1, got %d", foreignKeys) }
+
+  if max := db.GetRawDB().Stats().MaxOpenConnections; max != 3 { t.Errorf("Expected max open connections 3, got %d", max) }
+}
+
+func TestPostgresDialect_Rebind(t *testing.T) {
+  tests := map[string]string{
+    "SELECT 1": "SELECT 1",
+    "SELECT * FROM t WHERE a = ? AND b BETWEEN ? AND ?": "SELECT * FROM t WHERE a = $1 AND b BETWEEN $2 AND $3",
+    "SELECT '?' FROM t WHERE a = ?": "SELECT '?' FROM t WHERE a = $1",
+  }
+
+  for query, expected := range tests {
+    actual := database.PostgresDialect{}.Rebind(query)
+    if actual != expected { t.Errorf("got %q, want %q", actual, expected) }
+  }
+}
+
+func TestDialectFor(t *testing.T) {
+  if name := database.DialectFor("postgres").Name(); name != "postgres" { t.Errorf("got %s, want postgres", name) }
+  if name := database.DialectFor("sqlite3").Name(); name != "sqlite3" { t.Errorf("got %s, want sqlite3", name) }
+}
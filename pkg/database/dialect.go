@@ -0,0 +1,60 @@
+package database
+
+import (
+  "strconv"
+  "strings"
+)
+
+// Dialect covers the SQL differences between the supported backends.
+// The DAOs write queries with ? placeholders and ON CONFLICT ... excluded,
+// which SQLite and PostgreSQL both understand apart from the placeholders.
+type Dialect interface {
+  Name() string
+  Rebind(query string) string
+}
+
+type SQLiteDialect struct{}
+
+func (SQLiteDialect) Name() string { return "sqlite3" }
+
+func (SQLiteDialect) Rebind(query string) string { return query }
+
+type PostgresDialect struct{}
+
+func (PostgresDialect) Name() string { return "postgres" }
+
+// Rebind replaces ? with $1, $2, ... outside of quoted strings.
+func (PostgresDialect) Rebind(query string) string {
+  var sb strings.Builder
+  sb.Grow(len(query) + 16)
+
+  n := 0
+  var quote byte
+  for i := 0; i < len(query); i++ {
+    c := query[i]
+    switch {
+      case quote != 0:
+        if c == quote { quote = 0 }
+      case c == '\'' || c == '"':
+        quote = c
+      case c == '?':
+        n++
+        sb.WriteByte('$')
+        sb.WriteString(strconv.Itoa(n))
+        continue
+    }
+    sb.WriteByte(c)
+  }
+
+  return sb.String()
+}
+
+// DialectFor returns the dialect of a database/sql driver name.
+func DialectFor(driver string) Dialect {
+  switch driver {
+    case "postgres", "pgx":
+      return PostgresDialect{}
+    default:
+      return SQLiteDialect{}
+  }
+}
@@ -0,0 +1,194 @@
+package database
+
+import (
+  "context"
+  "database/sql"
+  "errors"
+  "fmt"
+  "os"
+  "path/filepath"
+  "sort"
+  "strings"
+  "time"
+
+  "github.com/mattn/go-sqlite3"
+)
+
+// BackupTimeFormat is the timestamp in backup file names. It sorts in time
+// order, which the retention policy relies on.
+const BackupTimeFormat = "20060102T150405"
+
+var ErrNotSQLite = errors.New("only supported for the sqlite3 driver")
+
+// Backup copies the database to dest with SQLite's online backup API. The
+// copy is a consistent snapshot even while other connections write. It is
+// written next to dest first and renamed into place, so dest is never left
+// half written.
+func (m *DBManager) Backup(ctx context.Context, dest string) error {
+  if m.Driver != "sqlite3" { return ErrNotSQLite }
+
+  tmp := dest + ".tmp"
+  _ = os.Remove(tmp)
+
+  if err := copySQLite(ctx, m.GetDBInstance().GetRawDB(), tmp, false); err != nil {
+    _ = os.Remove(tmp)
+    return err
+  }
+  if err := IntegrityCheck(ctx, tmp); err != nil {
+    _ = os.Remove(tmp)
+    return err
+  }
+
+  return os.Rename(tmp, dest)
+}
+
+// Restore replaces the contents of the database with the backup at src,
+// after checking the backup's integrity. The live database is overwritten
+// through the backup API, so open connections see the restored data.
+func (m *DBManager) Restore(ctx context.Context, src string) error {
+  if m.Driver != "sqlite3" { return ErrNotSQLite }
+
+  if _, err := os.Stat(src); err != nil { return err }
+  if err := IntegrityCheck(ctx, src); err != nil { return fmt.Errorf("backup %s: %w", src, err) }
+
+  return copySQLite(ctx, m.GetDBInstance().GetRawDB(), src, true)
+}
+
+// Vacuum rebuilds the database file to reclaim free pages.
+func (m *DBManager) Vacuum(ctx context.Context) error {
+  if m.Driver != "sqlite3" { return ErrNotSQLite }
+
+  _, err := m.GetDBInstance().ExecContext(ctx, "VACUUM")
+  return err
+}
+
+// Analyze refreshes the statistics the query planner uses.
+func (m *DBManager) Analyze(ctx context.Context) error {
+  _, err := m.GetDBInstance().ExecContext(ctx, "ANALYZE")
+  return err
+}
+
+// IntegrityCheck opens the SQLite file at path read-only and runs
+// PRAGMA integrity_check on it.
+func IntegrityCheck(ctx context.Context, path string) error {
+  db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+  if err != nil { return err }
+  defer db.Close()
+
+  rows, err := db.QueryContext(ctx, "PRAGMA integrity_check")
+  if err != nil { return err }
+  defer rows.Close()
+
+  var problems []string
+  for rows.Next() {
+    var line string
+    if err := rows.Scan(&line); err != nil { return err }
+    if line != "ok" { problems = append(problems, line) }
+  }
+  if err := rows.Err(); err != nil { return err }
+  if len(problems) > 0 { return fmt.Errorf("integrity check failed: %s", strings.Join(problems, "; ")) }
+
+  return nil
+}
+
+// copySQLite runs the backup API between live and the file at path. With
+// restore it copies path into live, otherwise live into path.
+func copySQLite(ctx context.Context, live *sql.DB, path string, restore bool) error {
+  fileDB, err := sql.Open("sqlite3", path)
+  if err != nil { return err }
+  defer fileDB.Close()
+
+  liveConn, err := live.Conn(ctx)
+  if err != nil { return err }
+  defer liveConn.Close()
+
+  fileConn, err := fileDB.Conn(ctx)
+  if err != nil { return err }
+  defer fileConn.Close()
+
+  return liveConn.Raw(func(liveRaw any) error {
+    return fileConn.Raw(func(fileRaw any) error {
+      liveSQLite, ok := liveRaw.(*sqlite3.SQLiteConn)
+      if !ok { return ErrNotSQLite }
+      fileSQLite, ok := fileRaw.(*sqlite3.SQLiteConn)
+      if !ok { return ErrNotSQLite }
+
+      dest, src := fileSQLite, liveSQLite
+      if restore { dest, src = liveSQLite, fileSQLite }
+
+      backup, err := dest.Backup("main", src, "main")
+      if err != nil { return err }
+
+      // One step copies every page under a single read lock, which is what
+      // makes the copy consistent.
+      _, stepErr := backup.Step(-1)
+      if err := backup.Finish(); err != nil && stepErr == nil { stepErr = err }
+
+      return stepErr
+    })
+  })
+}
+
+// BackupName returns the file name of a backup of the database at dbPath
+// taken at t, e.g. dunn-20250710T090000.db for dunn.db.
+func BackupName(dbPath string, t time.Time) string {
+  base := filepath.Base(dbPath)
+  ext := filepath.Ext(base)
+  return strings.TrimSuffix(base, ext) + "-" + t.Format(BackupTimeFormat) + ext
+}
+
+// RetentionPolicy decides which backups PruneBackups keeps. A backup is kept
+// when it is among the newest Keep or younger than MaxAge. Zero disables a
+// rule; with both zero every backup is kept.
+type RetentionPolicy struct {
+  Keep   int
+  MaxAge time.Duration
+}
+
+// PruneBackups removes the backups of dbPath in dir that the policy does not
+// keep and returns the removed paths.
+func PruneBackups(dir string, dbPath string, policy RetentionPolicy, now time.Time) ([]string, error) {
+  if policy.Keep <= 0 && policy.MaxAge <= 0 { return nil, nil }
+
+  backups, err := ListBackups(dir, dbPath)
+  if err != nil { return nil, err }
+
+  var removed []string
+  for i, backup := range backups {
+    if policy.Keep > 0 && i < policy.Keep { continue }
+    if policy.MaxAge > 0 && now.Sub(backup.TakenAt) < policy.MaxAge { continue }
+
+    if err := os.Remove(backup.Path); err != nil { return removed, err }
+    removed = append(removed, backup.Path)
+  }
+
+  return removed, nil
+}
+
+type BackupFile struct {
+  Path    string
+  TakenAt time.Time
+}
+
+// ListBackups returns the backups of dbPath in dir, newest first.
+func ListBackups(dir string, dbPath string) ([]BackupFile, error) {
+  base := filepath.Base(dbPath)
+  ext := filepath.Ext(base)
+  prefix := strings.TrimSuffix(base, ext) + "-"
+
+  entries, err := os.ReadDir(dir)
+  if err != nil { return nil, err }
+
+  var backups []BackupFile
+  for _, entry := range entries {
+    name := entry.Name()
+    if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) { continue }
+
+    takenAt, err := time.ParseInLocation(BackupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext), time.Local)
+    if err != nil { continue }
+    backups = append(backups, BackupFile{Path: filepath.Join(dir, name), TakenAt: takenAt})
+  }
+  sort.Slice(backups, func(i, j int) bool { return backups[i].TakenAt.After(backups[j].TakenAt) })
+
+  return backups, nil
+}
@@ -0,0 +1,72 @@
+package database_test
+
+import (
+  "context"
+  "os"
+  "path/filepath"
+  "testing"
+  "time"
+
+  _ "github.com/mattn/go-sqlite3"
+
+  "dunn-finance/pkg/database"
+)
+
+func TestSQLite3_Backup_Restore_Success(t *testing.T) {
+  dir := t.TempDir()
+  ctx := context.Background()
+  manager := &database.DBManager{ Driver: "sqlite3", DSN: filepath.Join(dir, "dunn.db"), }
+  db := manager.GetDBInstance()
+  defer db.Close()
+
+  if _, err := db.Exec("CREATE TABLE dummy (id INTEGER)"); err != nil { t.Fatal(err) }
+  if _, err := db.Exec("INSERT INTO dummy (id) VALUES (1), (2)"); err != nil { t.Fatal(err) }
+
+  backupPath := filepath.Join(dir, "backup.db")
+  if err := manager.Backup(ctx, backupPath); err != nil { t.Fatalf("Backup failed: %v", err) }
+  if _, err := os.Stat(backupPath + ".tmp"); !os.IsNotExist(err) { t.Errorf("Expected the temporary file to be renamed, got %v", err) }
+
+  if _, err := db.Exec("DELETE FROM dummy"); err != nil { t.Fatal(err) }
+  if err := manager.Restore(ctx, backupPath); err != nil { t.Fatalf("Restore failed: %v", err) }
+
+  var count int
+  if err := db.QueryRow("SELECT COUNT(*) FROM dummy").Scan(&count); err != nil { t.Fatal(err) }
+  if count != 2 { t.Errorf("Expected 2 restored rows, got %d", count) }
+
+  if err := manager.Vacuum(ctx); err != nil { t.Errorf("Vacuum failed: %v", err) }
+  if err := manager.Analyze(ctx); err != nil { t.Errorf("Analyze failed: %v", err) }
+}
+
+func TestSQLite3_Restore_Corrupt_Failure(t *testing.T) {
+  dir := t.TempDir()
+  manager := &database.DBManager{ Driver: "sqlite3", DSN: filepath.Join(dir, "dunn.db"), }
+  db := manager.GetDBInstance()
+  defer db.Close()
+
+  corrupt := filepath.Join(dir, "corrupt.db")
+  if err := os.WriteFile(corrupt, []byte("this is not a database"), 0o600); err != nil { t.Fatal(err) }
+
+  if err := manager.Restore(context.Background(), corrupt); err == nil { t.Errorf("Expected an error for a corrupt backup, got nil") }
+}
+
+func TestPruneBackups(t *testing.T) {
+  dir := t.TempDir()
+  now := time.Date(2025, 7, 10, 12, 0, 0, 0, time.Local)
+
+  for _, daysAgo := range []int{0, 1, 2, 10, 20} {
+    name := database.BackupName("/data/dunn.db", now.AddDate(0, 0, -daysAgo))
+    if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil { t.Fatal(err) }
+  }
+  if err := os.WriteFile(filepath.Join(dir, "other-20250101T000000.db"), nil, 0o600); err != nil { t.Fatal(err) }
+
+  policy := database.RetentionPolicy{Keep: 2, MaxAge: 7 * 24 * time.Hour}
+  removed, err := database.PruneBackups(dir, "/data/dunn.db", policy, now)
+  if err != nil { t.Fatal(err) }
+  if len(removed) != 2 { t.Errorf("Expected 2 removed backups, got %v", removed) }
+
+  backups, err := database.ListBackups(dir, "/data/dunn.db")
+  if err != nil { t.Fatal(err) }
+  if len(backups) != 3 { t.Fatalf("Expected 3 backups kept, got %d", len(backups)) }
+  if !backups[0].TakenAt.Equal(now) { t.Errorf("Expected newest first, got %v", backups[0].TakenAt) }
+  if _, err := os.Stat(filepath.Join(dir, "other-20250101T000000.db")); err != nil { t.Errorf("Backups of other databases must be left alone: %v", err) }
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+  "context"
+  "fmt"
+  "io/fs"
+  "strings"
+  "time"
+)
+
+const createSchemaMigrationsSql = `
+  CREATE TABLE IF NOT EXISTS schema_migrations (
+    name       TEXT PRIMARY KEY,
+    applied_at TIMESTAMP NOT NULL
+  )`
+
+// unlessColumn starts the line of a schema file adding a column, naming it
+// as table.column. SQLite has no ADD COLUMN IF NOT EXISTS, so Migrate records
+// the file without running it when the table has the column already.
+const unlessColumn = "-- migrate: unless column "
+
+// Migrate runs the files of fsys named in names, in that order, that have
+// not run on the database yet. Each file runs in a transaction together
+// with its row in schema_migrations. It returns the names it ran.
+func Migrate(ctx context.Context, db DBConnector, fsys fs.FS, names []string) ([]string, error) {
+  if _, err := db.ExecContext(ctx, createSchemaMigrationsSql); err != nil { return nil, err }
+
+  applied, err := AppliedMigrations(ctx, db)
+  if err != nil { return nil, err }
+
+  var ran []string
+  for _, name := range names {
+    if _, ok := applied[name]; ok { continue }
+
+    query, err := fs.ReadFile(fsys, name)
+    if err != nil { return ran, err }
+    skipped := false
+    err = db.WithTx(ctx, func(tx Querier) error {
+      if skipped, err = hasColumnOf(ctx, tx, db.Dialect(), string(query)); err != nil { return err }
+      if !skipped {
+        if _, err := tx.ExecContext(ctx, string(query)); err != nil { return err }
+      }
+      _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (name, applied_at) VALUES (?, ?)", name, time.Now())
+      return err
+    })
+    if err != nil { return ran, fmt.Errorf("failed to run %s: %w", name, err) }
+    if !skipped { ran = append(ran, name) }
+  }
+
+  return ran, nil
+}
+
+// hasColumnOf reports whether the database has the column of the unless
+// column line of query. It is false for files without one.
+func hasColumnOf(ctx context.Context, db Querier, dialect Dialect, query string) (bool, error) {
+  for _, line := range strings.Split(query, "\n") {
+    spec, ok := strings.CutPrefix(strings.TrimSpace(line), unlessColumn)
+    if !ok { continue }
+    table, column, ok := strings.Cut(strings.TrimSpace(spec), ".")
+    if !ok { return false, fmt.Errorf("invalid %q, want table.column", spec) }
+
+    q := "SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?"
+    if dialect.Name() == "postgres" { q = "SELECT COUNT(*) FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?" }
+    var n int
+    if err := db.QueryRowContext(ctx, q, table, column).Scan(&n); err != nil { return false, err }
+    return n > 0, nil
+  }
+  return false, nil
+}
+
+// AppliedMigrations returns when each migration ran. It is empty before the
+// first Migrate.
+func AppliedMigrations(ctx context.Context, db Querier) (map[string]time.Time, error) {
+  if _, err := db.ExecContext(ctx, createSchemaMigrationsSql); err != nil { return nil, err }
+
+  rows, err := db.QueryContext(ctx, "SELECT name, applied_at FROM schema_migrations")
+  if err != nil { return nil, err }
+  defer rows.Close()
+
+  applied := make(map[string]time.Time)
+  for rows.Next() {
+    var name string
+    var appliedAt time.Time
+    if err := rows.Scan(&name, &appliedAt); err != nil { return nil, err }
+    applied[name] = appliedAt
+  }
+
+  return applied, rows.Err()
+}
@@ -0,0 +1,149 @@
+package database_test
+
+import (
+  "context"
+  "slices"
+  "testing"
+  "testing/fstest"
+
+  _ "github.com/mattn/go-sqlite3"
+
+  schema "dunn-finance/configs/sql"
+  "dunn-finance/pkg/csvreader"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/importer"
+  "dunn-finance/pkg/model"
+  "dunn-finance/pkg/validation"
+)
+
+func TestMigrate_Success(t *testing.T) {
+  ctx := context.Background()
+  manager := &database.DBManager{ Driver: "sqlite3", DSN: ":memory:", }
+  db := manager.GetDBInstance()
+  defer db.Close()
+
+  fsys := fstest.MapFS{
+    "a.sql": {Data: []byte("CREATE TABLE a (id INTEGER PRIMARY KEY);")},
+    "b.sql": {Data: []byte("CREATE TABLE b (id INTEGER PRIMARY KEY, a_id INTEGER REFERENCES a(id)); CREATE INDEX idx_b_a_id ON b (a_id);")},
+    "c.sql": {Data: []byte("CREATE TABLE c (id INTEGER PRIMARY KEY")},
+  }
+
+  ran, err := database.Migrate(ctx, db, fsys, []string{"a.sql", "b.sql"})
+  if err != nil { t.Fatal(err) }
+  if !slices.Equal(ran, []string{"a.sql", "b.sql"}) { t.Errorf("Unexpected migrations: %v", ran) }
+
+  // Applied files do not run again, so their CREATE TABLE does not fail.
+  ran, err = database.Migrate(ctx, db, fsys, []string{"a.sql", "b.sql", "c.sql"})
+  if err == nil { t.Fatal("Expected the invalid file to fail") }
+  if len(ran) != 0 { t.Errorf("Expected nothing to run, got %v", ran) }
+
+  applied, err := database.AppliedMigrations(ctx, db)
+  if err != nil { t.Fatal(err) }
+  if len(applied) != 2 || applied["b.sql"].IsZero() { t.Errorf("Unexpected applied migrations: %v", applied) }
+  if _, ok := applied["c.sql"]; ok { t.Error("A failed migration must not be recorded") }
+}
+
+func TestMigrate_Schema_Success(t *testing.T) {
+  ctx := context.Background()
+  manager := &database.DBManager{ Driver: "sqlite3", DSN: ":memory:", }
+  db := manager.GetDBInstance()
+  defer db.Close()
+
+  fsys, err := schema.FS("sqlite3")
+  if err != nil { t.Fatal(err) }
+  ran, err := database.Migrate(ctx, db, fsys, schema.Files)
+  if err != nil { t.Fatal(err) }
+  if !slices.Equal(ran, schema.Files) { t.Errorf("Unexpected migrations: %v", ran) }
+
+  for _, table := range []string{"codes", "imports", "adjusted_daily_ohlcvs", "adjusted_daily_ohlcvs_history", "watchlist_codes", "alerts"} {
+    var count int
+    if err := db.QueryRow("SELECT COUNT(*) FROM " + table).Scan(&count); err != nil { t.Errorf("%s: %v", table, err) }
+  }
+}
+
+// baselineSchema is configs/sql/sqlite3 before imports existed.
+const baselineSchema = `
+CREATE TABLE IF NOT EXISTS adjusted_daily_ohlcvs (
+  yyyymmdd     TEXT NOT NULL,
+  code         TEXT NOT NULL,
+  open_price   REAL,
+  high_price   REAL,
+  low_price    REAL,
+  close_price  REAL,
+  dma_price_5  REAL,
+  dma_price_25 REAL,
+  dma_price_75 REAL,
+  vmap         REAL,
+  volume       REAL,
+  vma_5        REAL,
+  vma_25       REAL,
+  PRIMARY KEY (code, yyyymmdd),
+  FOREIGN KEY (code) REFERENCES codes(code)
+);
+CREATE TABLE IF NOT EXISTS codes (
+  code TEXT PRIMARY KEY,
+  name TEXT
+);
+CREATE TABLE IF NOT EXISTS daily_stocks (
+  yyyymmdd INTEGER,
+  code TEXT,
+  openPrice REAL,
+  highPrice REAL,
+  lowPrice REAL,
+  closePrice REAL,
+  rsi REAL,
+  macd REAL,
+  signal REAL
+);`
+
+func TestMigrate_Baseline_Success(t *testing.T) {
+  ctx := context.Background()
+  manager := &database.DBManager{ Driver: "sqlite3", DSN: ":memory:", }
+  db := manager.GetDBInstance()
+  defer db.Close()
+
+  if _, err := db.Exec(baselineSchema); err != nil { t.Fatal(err) }
+  if _, err := db.Exec("INSERT INTO codes (code, name) VALUES ('1301', NULL)"); err != nil { t.Fatal(err) }
+  if _, err := db.Exec("INSERT INTO adjusted_daily_ohlcvs (yyyymmdd, code, close_price) VALUES ('20250701', '1301', 4000)"); err != nil { t.Fatal(err) }
+
+  fsys, err := schema.FS("sqlite3")
+  if err != nil { t.Fatal(err) }
+  ran, err := database.Migrate(ctx, db, fsys, schema.Files)
+  if err != nil { t.Fatal(err) }
+  if !slices.Contains(ran, "adjusted_daily_ohlcvs_import_id.sql") { t.Errorf("Expected import_id to be added, ran %v", ran) }
+
+  date, _ := model.ParseDate("20250701")
+  bar, err := dao.NewAdjustedDailyOHLCVDAO(db).Find(ctx, "1301", date)
+  if err != nil { t.Fatalf("Failed to read a baseline bar: %v", err) }
+  if bar.ImportID != nil || *bar.ClosePrice != 4000 { t.Errorf("Unexpected bar: %+v", bar) }
+
+  result := importer.ImportFile(ctx, "../csvreader/testdata/sbi_timechart_5253_20250720.csv", "", csvreader.SBIFieldMap, true, validation.PolicyRejectRow, db)
+  if result.Err != nil || result.Inserted == 0 { t.Fatalf("Failed to import: %+v", result) }
+
+  stocks, err := dao.NewStockDAO(db).FindMany(ctx, "ORDER BY code")
+  if err != nil { t.Fatalf("Failed to read stocks: %v", err) }
+  if len(stocks) != 2 || stocks[1].Code != "5253" { t.Errorf("Unexpected stocks: %+v", stocks) }
+}
+
+func TestMigrate_ColumnExists_Skipped(t *testing.T) {
+  ctx := context.Background()
+  manager := &database.DBManager{ Driver: "sqlite3", DSN: ":memory:", }
+  db := manager.GetDBInstance()
+  defer db.Close()
+
+  fsys, err := schema.FS("sqlite3")
+  if err != nil { t.Fatal(err) }
+  if _, err := database.Migrate(ctx, db, fsys, schema.Files); err != nil { t.Fatal(err) }
+
+  // A database migrated when adjusted_daily_ohlcvs.sql created import_id.
+  if _, err := db.Exec("DELETE FROM schema_migrations WHERE name = 'adjusted_daily_ohlcvs_import_id.sql'"); err != nil { t.Fatal(err) }
+
+  ran, err := database.Migrate(ctx, db, fsys, schema.Files)
+  if err != nil { t.Fatalf("Expected the file to be skipped: %v", err) }
+  if len(ran) != 0 { t.Errorf("Expected nothing to run, got %v", ran) }
+
+  applied, err := database.AppliedMigrations(ctx, db)
+  if err != nil { t.Fatal(err) }
+  if _, ok := applied["adjusted_daily_ohlcvs_import_id.sql"]; !ok { t.Error("Expected the skipped file to be recorded") }
+}
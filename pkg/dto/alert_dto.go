@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+type AlertDTO struct {
+  ID          int64     `json:"id"`
+  RuleID      int64     `json:"rule_id"`
+  Code        string    `json:"code"`
+  Yyyymmdd    string    `json:"yyyymmdd"`
+  Message     string    `json:"message"`
+  TriggeredAt time.Time `json:"triggered_at"`
+}
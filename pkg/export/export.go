@@ -0,0 +1,237 @@
+package export
+
+import (
+  "context"
+  "fmt"
+  "io"
+  "iter"
+  "os"
+  "path/filepath"
+  "reflect"
+  "sort"
+  "strings"
+  "time"
+
+  "github.com/apache/arrow-go/v18/arrow"
+  "github.com/apache/arrow-go/v18/arrow/array"
+  "github.com/apache/arrow-go/v18/arrow/ipc"
+  "github.com/apache/arrow-go/v18/arrow/memory"
+  "github.com/apache/arrow-go/v18/parquet"
+  "github.com/apache/arrow-go/v18/parquet/compress"
+  "github.com/apache/arrow-go/v18/parquet/pqarrow"
+
+  "dunn-finance/pkg/model"
+)
+
+type Format string
+
+const (
+  FormatParquet Format = "parquet"
+  FormatArrow   Format = "arrow"
+)
+
+type Partition string
+
+const (
+  PartitionNone Partition = ""
+  PartitionCode Partition = "code"
+  PartitionYear Partition = "year"
+)
+
+const defaultBatchSize = 64 * 1024
+
+type Options struct {
+  Format    Format
+  Partition Partition
+  // Rows per record batch (Parquet row group). Defaults to 65536.
+  BatchSize int
+}
+
+type Result struct {
+  Rows  int
+  Files []string
+}
+
+// Schema is derived from the db tags of model.AdjustedDailyOHLCV, so a new
+// column there is exported without changes here. yyyymmdd becomes a DATE
+// column named date, and *float64 fields become nullable float64 columns.
+var Schema = buildSchema()
+
+func buildSchema() *arrow.Schema {
+  typ := reflect.TypeOf(model.AdjustedDailyOHLCV{})
+  var fields []arrow.Field
+  for i := 0; i < typ.NumField(); i++ {
+    field := typ.Field(i)
+    name := strings.Split(field.Tag.Get("db"), ",")[0]
+    switch {
+      case field.Type == reflect.TypeOf(model.Date{}):
+        fields = append(fields, arrow.Field{Name: "date", Type: arrow.FixedWidthTypes.Date32})
+      case field.Type.Kind() == reflect.String:
+        fields = append(fields, arrow.Field{Name: name, Type: arrow.BinaryTypes.String})
+      case field.Type == reflect.TypeOf((*float64)(nil)):
+        fields = append(fields, arrow.Field{Name: name, Type: arrow.PrimitiveTypes.Float64, Nullable: true})
+    }
+  }
+
+  return arrow.NewSchema(fields, nil)
+}
+
+// Export writes the rows to out. Without a partition out is the file to
+// write. With one, out is a directory laid out Hive style, e.g.
+// out/code=5253/part-0.parquet, which pandas and Polars read as a dataset.
+func Export(ctx context.Context, rows iter.Seq2[*model.AdjustedDailyOHLCV, error], out string, opts Options) (*Result, error) {
+  if opts.Format != FormatParquet && opts.Format != FormatArrow { return nil, fmt.Errorf("unknown format %q", opts.Format) }
+  if opts.Partition != PartitionNone && opts.Partition != PartitionCode && opts.Partition != PartitionYear {
+    return nil, fmt.Errorf("unknown partition %q", opts.Partition)
+  }
+  if opts.BatchSize <= 0 { opts.BatchSize = defaultBatchSize }
+
+  e := &exporter{out: out, opts: opts, mem: memory.NewGoAllocator(), partitions: make(map[string]*partitionWriter)}
+  result, err := e.run(ctx, rows)
+  if closeErr := e.closeAll(); err == nil { err = closeErr }
+  if err != nil { return nil, err }
+
+  return result, nil
+}
+
+type exporter struct {
+  out        string
+  opts       Options
+  mem        memory.Allocator
+  partitions map[string]*partitionWriter
+}
+
+type partitionWriter struct {
+  path    string
+  file    *os.File
+  writer  recordWriter
+  builder *array.RecordBuilder
+  pending int
+}
+
+type recordWriter interface {
+  Write(rec arrow.Record) error
+  Close() error
+}
+
+func (e *exporter) run(ctx context.Context, rows iter.Seq2[*model.AdjustedDailyOHLCV, error]) (*Result, error) {
+  result := &Result{}
+  for ohlcv, err := range rows {
+    if err != nil { return nil, err }
+    if err := ctx.Err(); err != nil { return nil, err }
+
+    if ohlcv.Yyyymmdd.IsZero() { return nil, fmt.Errorf("%w: no date on a row of %s", model.ErrInvalidDate, ohlcv.Code) }
+    t := ohlcv.Yyyymmdd.Time()
+
+    p, err := e.partition(ohlcv, t)
+    if err != nil { return nil, err }
+
+    appendRow(p.builder, ohlcv, t)
+    p.pending++
+    result.Rows++
+    if p.pending >= e.opts.BatchSize {
+      if err := p.flush(); err != nil { return nil, err }
+    }
+  }
+
+  for _, p := range e.partitions {
+    if err := p.flush(); err != nil { return nil, err }
+    result.Files = append(result.Files, p.path)
+  }
+  sort.Strings(result.Files)
+
+  return result, nil
+}
+
+func (e *exporter) partition(ohlcv *model.AdjustedDailyOHLCV, t time.Time) (*partitionWriter, error) {
+  key := ""
+  switch e.opts.Partition {
+    case PartitionCode:
+      key = "code=" + ohlcv.Code
+    case PartitionYear:
+      key = fmt.Sprintf("year=%d", t.Year())
+  }
+  if p, ok := e.partitions[key]; ok { return p, nil }
+
+  path := e.out
+  if key != "" { path = filepath.Join(e.out, key, "part-0."+string(e.opts.Format)) }
+  if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { return nil, err }
+
+  file, err := os.Create(path)
+  if err != nil { return nil, err }
+
+  writer, err := e.newWriter(file)
+  if err != nil {
+    file.Close()
+    return nil, err
+  }
+
+  p := &partitionWriter{path: path, file: file, writer: writer, builder: array.NewRecordBuilder(e.mem, Schema)}
+  e.partitions[key] = p
+
+  return p, nil
+}
+
+func (e *exporter) newWriter(w io.Writer) (recordWriter, error) {
+  if e.opts.Format == FormatArrow { return ipc.NewFileWriter(w, ipc.WithSchema(Schema), ipc.WithAllocator(e.mem)) }
+
+  props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy), parquet.WithAllocator(e.mem))
+  arrowProps := pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema())
+
+  return pqarrow.NewFileWriter(Schema, w, props, arrowProps)
+}
+
+func (e *exporter) closeAll() error {
+  var firstErr error
+  for _, p := range e.partitions {
+    p.builder.Release()
+    if err := p.writer.Close(); err != nil && firstErr == nil { firstErr = err }
+    // pqarrow closes the file itself. Closing twice only returns an error.
+    _ = p.file.Close()
+  }
+
+  return firstErr
+}
+
+func (p *partitionWriter) flush() error {
+  if p.pending == 0 { return nil }
+
+  rec := p.builder.NewRecord()
+  defer rec.Release()
+  p.pending = 0
+
+  return p.writer.Write(rec)
+}
+
+func appendRow(b *array.RecordBuilder, ohlcv *model.AdjustedDailyOHLCV, t time.Time) {
+  v := reflect.ValueOf(ohlcv).Elem()
+  for i, field := range Schema.Fields() {
+    switch builder := b.Field(i).(type) {
+      case *array.Date32Builder:
+        builder.Append(arrow.Date32FromTime(t))
+      case *array.StringBuilder:
+        builder.Append(v.FieldByIndex(fieldIndex(field.Name)).String())
+      case *array.Float64Builder:
+        f := v.FieldByIndex(fieldIndex(field.Name))
+        if f.IsNil() {
+          builder.AppendNull()
+        } else {
+          builder.Append(f.Elem().Float())
+        }
+    }
+  }
+}
+
+var fieldIndexes = func() map[string][]int {
+  typ := reflect.TypeOf(model.AdjustedDailyOHLCV{})
+  indexes := make(map[string][]int)
+  for i := 0; i < typ.NumField(); i++ {
+    field := typ.Field(i)
+    indexes[strings.Split(field.Tag.Get("db"), ",")[0]] = field.Index
+  }
+  return indexes
+}()
+
+func fieldIndex(column string) []int {
+  return fieldIndexes[column]
+}
@@ -0,0 +1,149 @@
+package export_test
+
+import (
+  "context"
+  "iter"
+  "os"
+  "path/filepath"
+  "testing"
+
+  "github.com/apache/arrow-go/v18/arrow"
+  "github.com/apache/arrow-go/v18/arrow/array"
+  "github.com/apache/arrow-go/v18/arrow/ipc"
+  "github.com/apache/arrow-go/v18/arrow/memory"
+  "github.com/apache/arrow-go/v18/parquet/file"
+  "github.com/apache/arrow-go/v18/parquet/pqarrow"
+
+  "dunn-finance/pkg/export"
+  "dunn-finance/pkg/model"
+)
+
+func f(v float64) *float64 { return &v }
+
+func testRows() []*model.AdjustedDailyOHLCV {
+  return []*model.AdjustedDailyOHLCV{
+    {Yyyymmdd: model.MustParseDate("20241230"), Code: "1234", OpenPrice: f(100), ClosePrice: f(110), Volume: f(1000)},
+    {Yyyymmdd: model.MustParseDate("20250106"), Code: "1234", OpenPrice: f(111), ClosePrice: f(120), Volume: f(2000)},
+    {Yyyymmdd: model.MustParseDate("20250106"), Code: "5678", OpenPrice: f(50), ClosePrice: f(55), DMAPrice5: f(52)},
+  }
+}
+
+func seq(rows []*model.AdjustedDailyOHLCV) iter.Seq2[*model.AdjustedDailyOHLCV, error] {
+  return func(yield func(*model.AdjustedDailyOHLCV, error) bool) {
+    for _, row := range rows {
+      if !yield(row, nil) { return }
+    }
+  }
+}
+
+func readParquet(t *testing.T, path string) arrow.Table {
+  t.Helper()
+  reader, err := file.OpenParquetFile(path, false)
+  if err != nil { t.Fatalf("Open %s: %v", path, err) }
+  t.Cleanup(func() { reader.Close() })
+
+  fileReader, err := pqarrow.NewFileReader(reader, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+  if err != nil { t.Fatal(err) }
+  table, err := fileReader.ReadTable(context.Background())
+  if err != nil { t.Fatal(err) }
+  t.Cleanup(table.Release)
+
+  return table
+}
+
+func TestSchema(t *testing.T) {
+  date, ok := export.Schema.FieldsByName("date")
+  if !ok || date[0].Type.ID() != arrow.DATE32 || date[0].Nullable { t.Errorf("date should be a non-null DATE32, got %v", date) }
+
+  code, ok := export.Schema.FieldsByName("code")
+  if !ok || code[0].Type.ID() != arrow.STRING { t.Errorf("code should be a string, got %v", code) }
+
+  for _, name := range []string{"open_price", "close_price", "dma_price_75", "vmap", "volume", "vma_25"} {
+    fields, ok := export.Schema.FieldsByName(name)
+    if !ok { t.Errorf("missing %s", name); continue }
+    if fields[0].Type.ID() != arrow.FLOAT64 || !fields[0].Nullable { t.Errorf("%s should be a nullable float64, got %v", name, fields[0]) }
+  }
+}
+
+func TestExport_ParquetSingleFile(t *testing.T) {
+  out := filepath.Join(t.TempDir(), "ohlcv.parquet")
+
+  result, err := export.Export(context.Background(), seq(testRows()), out, export.Options{Format: export.FormatParquet})
+  if err != nil { t.Fatalf("Export: %v", err) }
+  if result.Rows != 3 || len(result.Files) != 1 || result.Files[0] != out { t.Errorf("Unexpected result: %+v", result) }
+
+  table := readParquet(t, out)
+  if table.NumRows() != 3 { t.Fatalf("want 3 rows, got %d", table.NumRows()) }
+  for i, field := range export.Schema.Fields() {
+    got := table.Schema().Field(i)
+    if got.Name != field.Name || !arrow.TypeEqual(got.Type, field.Type) || got.Nullable != field.Nullable { t.Errorf("Field %d not round-tripped: %v", i, got) }
+  }
+
+  dates := table.Column(0).Data().Chunk(0).(*array.Date32)
+  if got := dates.Value(0).FormattedString(); got != "2024-12-30" { t.Errorf("want 2024-12-30, got %s", got) }
+
+  idx := table.Schema().FieldIndices("dma_price_5")[0]
+  dma := table.Column(idx).Data().Chunk(0).(*array.Float64)
+  if !dma.IsNull(0) || dma.IsNull(2) || dma.Value(2) != 52 { t.Errorf("dma_price_5 nulls not preserved: %v", dma) }
+}
+
+func TestExport_ParquetPartitioned(t *testing.T) {
+  tests := []struct {
+    partition export.Partition
+    want      map[string]int64
+  }{
+    {export.PartitionCode, map[string]int64{"code=1234": 2, "code=5678": 1}},
+    {export.PartitionYear, map[string]int64{"year=2024": 1, "year=2025": 2}},
+  }
+
+  for _, tt := range tests {
+    t.Run(string(tt.partition), func(t *testing.T) {
+      out := t.TempDir()
+      // A batch size of 1 writes one row group per row.
+      result, err := export.Export(context.Background(), seq(testRows()), out, export.Options{Format: export.FormatParquet, Partition: tt.partition, BatchSize: 1})
+      if err != nil { t.Fatalf("Export: %v", err) }
+      if len(result.Files) != len(tt.want) { t.Fatalf("want %d files, got %v", len(tt.want), result.Files) }
+
+      for dir, rows := range tt.want {
+        table := readParquet(t, filepath.Join(out, dir, "part-0.parquet"))
+        if table.NumRows() != rows { t.Errorf("%s: want %d rows, got %d", dir, rows, table.NumRows()) }
+      }
+    })
+  }
+}
+
+func TestExport_ArrowIPC(t *testing.T) {
+  out := filepath.Join(t.TempDir(), "ohlcv.arrow")
+
+  if _, err := export.Export(context.Background(), seq(testRows()), out, export.Options{Format: export.FormatArrow, BatchSize: 2}); err != nil { t.Fatalf("Export: %v", err) }
+
+  f, err := os.Open(out)
+  if err != nil { t.Fatal(err) }
+  defer f.Close()
+
+  reader, err := ipc.NewFileReader(f)
+  if err != nil { t.Fatalf("NewFileReader: %v", err) }
+  defer reader.Close()
+
+  if !reader.Schema().Equal(export.Schema) { t.Errorf("Schema not round-tripped:\n%v", reader.Schema()) }
+  if reader.NumRecords() != 2 { t.Errorf("want 2 record batches, got %d", reader.NumRecords()) }
+
+  var rows int64
+  for i := 0; i < reader.NumRecords(); i++ {
+    rec, err := reader.Record(i)
+    if err != nil { t.Fatal(err) }
+    rows += rec.NumRows()
+  }
+  if rows != 3 { t.Errorf("want 3 rows, got %d", rows) }
+}
+
+func TestExport_InvalidOptions(t *testing.T) {
+  out := t.TempDir()
+  if _, err := export.Export(context.Background(), seq(nil), out, export.Options{Format: "csv"}); err == nil { t.Error("Expected error for unknown format") }
+  if _, err := export.Export(context.Background(), seq(nil), out, export.Options{Format: export.FormatParquet, Partition: "month"}); err == nil { t.Error("Expected error for unknown partition") }
+}
+
+func TestExport_InvalidDate(t *testing.T) {
+  rows := []*model.AdjustedDailyOHLCV{{Code: "1234"}}
+  if _, err := export.Export(context.Background(), seq(rows), filepath.Join(t.TempDir(), "x.parquet"), export.Options{Format: export.FormatParquet}); err == nil { t.Error("Expected error for a row without a date") }
+}
@@ -0,0 +1,241 @@
+package importer
+
+import (
+  "context"
+  "crypto/sha256"
+  "encoding/hex"
+  "errors"
+  "fmt"
+  "io"
+  "os"
+  "path/filepath"
+  "sort"
+  "sync"
+  "text/tabwriter"
+  "time"
+
+  "dunn-finance/pkg/csvreader"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/model"
+  "dunn-finance/pkg/validation"
+)
+
+type FileResult struct {
+  Path     string
+  Code     string
+  ImportID int64
+  Rows     int
+  Inserted int
+  Failed   int
+  // Rows dropped by validation, and the problems found in the file.
+  Rejected int
+  Issues   []validation.Issue
+  Duration time.Duration
+  Err      error
+}
+
+// ExpandPaths turns a directory into its *.csv files, or expands a glob.
+func ExpandPaths(dirOrGlob string) ([]string, error) {
+  pattern := dirOrGlob
+  if info, err := os.Stat(dirOrGlob); err == nil && info.IsDir() { pattern = filepath.Join(dirOrGlob, "*.csv") }
+
+  paths, err := filepath.Glob(pattern)
+  if err != nil { return nil, err }
+  if len(paths) == 0 { return nil, fmt.Errorf("no CSV files match %s", dirOrGlob) }
+  sort.Strings(paths)
+
+  return paths, nil
+}
+
+// HashFile returns the hex SHA-256 of the file, recorded with each import so
+// a re-import of the same content can be told apart from a corrected one.
+func HashFile(path string) (string, error) {
+  f, err := os.Open(path)
+  if err != nil { return "", err }
+  defer f.Close()
+
+  h := sha256.New()
+  if _, err := io.Copy(h, f); err != nil { return "", err }
+
+  return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+type parsedFile struct {
+  index   int
+  result  *FileResult
+  hash    string
+  records []*model.AdjustedDailyOHLCV
+  started time.Time
+}
+
+// ImportFiles parses and validates the files with a pool of workers and
+// writes the records from a single goroutine, since SQLite allows only one
+// writer at a time. Each file is written in its own transaction. Invalid rows
+// are handled by policy. Results are returned in the order of paths.
+func ImportFiles(ctx context.Context, paths []string, fieldMap map[int]string, isSkipHeader bool, workers int, policy validation.Policy, db database.DBConnector) []*FileResult {
+  if workers < 1 { workers = 1 }
+
+  jobs := make(chan int)
+  parsed := make(chan *parsedFile)
+  results := make([]*FileResult, len(paths))
+
+  var wg sync.WaitGroup
+  for w := 0; w < workers; w++ {
+    wg.Add(1)
+    go func() {
+      defer wg.Done()
+      for i := range jobs {
+        p := parseFile(paths[i], "", fieldMap, isSkipHeader, policy)
+        p.index = i
+        parsed <- p
+      }
+    }()
+  }
+
+  go func() {
+    for i := range paths {
+      jobs <- i
+    }
+    close(jobs)
+    wg.Wait()
+    close(parsed)
+  }()
+
+  for p := range parsed {
+    writeFile(ctx, p, db)
+    p.result.Duration = time.Since(p.started)
+    results[p.index] = p.result
+  }
+
+  return results
+}
+
+// ImportFile is ImportFiles for one file whose code is given. An empty code
+// is detected from the file.
+func ImportFile(ctx context.Context, path string, code string, fieldMap map[int]string, isSkipHeader bool, policy validation.Policy, db database.DBConnector) *FileResult {
+  p := parseFile(path, code, fieldMap, isSkipHeader, policy)
+  writeFile(ctx, p, db)
+  p.result.Duration = time.Since(p.started)
+
+  return p.result
+}
+
+func parseFile(path string, code string, fieldMap map[int]string, isSkipHeader bool, policy validation.Policy) *parsedFile {
+  p := &parsedFile{result: &FileResult{Path: path}, started: time.Now()}
+
+  if code == "" {
+    detected, err := csvreader.DetectCode(path)
+    if err != nil {
+      p.result.Err = err
+      return p
+    }
+    code = detected
+  }
+  p.result.Code = code
+
+  rows, err := csvreader.LoadAdjustedDailyOHLCVRowsFromCSV(code, path, fieldMap, isSkipHeader, 0, 0)
+  if err != nil {
+    p.result.Err = err
+    return p
+  }
+  p.result.Rows = len(rows)
+
+  validated := validation.ValidateRows(rows, policy, time.Now())
+  p.records = validated.Valid
+  p.result.Rejected = validated.Rejected
+  p.result.Issues = validated.Issues
+  if validated.FileRejected {
+    p.result.Err = fmt.Errorf("rejected: %d issues", len(validated.Issues))
+    return p
+  }
+
+  if p.hash, err = HashFile(path); err != nil { p.result.Err = err }
+
+  return p
+}
+
+func writeFile(ctx context.Context, p *parsedFile, db database.DBConnector) {
+  if p.result.Err != nil { return }
+
+  path, err := filepath.Abs(p.result.Path)
+  if err != nil { path = p.result.Path }
+
+  inserted, failed := 0, 0
+  err = db.WithTx(ctx, func(tx database.Querier) error {
+    importDao := dao.NewImportDAO(tx)
+    imp := &model.Import{Source: "csv", FilePath: path, FileHash: p.hash, Code: p.result.Code, ImportedAt: time.Now(), RowsRead: p.result.Rows}
+    if err := importDao.Create(ctx, imp); err != nil { return err }
+    p.result.ImportID = imp.ID
+    if err := dao.NewStockDAO(tx).Register(ctx, p.result.Code); err != nil { return err }
+
+    ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(tx)
+    for _, record := range p.records {
+      if err := ctx.Err(); err != nil { return err }
+      record.ImportID = &imp.ID
+      err := insertRow(ctx, tx, ohlcvDao, record)
+      var rowErr *rowError
+      if errors.As(err, &rowErr) {
+        failed++
+        if p.result.Err == nil { p.result.Err = fmt.Errorf("failed to insert %s: %w", record.Yyyymmdd, rowErr.err) }
+        continue
+      }
+      if err != nil { return err }
+      inserted++
+    }
+    return importDao.SetRowCounts(ctx, imp.ID, p.result.Rows, inserted)
+  })
+  if err != nil {
+    p.result.Err = err
+    p.result.ImportID = 0
+    p.result.Failed = len(p.records)
+    return
+  }
+
+  p.result.Inserted, p.result.Failed = inserted, failed
+}
+
+// rowError is a row that failed to insert, leaving the transaction usable.
+type rowError struct {
+  err error
+}
+
+func (e *rowError) Error() string { return e.err.Error() }
+
+// insertRow inserts the record under a savepoint, so that a failed row is
+// undone alone. PostgreSQL aborts the whole transaction on a failed
+// statement otherwise, and every later row would fail with it.
+func insertRow(ctx context.Context, tx database.Querier, ohlcvDao *dao.AdjustedDailyOHLCVDAO, record *model.AdjustedDailyOHLCV) error {
+  if _, err := tx.ExecContext(ctx, "SAVEPOINT import_row"); err != nil { return err }
+
+  if err := ohlcvDao.Create(ctx, record); err != nil {
+    if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT import_row"); rbErr != nil { return fmt.Errorf("%w (rollback to savepoint failed: %v)", err, rbErr) }
+    return &rowError{err: err}
+  }
+
+  _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT import_row")
+  return err
+}
+
+// PrintResults writes a table of the files, followed by every validation
+// issue with its file and line.
+func PrintResults(w io.Writer, results []*FileResult) error {
+  tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+  fmt.Fprintln(tw, "FILE\tCODE\tIMPORT\tROWS\tINSERTED\tREJECTED\tFAILED\tTIME\tERROR")
+  for _, r := range results {
+    errText := "-"
+    if r.Err != nil { errText = r.Err.Error() }
+    importText := "-"
+    if r.ImportID != 0 { importText = fmt.Sprint(r.ImportID) }
+    fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\t%s\n", filepath.Base(r.Path), r.Code, importText, r.Rows, r.Inserted, r.Rejected, r.Failed, r.Duration.Round(time.Millisecond), errText)
+  }
+  if err := tw.Flush(); err != nil { return err }
+
+  for _, r := range results {
+    for _, issue := range r.Issues {
+      if _, err := fmt.Fprintf(w, "%s: %v\n", r.Path, issue); err != nil { return err }
+    }
+  }
+
+  return nil
+}
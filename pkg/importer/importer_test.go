@@ -0,0 +1,157 @@
+package importer_test
+
+import (
+  "context"
+  "bytes"
+  "os"
+  "path/filepath"
+  "strings"
+  "testing"
+
+  _ "github.com/mattn/go-sqlite3"
+
+  "dunn-finance/pkg/csvreader"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/importer"
+  "dunn-finance/pkg/model"
+  "dunn-finance/pkg/validation"
+)
+
+var fieldMap = csvreader.SBIFieldMap
+
+func TestExpandPaths_Success(t *testing.T) {
+  fromDir, err := importer.ExpandPaths("testdata")
+  if err != nil { t.Fatal(err) }
+  if len(fromDir) != 3 { t.Errorf("Expected 3 files, got %v", fromDir) }
+
+  fromGlob, err := importer.ExpandPaths("testdata/sbi_timechart_*.csv")
+  if err != nil { t.Fatal(err) }
+  if len(fromGlob) != 2 { t.Errorf("Expected 2 files, got %v", fromGlob) }
+}
+
+func TestExpandPaths_Failure(t *testing.T) {
+  _, err := importer.ExpandPaths("testdata/not_existing_*.csv")
+  if err == nil { t.Errorf("Expected error, got nil") }
+
+  // A directory without CSV files is not a file to import.
+  paths, err := importer.ExpandPaths(t.TempDir())
+  if err == nil { t.Errorf("Expected error for a directory without CSV files, got %v", paths) }
+}
+
+func TestImportFiles_Success(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(db)
+
+  stockDao := dao.NewStockDAO(db)
+  for _, code := range []string{"5253", "7203"} {
+    if err := stockDao.Create(context.Background(), &model.Stock{Code: code, Name: "テスト会社"}); err != nil { t.Fatalf("Failed to create stock record: %v", err) }
+  }
+
+  paths, err := importer.ExpandPaths("testdata")
+  if err != nil { t.Fatal(err) }
+
+  results := importer.ImportFiles(context.Background(), paths, fieldMap, true, 3, validation.PolicyRejectRow, db)
+  if len(results) != 3 { t.Fatalf("Expected 3 results, got %d", len(results)) }
+
+  byCode := map[string]*importer.FileResult{}
+  for i, r := range results {
+    if r.Path != paths[i] { t.Errorf("Expected results in path order. got %s, want %s", r.Path, paths[i]) }
+    byCode[r.Code] = r
+  }
+
+  if r := byCode["5253"]; r == nil || r.Inserted != 10 || r.Err != nil { t.Errorf("Unexpected result for 5253: %+v", r) }
+  if r := byCode["7203"]; r == nil || r.Inserted != 6 || r.Err != nil { t.Errorf("Unexpected result for 7203: %+v", r) }
+  if r := results[2]; r.Err == nil { t.Errorf("Expected error for file without code, got %+v", r) }
+
+  got, err := ohlcvDao.FindByDateRange(context.Background(), "7203", model.MustParseDate("20250601"), model.MustParseDate("20250731"))
+  if err != nil { t.Fatal(err) }
+  if len(got) != 6 { t.Errorf("want 6 records, got %d", len(got)) }
+  if got[0].ImportID == nil || *got[0].ImportID != byCode["7203"].ImportID { t.Errorf("Rows should refer to import %d, got %v", byCode["7203"].ImportID, got[0].ImportID) }
+
+  imp, err := dao.NewImportDAO(db).Find(context.Background(), byCode["7203"].ImportID)
+  if err != nil { t.Fatal(err) }
+  hash, err := importer.HashFile(byCode["7203"].Path)
+  if err != nil { t.Fatal(err) }
+  if imp.Source != "csv" || imp.FileHash != hash || imp.RowsRead != 6 || imp.RowsWritten != 6 { t.Errorf("Unexpected import: %+v", imp) }
+
+  var buf bytes.Buffer
+  if err := importer.PrintResults(&buf, results); err != nil { t.Fatal(err) }
+  if !strings.Contains(buf.String(), "sbi_timechart_5253_20250720.csv") { t.Errorf("Unexpected table:\n%s", buf.String()) }
+}
+
+func TestImportFiles_InvalidRows(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+  if err := dao.NewStockDAO(db).Create(ctx, &model.Stock{Code: "1234", Name: "テスト会社"}); err != nil { t.Fatal(err) }
+
+  path := filepath.Join(t.TempDir(), "prices_1234.csv")
+  content := "日付,始値,高値,安値,終値\n" +
+    "2025/07/18,100,110,90,105\n" +
+    "2025/07/17,100,110,90,1O5\n" +
+    "2025/07/16,100,110,90,0\n"
+  if err := os.WriteFile(path, []byte(content), 0o600); err != nil { t.Fatal(err) }
+
+  results := importer.ImportFiles(ctx, []string{path}, fieldMap, true, 1, validation.PolicyRejectRow, db)
+  r := results[0]
+  if r.Err != nil || r.Rows != 3 || r.Inserted != 1 || r.Rejected != 2 { t.Errorf("Unexpected result: %+v", r) }
+  if len(r.Issues) == 0 || r.Issues[0].Line != 3 { t.Errorf("Expected the issue on line 3 first, got %v", r.Issues) }
+
+  count, err := dao.NewAdjustedDailyOHLCVDAO(db).Count(ctx, "WHERE code = ?", "1234")
+  if err != nil { t.Fatal(err) }
+  if count != 1 { t.Errorf("Expected only the valid row stored, got %d", count) }
+
+  var buf bytes.Buffer
+  if err := importer.PrintResults(&buf, results); err != nil { t.Fatal(err) }
+  if !strings.Contains(buf.String(), "line 3: 20250717: ClosePrice") { t.Errorf("Expected the issue in the report:\n%s", buf.String()) }
+
+  rejected := importer.ImportFiles(ctx, []string{path}, fieldMap, true, 1, validation.PolicyRejectFile, db)[0]
+  if rejected.Err == nil || rejected.Inserted != 0 || rejected.Rejected != 3 || rejected.ImportID != 0 { t.Errorf("Expected the file rejected, got %+v", rejected) }
+}
+
+func TestImportFile_Code(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  if err := dao.NewStockDAO(db).Create(context.Background(), &model.Stock{Code: "9999", Name: "テスト会社"}); err != nil { t.Fatal(err) }
+
+  // unknown.csv has no code to detect, so it needs one given.
+  if r := importer.ImportFile(context.Background(), "testdata/unknown.csv", "", fieldMap, true, validation.PolicyRejectRow, db); r.Err == nil { t.Errorf("Expected the code detection to fail, got %+v", r) }
+
+  // A given code wins over the one in the file name.
+  r := importer.ImportFile(context.Background(), "testdata/sbi_timechart_7203_20250720.csv", "9999", fieldMap, true, validation.PolicyRejectRow, db)
+  if r.Err != nil || r.Code != "9999" || r.Inserted != 6 || r.ImportID == 0 { t.Fatalf("Unexpected result: %+v", r) }
+
+  count, err := dao.NewAdjustedDailyOHLCVDAO(db).Count(context.Background(), "WHERE code = ?", "9999")
+  if err != nil { t.Fatal(err) }
+  if int(count) != r.Inserted { t.Errorf("want %d rows, got %d", r.Inserted, count) }
+}
+
+func TestImportFile_FailedRow(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+  if err := dao.NewStockDAO(db).Create(ctx, &model.Stock{Code: "7203", Name: "テスト会社"}); err != nil { t.Fatal(err) }
+  trigger := `
+    CREATE TRIGGER fail_one_row BEFORE INSERT ON adjusted_daily_ohlcvs
+    WHEN NEW.yyyymmdd = '20250702'
+    BEGIN SELECT RAISE(ABORT, 'row rejected by test'); END`
+  if _, err := db.Exec(trigger); err != nil { t.Fatal(err) }
+
+  // The rows after the failed one are still written, and so are the counts.
+  r := importer.ImportFile(ctx, "testdata/sbi_timechart_7203_20250720.csv", "", fieldMap, true, validation.PolicyRejectRow, db)
+  if r.Err == nil || !strings.Contains(r.Err.Error(), "row rejected by test") || r.Inserted != 5 || r.Failed != 1 || r.ImportID == 0 { t.Fatalf("Unexpected result: %+v", r) }
+
+  imp, err := dao.NewImportDAO(db).Find(ctx, r.ImportID)
+  if err != nil { t.Fatal(err) }
+  if imp.RowsRead != 6 || imp.RowsWritten != 5 { t.Errorf("Unexpected import: %+v", imp) }
+}
+
+func TestImportFile_RegistersCode(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+
+  // 7203 is not in codes yet, which the foreign key of the rows needs.
+  r := importer.ImportFile(ctx, "testdata/sbi_timechart_7203_20250720.csv", "", fieldMap, true, validation.PolicyRejectRow, db)
+  if r.Err != nil || r.Inserted != 6 { t.Fatalf("Unexpected result: %+v", r) }
+
+  stock, err := dao.NewStockDAO(db).Find(ctx, "7203")
+  if err != nil { t.Fatalf("Expected 7203 registered: %v", err) }
+  if stock.Name != "" { t.Errorf("Unexpected name: %q", stock.Name) }
+}
@@ -0,0 +1,170 @@
+// Package indicator computes technical indicators over a series of values.
+// Every result is aligned with its input and is nil where the indicator is
+// not defined yet or the input value is missing.
+package indicator
+
+import (
+  "dunn-finance/pkg/model"
+)
+
+// Closes returns the close prices of the bars.
+func Closes(bars []*model.AdjustedDailyOHLCV) []*float64 {
+  closes := make([]*float64, len(bars))
+  for i, bar := range bars {
+    closes[i] = bar.ClosePrice
+  }
+  return closes
+}
+
+// Volumes returns the volumes of the bars.
+func Volumes(bars []*model.AdjustedDailyOHLCV) []*float64 {
+  volumes := make([]*float64, len(bars))
+  for i, bar := range bars {
+    volumes[i] = bar.Volume
+  }
+  return volumes
+}
+
+// SMA is the simple moving average of the last n values. Missing values are
+// skipped.
+func SMA(values []*float64, n int) []*float64 {
+  result := make([]*float64, len(values))
+  if n < 1 { return result }
+
+  var window []float64
+  sum := 0.0
+  for i, v := range values {
+    if v == nil { continue }
+    window = append(window, *v)
+    sum += *v
+    if len(window) > n {
+      sum -= window[0]
+      window = window[1:]
+    }
+    if len(window) == n { result[i] = ptr(sum / float64(n)) }
+  }
+
+  return result
+}
+
+// FillMovingAverages sets the moving averages the SBI CSV has, the 5, 25
+// and 75 day DMA of the close and the 5 and 25 day VMA, where they are
+// missing, e.g. on bars fetched from J-Quants. bars is the whole history of
+// a code, oldest first. It returns the bars it changed.
+func FillMovingAverages(bars []*model.AdjustedDailyOHLCV) []*model.AdjustedDailyOHLCV {
+  closes, volumes := Closes(bars), Volumes(bars)
+  averages := []struct {
+    values []*float64
+    field  func(bar *model.AdjustedDailyOHLCV) **float64
+  }{
+    {SMA(closes, 5), func(bar *model.AdjustedDailyOHLCV) **float64 { return &bar.DMAPrice5 }},
+    {SMA(closes, 25), func(bar *model.AdjustedDailyOHLCV) **float64 { return &bar.DMAPrice25 }},
+    {SMA(closes, 75), func(bar *model.AdjustedDailyOHLCV) **float64 { return &bar.DMAPrice75 }},
+    {SMA(volumes, 5), func(bar *model.AdjustedDailyOHLCV) **float64 { return &bar.VMA5 }},
+    {SMA(volumes, 25), func(bar *model.AdjustedDailyOHLCV) **float64 { return &bar.VMA25 }},
+  }
+
+  var changed []*model.AdjustedDailyOHLCV
+  for i, bar := range bars {
+    filled := false
+    for _, average := range averages {
+      field := average.field(bar)
+      if *field == nil && average.values[i] != nil {
+        *field = average.values[i]
+        filled = true
+      }
+    }
+    if filled { changed = append(changed, bar) }
+  }
+
+  return changed
+}
+
+// EMA is the exponential moving average over n values, seeded with the simple
+// average of the first n. Missing values are skipped.
+func EMA(values []*float64, n int) []*float64 {
+  result := make([]*float64, len(values))
+  if n < 1 { return result }
+
+  k := 2 / float64(n+1)
+  count, sum, ema := 0, 0.0, 0.0
+  for i, v := range values {
+    if v == nil { continue }
+    count++
+    switch {
+      case count < n:
+        sum += *v
+        continue
+      case count == n:
+        ema = (sum + *v) / float64(n)
+      default:
+        ema = *v*k + ema*(1-k)
+    }
+    result[i] = ptr(ema)
+  }
+
+  return result
+}
+
+// RSI is Wilder's relative strength index over n changes, from 0 to 100.
+// Missing values are skipped, so a change spans the gap.
+func RSI(values []*float64, n int) []*float64 {
+  result := make([]*float64, len(values))
+  if n < 1 { return result }
+
+  var prev *float64
+  changes, avgGain, avgLoss := 0, 0.0, 0.0
+  for i, v := range values {
+    if v == nil { continue }
+    if prev == nil {
+      prev = v
+      continue
+    }
+
+    change := *v - *prev
+    prev = v
+    gain, loss := max(change, 0), max(-change, 0)
+    changes++
+    if changes <= n {
+      avgGain += gain / float64(n)
+      avgLoss += loss / float64(n)
+      if changes < n { continue }
+    } else {
+      avgGain = (avgGain*float64(n-1) + gain) / float64(n)
+      avgLoss = (avgLoss*float64(n-1) + loss) / float64(n)
+    }
+
+    if avgLoss == 0 {
+      result[i] = ptr(100)
+    } else {
+      result[i] = ptr(100 - 100/(1+avgGain/avgLoss))
+    }
+  }
+
+  return result
+}
+
+type MACDResult struct {
+  MACD      []*float64
+  Signal    []*float64
+  Histogram []*float64
+}
+
+// MACD is the fast EMA minus the slow EMA, with an EMA of it as the signal.
+// The usual periods are 12, 26 and 9.
+func MACD(values []*float64, fast int, slow int, signal int) *MACDResult {
+  fastEMA, slowEMA := EMA(values, fast), EMA(values, slow)
+
+  result := &MACDResult{MACD: make([]*float64, len(values)), Histogram: make([]*float64, len(values))}
+  for i := range values {
+    if fastEMA[i] != nil && slowEMA[i] != nil { result.MACD[i] = ptr(*fastEMA[i] - *slowEMA[i]) }
+  }
+  result.Signal = EMA(result.MACD, signal)
+  for i := range values {
+    if result.MACD[i] != nil && result.Signal[i] != nil { result.Histogram[i] = ptr(*result.MACD[i] - *result.Signal[i]) }
+  }
+
+  return result
+}
+
+func ptr(v float64) *float64 { return &v }
@@ -0,0 +1,80 @@
+package indicator_test
+
+import (
+  "math"
+  "testing"
+
+  "dunn-finance/pkg/indicator"
+  "dunn-finance/pkg/model"
+)
+
+func series(values ...float64) []*float64 {
+  result := make([]*float64, len(values))
+  for i := range values {
+    result[i] = &values[i]
+  }
+  return result
+}
+
+func assertSeries(t *testing.T, got []*float64, expected []float64) {
+  t.Helper()
+  if len(got) != len(expected) { t.Fatalf("got %d values, want %d", len(got), len(expected)) }
+  for i, e := range expected {
+    switch {
+      case math.IsNaN(e) && got[i] != nil:
+        t.Errorf("[%d] got %f, want nil", i, *got[i])
+      case !math.IsNaN(e) && (got[i] == nil || math.Abs(*got[i]-e) > 1e-6):
+        t.Errorf("[%d] got %v, want %f", i, got[i], e)
+    }
+  }
+}
+
+var nan = math.NaN()
+
+func TestSMA(t *testing.T) {
+  values := series(1, 2, 3, 4, 5)
+  values[2] = nil
+
+  // The missing 3 is skipped, so the window of 5 is 2, 4, 5.
+  assertSeries(t, indicator.SMA(values, 3), []float64{nan, nan, nan, 7.0 / 3, 11.0 / 3})
+}
+
+func TestFillMovingAverages(t *testing.T) {
+  var bars []*model.AdjustedDailyOHLCV
+  for i, v := range series(10, 11, 12, 13, 14, 15) {
+    bars = append(bars, &model.AdjustedDailyOHLCV{Yyyymmdd: model.MustParseDate("20250701").AddDays(i), ClosePrice: v, Volume: v})
+  }
+  kept := 99.0
+  bars[5].DMAPrice5 = &kept
+
+  changed := indicator.FillMovingAverages(bars)
+  // Only the fifth bar gets a DMA5, as the sixth already has one, and there
+  // are too few bars for the others.
+  if len(changed) != 2 || changed[0] != bars[4] || changed[1] != bars[5] { t.Fatalf("Unexpected changed bars: %v", changed) }
+  if *bars[4].DMAPrice5 != 12 || *bars[4].VMA5 != 12 || bars[4].DMAPrice25 != nil { t.Errorf("Unexpected averages: %+v", bars[4]) }
+  if *bars[5].DMAPrice5 != 99 || *bars[5].VMA5 != 13 { t.Errorf("Expected the stored DMA5 to be kept: %+v", bars[5]) }
+}
+
+func TestEMA(t *testing.T) {
+  values := series(1, 2, 3, 4, 5)
+  values[3] = nil
+
+  // Seeded with (1+2+3)/3 = 2, then 5*0.5 + 2*0.5.
+  assertSeries(t, indicator.EMA(values, 3), []float64{nan, nan, 2, nan, 3.5})
+}
+
+func TestRSI(t *testing.T) {
+  // Changes +1, -1, +2 average to 1 and 1/3, then -2 smooths them to 2/3
+  // and 8/9.
+  assertSeries(t, indicator.RSI(series(10, 11, 10, 12, 10), 3), []float64{nan, nan, nan, 75, 100 - 100/(1+0.75)})
+  assertSeries(t, indicator.RSI(series(1, 2, 3), 2), []float64{nan, nan, 100})
+}
+
+func TestMACD(t *testing.T) {
+  macd := indicator.MACD(series(1, 2, 3, 4, 5, 6), 2, 3, 2)
+
+  // Fast EMA(2): -, 1.5, 2.5, 3.5, 4.5, 5.5. Slow EMA(3): -, -, 2, 3, 4, 5.
+  assertSeries(t, macd.MACD, []float64{nan, nan, 0.5, 0.5, 0.5, 0.5})
+  assertSeries(t, macd.Signal, []float64{nan, nan, nan, 0.5, 0.5, 0.5})
+  assertSeries(t, macd.Histogram, []float64{nan, nan, nan, 0, 0, 0})
+}
@@ -0,0 +1,189 @@
+package jquants
+
+import (
+  "bytes"
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "net/http"
+  "net/url"
+  "sync"
+  "time"
+)
+
+const DefaultBaseURL = "https://api.jquants.com"
+
+var ErrUnauthorized = errors.New("jquants: unauthorized")
+
+// DailyQuote is one element of the daily_quotes response.
+// Prices are null while trading is suspended.
+type DailyQuote struct {
+  Date             string   `json:"Date"`
+  Code             string   `json:"Code"`
+  Open             *float64 `json:"Open"`
+  High             *float64 `json:"High"`
+  Low              *float64 `json:"Low"`
+  Close            *float64 `json:"Close"`
+  Volume           *float64 `json:"Volume"`
+  TurnoverValue    *float64 `json:"TurnoverValue"`
+  AdjustmentFactor float64  `json:"AdjustmentFactor"`
+  AdjustmentOpen   *float64 `json:"AdjustmentOpen"`
+  AdjustmentHigh   *float64 `json:"AdjustmentHigh"`
+  AdjustmentLow    *float64 `json:"AdjustmentLow"`
+  AdjustmentClose  *float64 `json:"AdjustmentClose"`
+  AdjustmentVolume *float64 `json:"AdjustmentVolume"`
+}
+
+type dailyQuotesResponse struct {
+  DailyQuotes   []DailyQuote `json:"daily_quotes"`
+  PaginationKey string       `json:"pagination_key"`
+}
+
+// Client calls the J-Quants API. The ID token is obtained from RefreshToken,
+// which in turn is obtained from MailAddress and Password when it is empty
+// or expired. Both tokens are refreshed transparently on 401.
+type Client struct {
+  BaseURL      string
+  HTTPClient   *http.Client
+  MailAddress  string
+  Password     string
+  RefreshToken string
+
+  mu      sync.Mutex
+  idToken string
+}
+
+func NewClient(baseURL string) *Client {
+  if baseURL == "" { baseURL = DefaultBaseURL }
+
+  return &Client{
+    BaseURL:    baseURL,
+    HTTPClient: &http.Client{Timeout: 30 * time.Second},
+  }
+}
+
+// DailyQuotes returns all quotes of code between from and to (yyyymmdd),
+// following pagination_key until the last page.
+func (c *Client) DailyQuotes(ctx context.Context, code string, from string, to string) ([]DailyQuote, error) {
+  var quotes []DailyQuote
+  paginationKey := ""
+  for {
+    query := url.Values{}
+    query.Set("code", code)
+    if from != "" { query.Set("from", from) }
+    if to != "" { query.Set("to", to) }
+    if paginationKey != "" { query.Set("pagination_key", paginationKey) }
+
+    var res dailyQuotesResponse
+    if err := c.get(ctx, "/v1/prices/daily_quotes", query, &res); err != nil { return nil, err }
+
+    quotes = append(quotes, res.DailyQuotes...)
+    if res.PaginationKey == "" { break }
+    paginationKey = res.PaginationKey
+  }
+
+  return quotes, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+  token, err := c.token(ctx, false)
+  if err != nil { return err }
+
+  err = c.getWithToken(ctx, path, query, token, out)
+  if !errors.Is(err, ErrUnauthorized) { return err }
+
+  // The ID token lives for 24 hours. Refresh it once and retry.
+  token, err = c.token(ctx, true)
+  if err != nil { return err }
+
+  return c.getWithToken(ctx, path, query, token, out)
+}
+
+func (c *Client) getWithToken(ctx context.Context, path string, query url.Values, token string, out any) error {
+  req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path+"?"+query.Encode(), nil)
+  if err != nil { return err }
+  req.Header.Set("Authorization", "Bearer "+token)
+
+  return c.do(req, out)
+}
+
+// token returns the cached ID token, refreshing it when forced or missing.
+func (c *Client) token(ctx context.Context, force bool) (string, error) {
+  c.mu.Lock()
+  defer c.mu.Unlock()
+
+  if c.idToken != "" && !force { return c.idToken, nil }
+
+  if c.RefreshToken != "" {
+    idToken, err := c.authRefresh(ctx)
+    if err == nil {
+      c.idToken = idToken
+      return idToken, nil
+    }
+    if !errors.Is(err, ErrUnauthorized) { return "", err }
+  }
+
+  // The refresh token lives for a week. Get a new one with the login.
+  if c.MailAddress == "" || c.Password == "" {
+    return "", fmt.Errorf("%w: refresh token is missing or expired and no mail address/password is set", ErrUnauthorized)
+  }
+  refreshToken, err := c.authUser(ctx)
+  if err != nil { return "", err }
+  c.RefreshToken = refreshToken
+
+  idToken, err := c.authRefresh(ctx)
+  if err != nil { return "", err }
+  c.idToken = idToken
+
+  return idToken, nil
+}
+
+func (c *Client) authUser(ctx context.Context) (string, error) {
+  body, err := json.Marshal(map[string]string{"mailaddress": c.MailAddress, "password": c.Password})
+  if err != nil { return "", err }
+
+  req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/v1/token/auth_user", bytes.NewReader(body))
+  if err != nil { return "", err }
+  req.Header.Set("Content-Type", "application/json")
+
+  var res struct {
+    RefreshToken string `json:"refreshToken"`
+  }
+  if err := c.do(req, &res); err != nil { return "", err }
+
+  return res.RefreshToken, nil
+}
+
+func (c *Client) authRefresh(ctx context.Context) (string, error) {
+  query := url.Values{}
+  query.Set("refreshtoken", c.RefreshToken)
+  req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/v1/token/auth_refresh?"+query.Encode(), nil)
+  if err != nil { return "", err }
+
+  var res struct {
+    IDToken string `json:"idToken"`
+  }
+  if err := c.do(req, &res); err != nil { return "", err }
+
+  return res.IDToken, nil
+}
+
+func (c *Client) do(req *http.Request, out any) error {
+  res, err := c.HTTPClient.Do(req)
+  if err != nil { return err }
+  defer res.Body.Close()
+
+  body, err := io.ReadAll(res.Body)
+  if err != nil { return err }
+
+  switch {
+    case res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden:
+      return fmt.Errorf("%w: %s %s: %s", ErrUnauthorized, req.Method, req.URL.Path, body)
+    case res.StatusCode >= 400:
+      return fmt.Errorf("jquants: %s %s: %s: %s", req.Method, req.URL.Path, res.Status, body)
+  }
+
+  return json.Unmarshal(body, out)
+}
@@ -0,0 +1,116 @@
+package jquants_test
+
+import (
+  "context"
+  "encoding/json"
+  "errors"
+  "net/http"
+  "net/http/httptest"
+  "os"
+  "testing"
+
+  "dunn-finance/pkg/jquants"
+)
+
+// fakeAPI is a stand-in for the J-Quants API. The ID token issued first is
+// rejected once to exercise the refresh.
+type fakeAPI struct {
+  t             *testing.T
+  refreshCalls  int
+  authUserCalls int
+  quoteCalls    int
+  expireFirst   bool
+  refreshToken  string
+}
+
+func (api *fakeAPI) handler() http.Handler {
+  mux := http.NewServeMux()
+  mux.HandleFunc("/v1/token/auth_user", func(w http.ResponseWriter, r *http.Request) {
+    api.authUserCalls++
+    var body map[string]string
+    if err := json.NewDecoder(r.Body).Decode(&body); err != nil { api.t.Fatal(err) }
+    if body["mailaddress"] != "user@example.com" || body["password"] != "pw" {
+      http.Error(w, `{"message":"invalid"}`, http.StatusBadRequest)
+      return
+    }
+    api.refreshToken = "refresh-2"
+    json.NewEncoder(w).Encode(map[string]string{"refreshToken": api.refreshToken})
+  })
+  mux.HandleFunc("/v1/token/auth_refresh", func(w http.ResponseWriter, r *http.Request) {
+    api.refreshCalls++
+    if r.URL.Query().Get("refreshtoken") != api.refreshToken {
+      http.Error(w, `{"message":"expired"}`, http.StatusUnauthorized)
+      return
+    }
+    json.NewEncoder(w).Encode(map[string]string{"idToken": "id-" + string(rune('0'+api.refreshCalls))})
+  })
+  mux.HandleFunc("/v1/prices/daily_quotes", func(w http.ResponseWriter, r *http.Request) {
+    api.quoteCalls++
+    if api.expireFirst && r.Header.Get("Authorization") == "Bearer id-1" {
+      http.Error(w, `{"message":"The incoming token is invalid or expired."}`, http.StatusUnauthorized)
+      return
+    }
+    if r.URL.Query().Get("code") != "52530" { api.t.Errorf("Unexpected code: %s", r.URL.Query().Get("code")) }
+
+    path := "testdata/daily_quotes_page1.json"
+    if r.URL.Query().Get("pagination_key") == "next-page" { path = "testdata/daily_quotes_page2.json" }
+    b, err := os.ReadFile(path)
+    if err != nil { api.t.Fatal(err) }
+    w.Write(b)
+  })
+
+  return mux
+}
+
+func newClient(t *testing.T, api *fakeAPI) *jquants.Client {
+  server := httptest.NewServer(api.handler())
+  t.Cleanup(server.Close)
+
+  return jquants.NewClient(server.URL)
+}
+
+func TestClient_DailyQuotes_Success(t *testing.T) {
+  api := &fakeAPI{t: t, refreshToken: "refresh-1"}
+  client := newClient(t, api)
+  client.RefreshToken = "refresh-1"
+
+  quotes, err := client.DailyQuotes(context.Background(), "52530", "20240301", "20240331")
+  if err != nil { t.Fatal(err) }
+  if len(quotes) != 3 { t.Fatalf("Expected quote length: 3, but is %d", len(quotes)) }
+  if api.quoteCalls != 2 { t.Errorf("Expected 2 pages to be fetched, but fetched %d", api.quoteCalls) }
+  if quotes[1].AdjustmentFactor != 0.5 { t.Errorf("Expected: 0.5, but got: %f", quotes[1].AdjustmentFactor) }
+  if quotes[2].AdjustmentClose != nil { t.Errorf("Expected: nil, but got: %f", *quotes[2].AdjustmentClose) }
+}
+
+func TestClient_DailyQuotes_RefreshesExpiredIDToken(t *testing.T) {
+  api := &fakeAPI{t: t, refreshToken: "refresh-1", expireFirst: true}
+  client := newClient(t, api)
+  client.RefreshToken = "refresh-1"
+
+  quotes, err := client.DailyQuotes(context.Background(), "52530", "", "")
+  if err != nil { t.Fatal(err) }
+  if len(quotes) != 3 { t.Errorf("Expected quote length: 3, but is %d", len(quotes)) }
+  if api.refreshCalls != 2 { t.Errorf("Expected the ID token to be refreshed twice, but refreshed %d times", api.refreshCalls) }
+}
+
+func TestClient_DailyQuotes_LogsInWhenRefreshTokenExpired(t *testing.T) {
+  api := &fakeAPI{t: t, refreshToken: "refresh-1"}
+  client := newClient(t, api)
+  client.RefreshToken = "expired"
+  client.MailAddress = "user@example.com"
+  client.Password = "pw"
+
+  _, err := client.DailyQuotes(context.Background(), "52530", "", "")
+  if err != nil { t.Fatal(err) }
+  if api.authUserCalls != 1 { t.Errorf("Expected 1 login, but got %d", api.authUserCalls) }
+  if client.RefreshToken != "refresh-2" { t.Errorf("Expected the new refresh token to be kept, got %s", client.RefreshToken) }
+}
+
+func TestClient_DailyQuotes_Failure(t *testing.T) {
+  api := &fakeAPI{t: t, refreshToken: "refresh-1"}
+  client := newClient(t, api)
+  client.RefreshToken = "expired"
+
+  _, err := client.DailyQuotes(context.Background(), "52530", "", "")
+  if !errors.Is(err, jquants.ErrUnauthorized) { t.Errorf("Expected ErrUnauthorized, got %v", err) }
+}
@@ -0,0 +1,110 @@
+package jquants
+
+import (
+  "context"
+  "fmt"
+  "time"
+
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/model"
+)
+
+// ToAPICode converts a 4-digit code to the 5-digit J-Quants code.
+func ToAPICode(code string) string {
+  if len(code) == 4 { return code + "0" }
+  return code
+}
+
+// FromAPICode converts a 5-digit J-Quants code such as 52530 to 5253.
+func FromAPICode(code string) string {
+  if len(code) == 5 && code[4] == '0' { return code[:4] }
+  return code
+}
+
+func ToAdjustedDailyOHLCV(q *DailyQuote) (*model.AdjustedDailyOHLCV, error) {
+  yyyymmdd, err := model.ParseDate(q.Date)
+  if err != nil { return nil, fmt.Errorf("failed to parse date %q: %w", q.Date, err) }
+
+  return &model.AdjustedDailyOHLCV{
+    Yyyymmdd:   yyyymmdd,
+    Code:       FromAPICode(q.Code),
+    OpenPrice:  q.AdjustmentOpen,
+    HighPrice:  q.AdjustmentHigh,
+    LowPrice:   q.AdjustmentLow,
+    ClosePrice: q.AdjustmentClose,
+    Volume:     q.AdjustmentVolume,
+  }, nil
+}
+
+// ToAdjustmentFactor returns nil for days without a corporate action.
+func ToAdjustmentFactor(q *DailyQuote) (*model.AdjustmentFactor, error) {
+  if q.AdjustmentFactor == 0 || q.AdjustmentFactor == 1 { return nil, nil }
+
+  yyyymmdd, err := model.ParseDate(q.Date)
+  if err != nil { return nil, fmt.Errorf("failed to parse date %q: %w", q.Date, err) }
+
+  return &model.AdjustmentFactor{
+    Yyyymmdd: yyyymmdd,
+    Code:     FromAPICode(q.Code),
+    Factor:   q.AdjustmentFactor,
+  }, nil
+}
+
+type ImportResult struct {
+  ImportID int64
+  Quotes   int
+  Factors  int
+}
+
+// Importer fetches daily quotes and upserts them in one transaction. Only
+// prices and volume are written so moving averages imported from SBI are kept.
+type Importer struct {
+  Client *Client
+  DB     database.DBConnector
+}
+
+func (im *Importer) Import(ctx context.Context, code string, from string, to string) (*ImportResult, error) {
+  quotes, err := im.Client.DailyQuotes(ctx, ToAPICode(code), from, to)
+  if err != nil { return nil, err }
+
+  var result *ImportResult
+  err = im.DB.WithTx(ctx, func(tx database.Querier) error {
+    importDao := dao.NewImportDAO(tx)
+    imp := &model.Import{Source: "jquants", Code: code, ImportedAt: time.Now(), RowsRead: len(quotes)}
+    if err := importDao.Create(ctx, imp); err != nil { return err }
+    if err := dao.NewStockDAO(tx).Register(ctx, code); err != nil { return err }
+
+    var err error
+    result, err = upsertQuotes(ctx, tx, imp.ID, quotes)
+    if err != nil { return err }
+    result.ImportID = imp.ID
+
+    return importDao.SetRowCounts(ctx, imp.ID, len(quotes), result.Quotes)
+  })
+  if err != nil { return nil, err }
+
+  return result, nil
+}
+
+func upsertQuotes(ctx context.Context, tx database.Querier, importID int64, quotes []DailyQuote) (*ImportResult, error) {
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(tx)
+  factorDao := dao.NewAdjustmentFactorDAO(tx)
+
+  result := &ImportResult{}
+  for i := range quotes {
+    ohlcv, err := ToAdjustedDailyOHLCV(&quotes[i])
+    if err != nil { return result, err }
+    ohlcv.ImportID = &importID
+    if err := ohlcvDao.UpsertPrices(ctx, ohlcv); err != nil { return result, fmt.Errorf("failed to upsert %s %s: %w", ohlcv.Code, ohlcv.Yyyymmdd, err) }
+    result.Quotes++
+
+    factor, err := ToAdjustmentFactor(&quotes[i])
+    if err != nil { return result, err }
+    if factor == nil { continue }
+    if err := factorDao.Create(ctx, factor); err != nil { return result, fmt.Errorf("failed to upsert factor %s %s: %w", factor.Code, factor.Yyyymmdd, err) }
+    result.Factors++
+  }
+
+  return result, nil
+}
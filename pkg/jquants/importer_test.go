@@ -0,0 +1,60 @@
+package jquants_test
+
+import (
+  "context"
+  "testing"
+
+  _ "github.com/mattn/go-sqlite3"
+
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/jquants"
+  "dunn-finance/pkg/model"
+)
+
+func TestCodeConversion(t *testing.T) {
+  if actual := jquants.ToAPICode("5253"); actual != "52530" { t.Errorf("got %s, want 52530", actual) }
+  if actual := jquants.FromAPICode("52530"); actual != "5253" { t.Errorf("got %s, want 5253", actual) }
+  if actual := jquants.FromAPICode("130A0"); actual != "130A" { t.Errorf("got %s, want 130A", actual) }
+}
+
+func TestImporter_Import_Success(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  stockDao := dao.NewStockDAO(db)
+  if err := stockDao.Create(context.Background(), &model.Stock{Code: "5253", Name: "カバー"}); err != nil { t.Fatal(err) }
+
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(db)
+  factorDao := dao.NewAdjustmentFactorDAO(db)
+
+  // A moving average imported from SBI before must survive the import.
+  dma := 1012.0
+  if err := ohlcvDao.Create(context.Background(), &model.AdjustedDailyOHLCV{Yyyymmdd: model.MustParseDate("20240327"), Code: "5253", DMAPrice5: &dma}); err != nil { t.Fatal(err) }
+
+  api := &fakeAPI{t: t, refreshToken: "refresh-1"}
+  client := newClient(t, api)
+  client.RefreshToken = "refresh-1"
+
+  importer := &jquants.Importer{Client: client, DB: db}
+  result, err := importer.Import(context.Background(), "5253", "20240301", "20240331")
+  if err != nil { t.Fatal(err) }
+  if result.Quotes != 3 { t.Errorf("Expected 3 quotes, got %d", result.Quotes) }
+  if result.Factors != 1 { t.Errorf("Expected 1 factor, got %d", result.Factors) }
+
+  ohlcv, err := ohlcvDao.Find(context.Background(), "5253", model.MustParseDate("20240327"))
+  if err != nil { t.Fatal(err) }
+  if *ohlcv.ClosePrice != 1010 { t.Errorf("Expected adjusted close 1010, got %f", *ohlcv.ClosePrice) }
+  if *ohlcv.Volume != 2000000 { t.Errorf("Expected adjusted volume 2000000, got %f", *ohlcv.Volume) }
+  if ohlcv.DMAPrice5 == nil || *ohlcv.DMAPrice5 != dma { t.Errorf("Expected DMA5 %f to be kept, got %v", dma, ohlcv.DMAPrice5) }
+  if ohlcv.ImportID == nil || *ohlcv.ImportID != result.ImportID { t.Errorf("Expected import %d, got %v", result.ImportID, ohlcv.ImportID) }
+
+  imp, err := dao.NewImportDAO(db).Find(context.Background(), result.ImportID)
+  if err != nil { t.Fatal(err) }
+  if imp.Source != "jquants" || imp.Code != "5253" || imp.RowsWritten != 3 { t.Errorf("Unexpected import: %+v", imp) }
+
+  suspended, err := ohlcvDao.Find(context.Background(), "5253", model.MustParseDate("20240329"))
+  if err != nil { t.Fatal(err) }
+  if suspended.ClosePrice != nil { t.Errorf("Expected: nil, but got: %f", *suspended.ClosePrice) }
+
+  factors, err := factorDao.FindByCode(context.Background(), "5253")
+  if err != nil { t.Fatal(err) }
+  if len(factors) != 1 || factors[0].Yyyymmdd.String() != "20240328" || factors[0].Factor != 0.5 { t.Errorf("Unexpected factors: %+v", factors) }
+}
@@ -0,0 +1,80 @@
+package mapper
+
+import (
+  "fmt"
+
+  "dunn-finance/pkg/dto"
+  "dunn-finance/pkg/model"
+)
+
+// ToAdjustedDailyOHLCVDTO keeps missing values, such as the moving averages
+// of the first days, nil so that they are null in JSON rather than 0. Values
+// are copied, so the DTO does not share memory with the model.
+func ToAdjustedDailyOHLCVDTO(m *model.AdjustedDailyOHLCV) *dto.AdjustedDailyOHLCV {
+  if m == nil { return nil }
+
+  return &dto.AdjustedDailyOHLCV{
+    Yyyymmdd:   m.Yyyymmdd.String(),
+    Code:       m.Code,
+    OpenPrice:  copyFloat(m.OpenPrice),
+    HighPrice:  copyFloat(m.HighPrice),
+    LowPrice:   copyFloat(m.LowPrice),
+    ClosePrice: copyFloat(m.ClosePrice),
+    DMAPrice5:  copyFloat(m.DMAPrice5),
+    DMAPrice25: copyFloat(m.DMAPrice25),
+    DMAPrice75: copyFloat(m.DMAPrice75),
+    VMAP:       copyFloat(m.VMAP),
+    Volume:     copyFloat(m.Volume),
+    VMA5:       copyFloat(m.VMA5),
+    VMA25:      copyFloat(m.VMA25),
+  }
+}
+
+// ToAdjustedDailyOHLCVModel fails when the date is not a valid yyyymmdd.
+func ToAdjustedDailyOHLCVModel(d *dto.AdjustedDailyOHLCV) (*model.AdjustedDailyOHLCV, error) {
+  if d == nil { return nil, nil }
+
+  yyyymmdd, err := model.ParseDate(d.Yyyymmdd)
+  if err != nil { return nil, fmt.Errorf("%s: %w", d.Code, err) }
+
+  return &model.AdjustedDailyOHLCV{
+    Yyyymmdd:   yyyymmdd,
+    Code:       d.Code,
+    OpenPrice:  copyFloat(d.OpenPrice),
+    HighPrice:  copyFloat(d.HighPrice),
+    LowPrice:   copyFloat(d.LowPrice),
+    ClosePrice: copyFloat(d.ClosePrice),
+    DMAPrice5:  copyFloat(d.DMAPrice5),
+    DMAPrice25: copyFloat(d.DMAPrice25),
+    DMAPrice75: copyFloat(d.DMAPrice75),
+    VMAP:       copyFloat(d.VMAP),
+    Volume:     copyFloat(d.Volume),
+    VMA5:       copyFloat(d.VMA5),
+    VMA25:      copyFloat(d.VMA25),
+  }, nil
+}
+
+func ToAdjustedDailyOHLCVDTOs(ms []*model.AdjustedDailyOHLCV) []*dto.AdjustedDailyOHLCV {
+  ds := make([]*dto.AdjustedDailyOHLCV, len(ms))
+  for i, m := range ms {
+    ds[i] = ToAdjustedDailyOHLCVDTO(m)
+  }
+  return ds
+}
+
+// ToAdjustedDailyOHLCVModels stops at the first invalid DTO.
+func ToAdjustedDailyOHLCVModels(ds []*dto.AdjustedDailyOHLCV) ([]*model.AdjustedDailyOHLCV, error) {
+  ms := make([]*model.AdjustedDailyOHLCV, len(ds))
+  for i, d := range ds {
+    m, err := ToAdjustedDailyOHLCVModel(d)
+    if err != nil { return nil, fmt.Errorf("item %d: %w", i, err) }
+    ms[i] = m
+  }
+  return ms, nil
+}
+
+func copyFloat(v *float64) *float64 {
+  if v == nil { return nil }
+  c := *v
+  return &c
+}
@@ -0,0 +1,67 @@
+package mapper_test
+
+import (
+  "encoding/json"
+  "errors"
+  "reflect"
+  "strings"
+  "testing"
+
+  "dunn-finance/pkg/csvreader"
+  "dunn-finance/pkg/dto"
+  "dunn-finance/pkg/mapper"
+  "dunn-finance/pkg/model"
+)
+
+func TestToAdjustedDailyOHLCVDTO_Success(t *testing.T) {
+  closePrice := 1020.0
+  ohlcv := &model.AdjustedDailyOHLCV{Yyyymmdd: model.MustParseDate("20250706"), Code: "1234", ClosePrice: &closePrice}
+
+  ohlcvDto := mapper.ToAdjustedDailyOHLCVDTO(ohlcv)
+  if ohlcvDto.Yyyymmdd != "20250706" { t.Errorf("got %s, want 20250706", ohlcvDto.Yyyymmdd) }
+  if ohlcvDto.Code != "1234" { t.Errorf("got %s, want 1234", ohlcvDto.Code) }
+  if ohlcvDto.ClosePrice == nil || *ohlcvDto.ClosePrice != closePrice { t.Errorf("got %v, want %f", ohlcvDto.ClosePrice, closePrice) }
+  if ohlcvDto.OpenPrice != nil { t.Errorf("Expected a missing open price to stay nil, got %f", *ohlcvDto.OpenPrice) }
+
+  *ohlcvDto.ClosePrice = 0
+  if *ohlcv.ClosePrice != closePrice { t.Error("Expected the DTO not to share values with the model") }
+
+  if mapper.ToAdjustedDailyOHLCVDTO(nil) != nil { t.Error("Expected nil for nil") }
+}
+
+func TestToAdjustedDailyOHLCVModel_InvalidDate(t *testing.T) {
+  _, err := mapper.ToAdjustedDailyOHLCVModel(&dto.AdjustedDailyOHLCV{Yyyymmdd: "20250230", Code: "1234"})
+  if !errors.Is(err, model.ErrInvalidDate) { t.Errorf("Expected ErrInvalidDate, got %v", err) }
+
+  _, err = mapper.ToAdjustedDailyOHLCVModels([]*dto.AdjustedDailyOHLCV{{Yyyymmdd: "20250228"}, {Yyyymmdd: ""}})
+  if err == nil || !strings.HasPrefix(err.Error(), "item 1:") { t.Errorf("Expected an error on item 1, got %v", err) }
+}
+
+// The oldest rows of the SBI export have no moving averages yet. They must
+// come back nil after a trip through JSON, not 0.
+func TestAdjustedDailyOHLCVDTO_RoundTrip(t *testing.T) {
+  ohlcvs, err := csvreader.LoadAdjustedDailyOHLCVsFromCSV("5253", "../csvreader/testdata/sbi_timechart_5253_20250720.csv", csvreader.SBIFieldMap, true, 0, 0)
+  if err != nil { t.Fatal(err) }
+
+  oldest := ohlcvs[len(ohlcvs)-1]
+  if oldest.Yyyymmdd.String() != "20230327" || oldest.DMAPrice5 != nil || oldest.VMA25 != nil { t.Fatalf("Expected a warm-up row without averages, got %+v", oldest) }
+
+  body, err := json.Marshal(mapper.ToAdjustedDailyOHLCVDTOs(ohlcvs))
+  if err != nil { t.Fatal(err) }
+
+  var dtos []*dto.AdjustedDailyOHLCV
+  if err := json.Unmarshal(body, &dtos); err != nil { t.Fatal(err) }
+  if last := dtos[len(dtos)-1]; last.DMAPrice5 != nil || last.DMAPrice75 != nil || last.VMA5 != nil { t.Errorf("Expected null averages, got %+v", last) }
+
+  roundTripped, err := mapper.ToAdjustedDailyOHLCVModels(dtos)
+  if err != nil { t.Fatal(err) }
+  if !reflect.DeepEqual(ohlcvs, roundTripped) { t.Error("Expected the bars to survive the round trip") }
+}
+
+func TestAdjustedDailyOHLCVDTO_NullJSON(t *testing.T) {
+  volume := 1000.0
+  body, err := json.Marshal(mapper.ToAdjustedDailyOHLCVDTO(&model.AdjustedDailyOHLCV{Yyyymmdd: model.MustParseDate("20250706"), Code: "1234", Volume: &volume}))
+  if err != nil { t.Fatal(err) }
+
+  if !strings.Contains(string(body), `"dma_price_5":null`) || !strings.Contains(string(body), `"volume":1000`) { t.Errorf("Unexpected JSON: %s", body) }
+}
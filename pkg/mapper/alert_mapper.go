@@ -0,0 +1,17 @@
+package mapper
+
+import (
+  "dunn-finance/pkg/dto"
+  "dunn-finance/pkg/model"
+)
+
+func ToAlertDTO(m *model.Alert) *dto.AlertDTO {
+  return &dto.AlertDTO{
+    ID:          m.ID,
+    RuleID:      m.RuleID,
+    Code:        m.Code,
+    Yyyymmdd:    m.Yyyymmdd.String(),
+    Message:     m.Message,
+    TriggeredAt: m.TriggeredAt,
+  }
+}
@@ -0,0 +1,19 @@
+package mapper_test
+
+import (
+  "testing"
+  "time"
+
+  "dunn-finance/pkg/mapper"
+  "dunn-finance/pkg/model"
+)
+
+func TestToAlertDTO_Success(t *testing.T) {
+  triggeredAt := time.Date(2025, 7, 18, 16, 0, 0, 0, time.UTC)
+  alert := &model.Alert{ID: 3, RuleID: 1, Code: "5253", Yyyymmdd: model.MustParseDate("20250718"), Message: "close crosses_above 2200", TriggeredAt: triggeredAt}
+
+  alertDto := mapper.ToAlertDTO(alert)
+  if alertDto.ID != 3 || alertDto.RuleID != 1 || alertDto.Code != "5253" || alertDto.Message != alert.Message { t.Errorf("Unexpected DTO: %+v", alertDto) }
+  if alertDto.Yyyymmdd != "20250718" { t.Errorf("got %s, want 20250718", alertDto.Yyyymmdd) }
+  if !alertDto.TriggeredAt.Equal(triggeredAt) { t.Errorf("got %v, want %v", alertDto.TriggeredAt, triggeredAt) }
+}
@@ -0,0 +1,25 @@
+package mapper
+
+import (
+  "dunn-finance/pkg/model"
+  marketdatav1 "dunn-finance/pkg/marketdata/v1"
+)
+
+func ToStockProto(m *model.Stock) *marketdatav1.Stock {
+  return &marketdatav1.Stock{
+    Code: m.Code,
+    Name: m.Name,
+  }
+}
+
+func ToOHLCVBarProto(m *model.AdjustedDailyOHLCV) *marketdatav1.OHLCVBar {
+  return &marketdatav1.OHLCVBar{
+    Code:   m.Code,
+    Date:   m.Yyyymmdd.String(),
+    Open:   copyFloat(m.OpenPrice),
+    High:   copyFloat(m.HighPrice),
+    Low:    copyFloat(m.LowPrice),
+    Close:  copyFloat(m.ClosePrice),
+    Volume: copyFloat(m.Volume),
+  }
+}
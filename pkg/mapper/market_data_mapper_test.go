@@ -0,0 +1,22 @@
+package mapper_test
+
+import (
+  "testing"
+
+  "dunn-finance/pkg/mapper"
+  "dunn-finance/pkg/model"
+)
+
+func TestToOHLCVBarProto_Success(t *testing.T) {
+  closePrice := 1020.0
+  ohlcv := &model.AdjustedDailyOHLCV{Yyyymmdd: model.MustParseDate("20250706"), Code: "1234", ClosePrice: &closePrice}
+
+  bar := mapper.ToOHLCVBarProto(ohlcv)
+  if bar.GetDate() != "20250706" { t.Errorf("got %s, want 20250706", bar.GetDate()) }
+  if bar.GetCode() != "1234" { t.Errorf("got %s, want 1234", bar.GetCode()) }
+  if bar.GetClose() != closePrice { t.Errorf("got %f, want %f", bar.GetClose(), closePrice) }
+  if bar.Open != nil { t.Errorf("Expected a missing open price to stay unset, got %f", *bar.Open) }
+
+  *bar.Close = 0
+  if *ohlcv.ClosePrice != closePrice { t.Error("Expected the proto not to share values with the model") }
+}
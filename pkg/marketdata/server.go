@@ -0,0 +1,132 @@
+// Package marketdata serves stocks and bars over gRPC. The service is defined
+// in proto/marketdata/v1 and its Go code is generated into the v1 package.
+package marketdata
+
+//go:generate protoc -I ../../proto --go_out=. --go_opt=module=dunn-finance/pkg/marketdata --go-grpc_out=. --go-grpc_opt=module=dunn-finance/pkg/marketdata marketdata/v1/market_data.proto
+
+import (
+  "context"
+  "database/sql"
+  "errors"
+  "log"
+
+  "google.golang.org/grpc"
+  "google.golang.org/grpc/codes"
+  "google.golang.org/grpc/status"
+
+  "dunn-finance/pkg/api"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/mapper"
+  marketdatav1 "dunn-finance/pkg/marketdata/v1"
+  "dunn-finance/pkg/model"
+)
+
+// Server implements the MarketData service on top of the DAOs. Paging works
+// like the REST API, with the same default and maximum limits.
+type Server struct {
+  marketdatav1.UnimplementedMarketDataServer
+
+  DB database.DBConnector
+}
+
+// Register adds the service to a gRPC server.
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+  marketdatav1.RegisterMarketDataServer(registrar, s)
+}
+
+func (s *Server) GetStock(ctx context.Context, req *marketdatav1.GetStockRequest) (*marketdatav1.Stock, error) {
+  stock, err := s.findStock(ctx, req.GetCode())
+  if err != nil { return nil, err }
+
+  return mapper.ToStockProto(stock), nil
+}
+
+func (s *Server) ListStocks(ctx context.Context, req *marketdatav1.ListStocksRequest) (*marketdatav1.ListStocksResponse, error) {
+  limit, offset, err := pagination(req.GetLimit(), req.GetOffset())
+  if err != nil { return nil, err }
+
+  stockDao := dao.NewStockDAO(s.DB)
+  total, err := stockDao.Count(ctx, "")
+  if err != nil { return nil, internal(err) }
+  stocks, err := stockDao.FindPage(ctx, limit, offset)
+  if err != nil { return nil, internal(err) }
+
+  res := &marketdatav1.ListStocksResponse{Total: int32(total)}
+  for _, stock := range stocks {
+    res.Stocks = append(res.Stocks, mapper.ToStockProto(stock))
+  }
+
+  return res, nil
+}
+
+func (s *Server) GetBars(ctx context.Context, req *marketdatav1.GetBarsRequest) (*marketdatav1.GetBarsResponse, error) {
+  stock, err := s.findStock(ctx, req.GetCode())
+  if err != nil { return nil, err }
+
+  limit, offset, err := pagination(req.GetLimit(), req.GetOffset())
+  if err != nil { return nil, err }
+  from, to, err := dateRange(req.GetFrom(), req.GetTo())
+  if err != nil { return nil, err }
+
+  res := &marketdatav1.GetBarsResponse{}
+  i := 0
+  for bar, err := range dao.NewAdjustedDailyOHLCVDAO(s.DB).IterateFiltered(ctx, []string{stock.Code}, from, to) {
+    if err != nil { return nil, internal(err) }
+    if i >= offset && i < offset+limit { res.Bars = append(res.Bars, mapper.ToOHLCVBarProto(bar)) }
+    i++
+  }
+  res.Total = int32(i)
+
+  return res, nil
+}
+
+func (s *Server) StreamBars(req *marketdatav1.StreamBarsRequest, stream grpc.ServerStreamingServer[marketdatav1.OHLCVBar]) error {
+  from, to, err := dateRange(req.GetFrom(), req.GetTo())
+  if err != nil { return err }
+
+  for bar, err := range dao.NewAdjustedDailyOHLCVDAO(s.DB).IterateFiltered(stream.Context(), req.GetCodes(), from, to) {
+    if err != nil { return internal(err) }
+    if err := stream.Send(mapper.ToOHLCVBarProto(bar)); err != nil { return err }
+  }
+
+  return nil
+}
+
+func (s *Server) findStock(ctx context.Context, code string) (*model.Stock, error) {
+  if code == "" { return nil, status.Error(codes.InvalidArgument, "code is required") }
+
+  stock, err := dao.NewStockDAO(s.DB).Find(ctx, code)
+  if errors.Is(err, sql.ErrNoRows) { return nil, status.Errorf(codes.NotFound, "stock %s not found", code) }
+  if err != nil { return nil, internal(err) }
+
+  return stock, nil
+}
+
+// internal logs the cause and hides it from the client, as the REST API does.
+func internal(err error) error {
+  log.Printf("[ERROR] %v\n", err)
+  return status.Error(codes.Internal, "internal server error")
+}
+
+func pagination(limit int32, offset int32) (int, int, error) {
+  if limit == 0 { limit = api.DefaultLimit }
+  if limit < 0 || limit > api.MaxLimit { return 0, 0, status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", api.MaxLimit) }
+  if offset < 0 { return 0, 0, status.Error(codes.InvalidArgument, "offset must not be negative") }
+
+  return int(limit), int(offset), nil
+}
+
+func dateRange(fromStr string, toStr string) (model.Date, model.Date, error) {
+  var from, to model.Date
+  var err error
+  if fromStr != "" {
+    if from, err = model.ParseDate(fromStr); err != nil { return from, to, status.Errorf(codes.InvalidArgument, "from: %v", err) }
+  }
+  if toStr != "" {
+    if to, err = model.ParseDate(toStr); err != nil { return from, to, status.Errorf(codes.InvalidArgument, "to: %v", err) }
+  }
+  if !from.IsZero() && !to.IsZero() && from.After(to) { return from, to, status.Errorf(codes.InvalidArgument, "from %s is after to %s", from, to) }
+
+  return from, to, nil
+}
@@ -0,0 +1,112 @@
+package marketdata_test
+
+import (
+  "context"
+  "io"
+  "net"
+  "testing"
+
+  _ "github.com/mattn/go-sqlite3"
+  "google.golang.org/grpc"
+  "google.golang.org/grpc/codes"
+  "google.golang.org/grpc/credentials/insecure"
+  "google.golang.org/grpc/status"
+  "google.golang.org/grpc/test/bufconn"
+
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/marketdata"
+  marketdatav1 "dunn-finance/pkg/marketdata/v1"
+  "dunn-finance/pkg/model"
+)
+
+func f(v float64) *float64 { return &v }
+
+func newTestClient(t *testing.T) marketdatav1.MarketDataClient {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+
+  stockDao := dao.NewStockDAO(db)
+  for _, stock := range []*model.Stock{{Code: "7203", Name: "トヨタ自動車"}, {Code: "1234", Name: "テスト会社"}, {Code: "9984", Name: "ソフトバンクグループ"}} {
+    if err := stockDao.Create(ctx, stock); err != nil { t.Fatal(err) }
+  }
+
+  ohlcvDao := dao.NewAdjustedDailyOHLCVDAO(db)
+  for _, code := range []string{"1234", "7203"} {
+    for i, date := range []string{"20250707", "20250708", "20250709", "20250710", "20250711"} {
+      price := 100 + float64(i)
+      ohlcv := &model.AdjustedDailyOHLCV{Yyyymmdd: model.MustParseDate(date), Code: code, OpenPrice: f(price), ClosePrice: f(price + 1), Volume: f(1000)}
+      if err := ohlcvDao.Create(ctx, ohlcv); err != nil { t.Fatal(err) }
+    }
+  }
+
+  listener := bufconn.Listen(1 << 20)
+  server := grpc.NewServer()
+  (&marketdata.Server{DB: db}).Register(server)
+  go server.Serve(listener)
+  t.Cleanup(server.Stop)
+
+  conn, err := grpc.NewClient("passthrough:///bufnet",
+    grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+    grpc.WithTransportCredentials(insecure.NewCredentials()))
+  if err != nil { t.Fatal(err) }
+  t.Cleanup(func() { conn.Close() })
+
+  return marketdatav1.NewMarketDataClient(conn)
+}
+
+func TestServer_GetStock(t *testing.T) {
+  client := newTestClient(t)
+
+  stock, err := client.GetStock(context.Background(), &marketdatav1.GetStockRequest{Code: "7203"})
+  if err != nil { t.Fatal(err) }
+  if stock.GetName() != "トヨタ自動車" { t.Errorf("Unexpected stock: %v", stock) }
+
+  _, err = client.GetStock(context.Background(), &marketdatav1.GetStockRequest{Code: "0000"})
+  if status.Code(err) != codes.NotFound { t.Errorf("Expected NotFound, got %v", err) }
+}
+
+func TestServer_ListStocks(t *testing.T) {
+  client := newTestClient(t)
+
+  res, err := client.ListStocks(context.Background(), &marketdatav1.ListStocksRequest{Limit: 2, Offset: 1})
+  if err != nil { t.Fatal(err) }
+  if res.GetTotal() != 3 || len(res.GetStocks()) != 2 || res.GetStocks()[0].GetCode() != "7203" { t.Errorf("Unexpected response: %v", res) }
+
+  _, err = client.ListStocks(context.Background(), &marketdatav1.ListStocksRequest{Limit: 1001})
+  if status.Code(err) != codes.InvalidArgument { t.Errorf("Expected InvalidArgument, got %v", err) }
+}
+
+func TestServer_GetBars(t *testing.T) {
+  client := newTestClient(t)
+
+  res, err := client.GetBars(context.Background(), &marketdatav1.GetBarsRequest{Code: "1234", From: "2025-07-08", To: "20250710", Limit: 2, Offset: 1})
+  if err != nil { t.Fatal(err) }
+  if res.GetTotal() != 3 || len(res.GetBars()) != 2 { t.Fatalf("Unexpected response: %v", res) }
+  bar := res.GetBars()[0]
+  if bar.GetDate() != "20250709" || bar.GetOpen() != 102 || bar.GetClose() != 103 { t.Errorf("Unexpected bar: %v", bar) }
+  if bar.High != nil { t.Errorf("Expected a missing high to stay unset") }
+
+  _, err = client.GetBars(context.Background(), &marketdatav1.GetBarsRequest{Code: "1234", From: "20250710", To: "20250701"})
+  if status.Code(err) != codes.InvalidArgument { t.Errorf("Expected InvalidArgument, got %v", err) }
+}
+
+func TestServer_StreamBars(t *testing.T) {
+  client := newTestClient(t)
+
+  stream, err := client.StreamBars(context.Background(), &marketdatav1.StreamBarsRequest{From: "20250710"})
+  if err != nil { t.Fatal(err) }
+
+  var got []string
+  for {
+    bar, err := stream.Recv()
+    if err == io.EOF { break }
+    if err != nil { t.Fatal(err) }
+    got = append(got, bar.GetCode()+":"+bar.GetDate())
+  }
+
+  expected := []string{"1234:20250710", "1234:20250711", "7203:20250710", "7203:20250711"}
+  if len(got) != len(expected) { t.Fatalf("got %v, want %v", got, expected) }
+  for i := range expected {
+    if got[i] != expected[i] { t.Errorf("got %v, want %v", got, expected) }
+  }
+}
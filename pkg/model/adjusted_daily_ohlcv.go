@@ -1,17 +1,19 @@
 package model
 
 type AdjustedDailyOHLCV struct {
-  Yyyymmdd   string
-  Code       string
-  OpenPrice  *float64
-  HighPrice  *float64
-  LowPrice   *float64
-  ClosePrice *float64
-  DMAPrice5  *float64
-  DMAPrice25 *float64
-  DMAPrice75 *float64
-  VMAP       *float64
-  Volume     *float64
-  VMA5       *float64
-  VMA25      *float64
+  Yyyymmdd   Date     `db:"yyyymmdd,pk"`
+  Code       string   `db:"code,pk"`
+  OpenPrice  *float64 `db:"open_price"`
+  HighPrice  *float64 `db:"high_price"`
+  LowPrice   *float64 `db:"low_price"`
+  ClosePrice *float64 `db:"close_price"`
+  DMAPrice5  *float64 `db:"dma_price_5"`
+  DMAPrice25 *float64 `db:"dma_price_25"`
+  DMAPrice75 *float64 `db:"dma_price_75"`
+  VMAP       *float64 `db:"vmap"`
+  Volume     *float64 `db:"volume"`
+  VMA5       *float64 `db:"vma_5"`
+  VMA25      *float64 `db:"vma_25"`
+  // ImportID is the import that last wrote the row, nil when untracked.
+  ImportID   *int64   `db:"import_id"`
 }
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// AdjustedDailyOHLCVHistory is the old values of an adjusted daily OHLCV,
+// saved by a trigger when an update changed them.
+type AdjustedDailyOHLCVHistory struct {
+  ID                 int64     `db:"id,pk"`
+  Yyyymmdd           Date      `db:"yyyymmdd"`
+  Code               string    `db:"code"`
+  OpenPrice          *float64  `db:"open_price"`
+  HighPrice          *float64  `db:"high_price"`
+  LowPrice           *float64  `db:"low_price"`
+  ClosePrice         *float64  `db:"close_price"`
+  DMAPrice5          *float64  `db:"dma_price_5"`
+  DMAPrice25         *float64  `db:"dma_price_25"`
+  DMAPrice75         *float64  `db:"dma_price_75"`
+  VMAP               *float64  `db:"vmap"`
+  Volume             *float64  `db:"volume"`
+  VMA5               *float64  `db:"vma_5"`
+  VMA25              *float64  `db:"vma_25"`
+  ImportID           *int64    `db:"import_id"`
+  ReplacedByImportID *int64    `db:"replaced_by_import_id"`
+  ReplacedAt         time.Time `db:"replaced_at"`
+}
@@ -0,0 +1,9 @@
+package model
+
+// AdjustmentFactor is a corporate action such as a stock split effective on
+// Yyyymmdd. A 1:2 split has Factor 0.5.
+type AdjustmentFactor struct {
+  Yyyymmdd Date    `db:"yyyymmdd,pk"`
+  Code     string  `db:"code,pk"`
+  Factor   float64 `db:"factor"`
+}
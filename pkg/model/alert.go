@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// AlertRule is a condition checked against the latest bar of a code after
+// each import, such as "close crosses_above 2200". See alert.Parse for the
+// syntax. A rule with an empty Code applies to every code.
+type AlertRule struct {
+  ID         int64     `db:"id,pk"`
+  Code       string    `db:"code"`
+  Expression string    `db:"expression"`
+  Note       string    `db:"note"`
+  Enabled    bool      `db:"enabled"`
+  CreatedAt  time.Time `db:"created_at"`
+}
+
+// Alert records a rule triggered by the bar of Yyyymmdd.
+type Alert struct {
+  ID          int64     `db:"id,pk"`
+  RuleID      int64     `db:"rule_id"`
+  Code        string    `db:"code"`
+  Yyyymmdd    Date      `db:"yyyymmdd"`
+  Message     string    `db:"message"`
+  TriggeredAt time.Time `db:"triggered_at"`
+}
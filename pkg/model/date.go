@@ -0,0 +1,222 @@
+package model
+
+import (
+  "database/sql/driver"
+  "errors"
+  "fmt"
+  "regexp"
+  "strconv"
+  "strings"
+  "time"
+)
+
+// Date is a calendar date without a time of day. It is stored and marshalled
+// as yyyymmdd, e.g. "20250718", which is how dates have always been kept, so
+// existing rows and JSON read back unchanged. Dates compare with == and work
+// as map keys. The zero Date is invalid and is refused by Value.
+type Date struct {
+  t time.Time
+}
+
+const yyyymmdd = "20060102"
+
+var ErrInvalidDate = errors.New("invalid date")
+
+func NewDate(year int, month time.Month, day int) Date {
+  return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// DateOf returns the date of t in its own location.
+func DateOf(t time.Time) Date {
+  return NewDate(t.Date())
+}
+
+var (
+  separatedDate = regexp.MustCompile(`^(\d{4})[/\-.](\d{1,2})[/\-.](\d{1,2})$`)
+  kanjiDate     = regexp.MustCompile(`^(\d{4})年(\d{1,2})月(\d{1,2})日$`)
+  eraDate       = regexp.MustCompile(`^(令和|平成|昭和|R|H|S)(元|\d{1,2})(?:年|[/\-.])(\d{1,2})(?:月|[/\-.])(\d{1,2})日?$`)
+)
+
+type era struct {
+  start Date
+  end   Date // first day of the next era, zero for the current one
+}
+
+var eras = map[string]era{
+  "令和": {start: NewDate(2019, time.May, 1)},
+  "平成": {start: NewDate(1989, time.January, 8), end: NewDate(2019, time.May, 1)},
+  "昭和": {start: NewDate(1926, time.December, 25), end: NewDate(1989, time.January, 8)},
+}
+
+var eraLetters = map[string]string{"R": "令和", "H": "平成", "S": "昭和"}
+
+// ParseDate reads the formats found in Japanese sources: 20250718,
+// 2025/07/18, 2025-07-18, 2025年7月18日 and era dates such as 令和7年7月18日,
+// 令和元年5月1日 or R7.7.18. Full-width digits are accepted. Dates that do not
+// exist, such as 2025/02/30 or 平成32年1月1日, are rejected.
+func ParseDate(s string) (Date, error) {
+  s = normalizeDate(s)
+
+  if len(s) == 8 && isDigits(s) {
+    return newCheckedDate(s, atoi(s[:4]), atoi(s[4:6]), atoi(s[6:]))
+  }
+  if m := separatedDate.FindStringSubmatch(s); m != nil {
+    return newCheckedDate(s, atoi(m[1]), atoi(m[2]), atoi(m[3]))
+  }
+  if m := kanjiDate.FindStringSubmatch(s); m != nil {
+    return newCheckedDate(s, atoi(m[1]), atoi(m[2]), atoi(m[3]))
+  }
+  if m := eraDate.FindStringSubmatch(s); m != nil {
+    name := m[1]
+    if full, ok := eraLetters[name]; ok { name = full }
+    e := eras[name]
+
+    year := 1
+    if m[2] != "元" { year = atoi(m[2]) }
+
+    d, err := newCheckedDate(s, e.start.t.Year()+year-1, atoi(m[3]), atoi(m[4]))
+    if err != nil { return Date{}, err }
+    if year < 1 || d.Before(e.start) || (!e.end.IsZero() && !d.Before(e.end)) {
+      return Date{}, fmt.Errorf("%w: %q is outside of %s", ErrInvalidDate, s, name)
+    }
+
+    return d, nil
+  }
+
+  return Date{}, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+}
+
+// MustParseDate is ParseDate for constants. It panics on error.
+func MustParseDate(s string) Date {
+  d, err := ParseDate(s)
+  if err != nil { panic(err) }
+  return d
+}
+
+func newCheckedDate(s string, year int, month int, day int) (Date, error) {
+  d := NewDate(year, time.Month(month), day)
+  // time.Date normalizes 2025/02/30 to 2025/03/02.
+  if y, m, dd := d.t.Date(); y != year || int(m) != month || dd != day || year < 1 {
+    return Date{}, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+  }
+
+  return d, nil
+}
+
+func normalizeDate(s string) string {
+  s = strings.TrimSpace(s)
+  return strings.Map(func(r rune) rune {
+    switch {
+      case r >= '０' && r <= '９':
+        return '0' + (r - '０')
+      case r == '／':
+        return '/'
+      case r == '－':
+        return '-'
+      case r == '．':
+        return '.'
+    }
+    return r
+  }, s)
+}
+
+func isDigits(s string) bool {
+  for i := 0; i < len(s); i++ {
+    if s[i] < '0' || s[i] > '9' { return false }
+  }
+  return true
+}
+
+func atoi(s string) int {
+  n, _ := strconv.Atoi(s)
+  return n
+}
+
+// String returns yyyymmdd, or "" for the zero Date.
+func (d Date) String() string {
+  if d.IsZero() { return "" }
+  return d.t.Format(yyyymmdd)
+}
+
+func (d Date) IsZero() bool {
+  return d.t.IsZero()
+}
+
+// Time returns midnight UTC of the date.
+func (d Date) Time() time.Time {
+  return d.t
+}
+
+func (d Date) AddDays(n int) Date {
+  return Date{d.t.AddDate(0, 0, n)}
+}
+
+func (d Date) Before(other Date) bool {
+  return d.t.Before(other.t)
+}
+
+func (d Date) After(other Date) bool {
+  return d.t.After(other.t)
+}
+
+// Value stores the date as yyyymmdd text. The zero Date is an error, so a
+// row whose date failed to parse is never written.
+func (d Date) Value() (driver.Value, error) {
+  if d.IsZero() { return nil, fmt.Errorf("%w: zero date", ErrInvalidDate) }
+  return d.String(), nil
+}
+
+// Scan reads yyyymmdd stored as text or as an integer, and timestamps.
+func (d *Date) Scan(src any) error {
+  switch v := src.(type) {
+    case string:
+      return d.scanString(v)
+    case []byte:
+      return d.scanString(string(v))
+    case int64:
+      return d.scanString(strconv.FormatInt(v, 10))
+    case time.Time:
+      *d = NewDate(v.Date())
+      return nil
+    default:
+      return fmt.Errorf("%w: cannot scan %T", ErrInvalidDate, src)
+  }
+}
+
+func (d *Date) scanString(s string) error {
+  parsed, err := ParseDate(s)
+  if err != nil { return err }
+  *d = parsed
+  return nil
+}
+
+func (d Date) MarshalText() ([]byte, error) {
+  return []byte(d.String()), nil
+}
+
+func (d *Date) UnmarshalText(text []byte) error {
+  if len(text) == 0 {
+    *d = Date{}
+    return nil
+  }
+  return d.scanString(string(text))
+}
+
+// MarshalJSON writes "yyyymmdd", or null for the zero Date.
+func (d Date) MarshalJSON() ([]byte, error) {
+  if d.IsZero() { return []byte("null"), nil }
+  return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON accepts a string in any format ParseDate reads, a yyyymmdd
+// number and null.
+func (d *Date) UnmarshalJSON(data []byte) error {
+  s := string(data)
+  if s == "null" {
+    *d = Date{}
+    return nil
+  }
+  if unquoted, err := strconv.Unquote(s); err == nil { s = unquoted }
+
+  return d.UnmarshalText([]byte(s))
+}
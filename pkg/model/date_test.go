@@ -0,0 +1,95 @@
+package model_test
+
+import (
+  "encoding/json"
+  "errors"
+  "testing"
+  "time"
+
+  "dunn-finance/pkg/model"
+)
+
+func TestParseDate_Success(t *testing.T) {
+  expected := model.NewDate(2025, time.July, 18)
+  for _, s := range []string{
+    "20250718",
+    "2025/07/18",
+    "2025/7/18",
+    "2025-07-18",
+    "2025.07.18",
+    " 2025/07/18 ",
+    "２０２５／０７／１８",
+    "2025年7月18日",
+    "令和7年7月18日",
+    "R7.7.18",
+    "R07/07/18",
+  } {
+    actual, err := model.ParseDate(s)
+    if err != nil { t.Errorf("%q: %v", s, err); continue }
+    if actual != expected { t.Errorf("%q: got %s, want %s", s, actual, expected) }
+  }
+
+  if d := model.MustParseDate("令和元年5月1日"); d != model.NewDate(2019, time.May, 1) { t.Errorf("令和元年5月1日: got %s", d) }
+  if d := model.MustParseDate("平成31年4月30日"); d != model.NewDate(2019, time.April, 30) { t.Errorf("平成31年4月30日: got %s", d) }
+  if d := model.MustParseDate("H1.1.8"); d != model.NewDate(1989, time.January, 8) { t.Errorf("H1.1.8: got %s", d) }
+}
+
+func TestParseDate_Failure(t *testing.T) {
+  for _, s := range []string{
+    "",
+    "2025/07/13 10:00",
+    "2025/02/30",
+    "20251301",
+    "2025071",
+    "平成31年5月1日",
+    "令和0年1月1日",
+    "昭和64年1月8日",
+    "M45.7.30",
+  } {
+    if _, err := model.ParseDate(s); !errors.Is(err, model.ErrInvalidDate) { t.Errorf("%q: want ErrInvalidDate, got %v", s, err) }
+  }
+}
+
+func TestDate_Value_Scan(t *testing.T) {
+  d := model.NewDate(2025, time.July, 18)
+  v, err := d.Value()
+  if err != nil { t.Fatal(err) }
+  if v != "20250718" { t.Errorf("got %v, want 20250718", v) }
+
+  if _, err := (model.Date{}).Value(); !errors.Is(err, model.ErrInvalidDate) { t.Errorf("Expected the zero date to be refused, got %v", err) }
+
+  for _, src := range []any{"20250718", []byte("20250718"), int64(20250718), time.Date(2025, 7, 18, 15, 0, 0, 0, time.UTC)} {
+    var scanned model.Date
+    if err := scanned.Scan(src); err != nil { t.Errorf("%T: %v", src, err); continue }
+    if scanned != d { t.Errorf("%T: got %s, want %s", src, scanned, d) }
+  }
+
+  var scanned model.Date
+  if err := scanned.Scan("2025/13/01"); err == nil { t.Error("Expected an error for a malformed stored date") }
+}
+
+func TestDate_JSON(t *testing.T) {
+  type row struct {
+    Yyyymmdd model.Date `json:"yyyymmdd"`
+  }
+
+  data, err := json.Marshal(row{model.NewDate(2025, time.July, 18)})
+  if err != nil { t.Fatal(err) }
+  if string(data) != `{"yyyymmdd":"20250718"}` { t.Errorf("got %s", data) }
+
+  for _, s := range []string{`{"yyyymmdd":"20250718"}`, `{"yyyymmdd":"2025-07-18"}`, `{"yyyymmdd":20250718}`} {
+    var r row
+    if err := json.Unmarshal([]byte(s), &r); err != nil { t.Errorf("%s: %v", s, err); continue }
+    if r.Yyyymmdd != model.NewDate(2025, time.July, 18) { t.Errorf("%s: got %s", s, r.Yyyymmdd) }
+  }
+
+  var r row
+  if err := json.Unmarshal([]byte(`{"yyyymmdd":null}`), &r); err != nil || !r.Yyyymmdd.IsZero() { t.Errorf("null: got %s, %v", r.Yyyymmdd, err) }
+  if err := json.Unmarshal([]byte(`{"yyyymmdd":"2025/02/30"}`), &r); err == nil { t.Error("Expected an error for 2025/02/30") }
+}
+
+func TestDate_AddDays(t *testing.T) {
+  d := model.NewDate(2024, time.February, 28)
+  if next := d.AddDays(1); next.String() != "20240229" { t.Errorf("got %s", next) }
+  if !d.Before(d.AddDays(1)) || !d.AddDays(1).After(d) { t.Error("Expected AddDays(1) to be after") }
+}
@@ -0,0 +1,9 @@
+package model
+
+type Fundamentals struct {
+  Code          string
+  PER           *float64
+  PBR           *float64
+  DividendYield *float64
+  MarketCap     *float64
+}
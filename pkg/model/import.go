@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Import records one run that wrote adjusted daily OHLCVs, such as a CSV file
+// or an API fetch. Rows refer to it by ImportID.
+type Import struct {
+  ID          int64     `db:"id,pk"`
+  Source      string    `db:"source"`
+  FilePath    string    `db:"file_path"`
+  FileHash    string    `db:"file_hash"`
+  Code        string    `db:"code"`
+  ImportedAt  time.Time `db:"imported_at"`
+  RowsRead    int       `db:"rows_read"`
+  RowsWritten int       `db:"rows_written"`
+}
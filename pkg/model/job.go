@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Statuses of a Job.
+const (
+  JobRunning   = "running"
+  JobSucceeded = "succeeded"
+  JobFailed    = "failed"
+)
+
+// Job records one run of a scheduled job of dunn daemon. FinishedAt is nil
+// while it runs. Summary tells what each step did, Error why it failed.
+type Job struct {
+  ID          int64      `db:"id,pk"`
+  Name        string     `db:"name"`
+  ScheduledAt time.Time  `db:"scheduled_at"`
+  StartedAt   time.Time  `db:"started_at"`
+  FinishedAt  *time.Time `db:"finished_at"`
+  Status      string     `db:"status"`
+  Summary     string     `db:"summary"`
+  Error       string     `db:"error"`
+}
@@ -1,6 +1,6 @@
 package model
 
 type Stock struct {
-  Code string
-  Name string
+  Code string `db:"code,pk"`
+  Name string `db:"name"`
 }
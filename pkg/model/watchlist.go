@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Watchlist is a named group of codes, such as the holdings or the codes of
+// a sector, that batch commands can run over with -watchlist.
+type Watchlist struct {
+  ID        int64     `db:"id,pk"`
+  Name      string    `db:"name"`
+  Notes     string    `db:"notes"`
+  CreatedAt time.Time `db:"created_at"`
+}
+
+// WatchlistCode is a code on a watchlist. A code may be on many lists.
+type WatchlistCode struct {
+  WatchlistID int64     `db:"watchlist_id,pk"`
+  Code        string    `db:"code,pk"`
+  Note        string    `db:"note"`
+  AddedAt     time.Time `db:"added_at"`
+}
@@ -0,0 +1,113 @@
+// Package report writes self-contained HTML reports of the recent performance
+// of codes. Charts are inlined as SVG and styles as CSS, so a report needs no
+// server and can be mailed or archived as a single file.
+package report
+
+import (
+  "bytes"
+  _ "embed"
+  "fmt"
+  "html/template"
+  "image/color"
+  "io"
+  "strconv"
+  "strings"
+  "time"
+
+  "dunn-finance/pkg/chart"
+  "dunn-finance/pkg/model"
+)
+
+// ChartBars is the number of the latest bars charted, about six months.
+const ChartBars = 130
+
+// Item is the section of one code.
+type Item struct {
+  Summary *Summary
+  Chart   template.HTML
+}
+
+type Report struct {
+  Title       string
+  GeneratedAt time.Time
+  Items       []*Item
+  // Colors of rises and falls, JapaneseColors when zero.
+  Colors      chart.Colors
+}
+
+// NewItem summarizes the bars of a code, oldest first, and charts the latest
+// ChartBars of them. It returns nil without bars.
+func NewItem(stock *model.Stock, bars []*model.AdjustedDailyOHLCV, colors chart.Colors) (*Item, error) {
+  summary := Summarize(stock, bars)
+  if summary == nil { return nil, nil }
+
+  var svg bytes.Buffer
+  opts := chart.Options{Width: 960, Height: 540, Volume: true, DMA: true, Colors: colors}
+  if err := chart.Render(&svg, chart.FormatSVG, bars[max(len(bars)-ChartBars, 0):], opts); err != nil { return nil, fmt.Errorf("%s: %w", stock.Code, err) }
+
+  // The chart is our own SVG, not user input.
+  return &Item{Summary: summary, Chart: template.HTML(svg.String())}, nil
+}
+
+//go:embed report.html.tmpl
+var templateText string
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+func Render(w io.Writer, r *Report) error {
+  colors := r.Colors
+  if colors == (chart.Colors{}) { colors = chart.JapaneseColors }
+
+  funcs := template.FuncMap{
+    "number": func(v *float64) string { return formatNumber(v) },
+    "percent": func(v *float64) string {
+      if v == nil { return "-" }
+      return fmt.Sprintf("%+.2f%%", *v)
+    },
+    "ratio": func(v *float64) string {
+      if v == nil { return "-" }
+      return fmt.Sprintf("%.2fx", *v)
+    },
+    // tone colors a change as a rise or a fall.
+    "tone": func(v *float64) template.CSS {
+      switch {
+        case v == nil || *v == 0:
+          return ""
+        case *v > 0:
+          return template.CSS("color: " + hex(colors.Up))
+        default:
+          return template.CSS("color: " + hex(colors.Down))
+      }
+    },
+    "deref": func(v *float64) float64 { return *v },
+    "date": func(d model.Date) string { return d.Time().Format("2006/01/02") },
+    "jst": func(t time.Time) string { return t.In(jst).Format("2006/01/02 15:04 MST") },
+  }
+
+  tmpl, err := template.New("report").Funcs(funcs).Parse(templateText)
+  if err != nil { return err }
+  return tmpl.Execute(w, r)
+}
+
+func hex(c color.RGBA) string {
+  return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// formatNumber prints v with thousands separators and at most two decimals,
+// as adjusted prices are often fractional.
+func formatNumber(v *float64) string {
+  if v == nil { return "-" }
+
+  s := strconv.FormatFloat(*v, 'f', 2, 64)
+  s = strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+  sign := ""
+  if strings.HasPrefix(s, "-") { sign, s = "-", s[1:] }
+  integer, fraction, hasFraction := strings.Cut(s, ".")
+  var sb strings.Builder
+  for i, r := range integer {
+    if i > 0 && (len(integer)-i)%3 == 0 { sb.WriteByte(',') }
+    sb.WriteRune(r)
+  }
+  if hasFraction { return sign + sb.String() + "." + fraction }
+  return sign + sb.String()
+}
@@ -0,0 +1,92 @@
+package report_test
+
+import (
+  "bytes"
+  "math"
+  "strings"
+  "testing"
+  "time"
+
+  "dunn-finance/pkg/chart"
+  "dunn-finance/pkg/model"
+  "dunn-finance/pkg/report"
+)
+
+func f(v float64) *float64 { return &v }
+
+// dailyBars returns a bar for every weekday from 2024-06-03 to 2025-07-18,
+// closing 1 higher each day from 1000.
+func dailyBars() []*model.AdjustedDailyOHLCV {
+  var bars []*model.AdjustedDailyOHLCV
+  for d := model.MustParseDate("20240603"); !d.After(model.MustParseDate("20250718")); d = d.AddDays(1) {
+    if wd := d.Time().Weekday(); wd == time.Saturday || wd == time.Sunday { continue }
+    price := 1000 + float64(len(bars))
+    bars = append(bars, &model.AdjustedDailyOHLCV{
+      Yyyymmdd: d, Code: "5253",
+      OpenPrice: f(price - 1), HighPrice: f(price + 5), LowPrice: f(price - 5), ClosePrice: f(price), Volume: f(3000),
+    })
+  }
+  return bars
+}
+
+func closeOn(bars []*model.AdjustedDailyOHLCV, yyyymmdd string) float64 {
+  for _, bar := range bars {
+    if bar.Yyyymmdd.String() == yyyymmdd { return *bar.ClosePrice }
+  }
+  panic(yyyymmdd)
+}
+
+func assertPercent(t *testing.T, name string, got *float64, expected float64) {
+  t.Helper()
+  if got == nil || math.Abs(*got-expected) > 1e-9 { t.Errorf("%s: got %v, want %f", name, got, expected) }
+}
+
+func TestSummarize(t *testing.T) {
+  bars := dailyBars()
+  latest := bars[len(bars)-1]
+  latest.DMAPrice25, latest.DMAPrice75, latest.VMA25 = f(1250), f(1400), f(1000)
+  closePrice := *latest.ClosePrice
+
+  s := report.Summarize(&model.Stock{Code: "5253", Name: "カバー"}, bars)
+  if s.Date.String() != "20250718" || *s.Close != closePrice { t.Fatalf("Unexpected latest bar: %+v", s) }
+
+  assertPercent(t, "1W", s.Return1W, (closePrice/closeOn(bars, "20250711")-1)*100)
+  // 2025-04-18 was a Friday.
+  assertPercent(t, "3M", s.Return3M, (closePrice/closeOn(bars, "20250418")-1)*100)
+  // The last close of 2024 was on Tuesday 2024-12-31.
+  assertPercent(t, "YTD", s.ReturnYTD, (closePrice/closeOn(bars, "20241231")-1)*100)
+  assertPercent(t, "DMA25", s.DMA25Gap, (closePrice/1250-1)*100)
+  assertPercent(t, "Volume", s.VolumeRatio, 3)
+
+  // The 52 weeks start after 2024-07-18.
+  if *s.Low52W != closeOn(bars, "20240719")-5 || *s.High52W != closePrice+5 { t.Errorf("Unexpected 52 week range %f - %f", *s.Low52W, *s.High52W) }
+  assertPercent(t, "52W", s.Position52W, (closePrice-*s.Low52W)/(*s.High52W-*s.Low52W)*100)
+}
+
+func TestSummarize_ShortHistory(t *testing.T) {
+  bars := dailyBars()[:3]
+
+  s := report.Summarize(&model.Stock{Code: "5253"}, bars)
+  if s.Return1W != nil || s.ReturnYTD != nil || s.DMA25Gap != nil || s.VolumeRatio != nil { t.Errorf("Expected missing figures, got %+v", s) }
+  if report.Summarize(&model.Stock{Code: "5253"}, nil) != nil { t.Error("Expected nil without bars") }
+}
+
+func TestRender(t *testing.T) {
+  bars := dailyBars()
+  cover, err := report.NewItem(&model.Stock{Code: "5253", Name: "カバー"}, bars, chart.JapaneseColors)
+  if err != nil { t.Fatal(err) }
+  toyota, err := report.NewItem(&model.Stock{Code: "7203", Name: "<トヨタ>"}, bars[:200], chart.JapaneseColors)
+  if err != nil { t.Fatal(err) }
+
+  var buf bytes.Buffer
+  r := &report.Report{Title: "Watchlist", GeneratedAt: time.Date(2025, 7, 18, 7, 0, 0, 0, time.UTC), Items: []*report.Item{cover, toyota}}
+  if err := report.Render(&buf, r); err != nil { t.Fatal(err) }
+
+  html := buf.String()
+  for _, s := range []string{"<title>Watchlist</title>", "Generated 2025/07/18 16:00 JST", `<a href="#code-5253">5253</a>`, `id="code-7203"`, "&lt;トヨタ&gt;", "<td class=\"num\">1,294</td>", `style="color: #d32f2f"`} {
+    if !strings.Contains(html, s) { t.Errorf("Expected %s in the report", s) }
+  }
+  if strings.Count(html, "<svg") != 2 { t.Errorf("Expected a chart per code, got %d", strings.Count(html, "<svg")) }
+  // Nothing is loaded from elsewhere.
+  if strings.Contains(html, "src=") || strings.Contains(html, "<link") { t.Error("Expected a self-contained report") }
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+  "time"
+
+  "dunn-finance/pkg/model"
+)
+
+// Summary is the recent performance of one code as of its latest bar.
+// Percentages are nil when there are not enough bars to compute them.
+type Summary struct {
+  Code        string
+  Name        string
+  Date        model.Date
+  Close       *float64
+  // Returns of the close since the last close on or before 1 week, 1 month
+  // and 3 months ago, and since the last close of the previous year.
+  Return1W    *float64
+  Return1M    *float64
+  Return3M    *float64
+  ReturnYTD   *float64
+  // Distances of the close above the moving averages, in percent.
+  DMA25Gap    *float64
+  DMA75Gap    *float64
+  // VolumeRatio is the volume over its 25 day average.
+  VolumeRatio *float64
+  High52W     *float64
+  Low52W      *float64
+  // Position52W is where the close sits in the 52 week range, 0 at the low
+  // and 100 at the high.
+  Position52W *float64
+}
+
+// Summarize computes the summary from the bars of a code, oldest first. It
+// returns nil without bars.
+func Summarize(stock *model.Stock, bars []*model.AdjustedDailyOHLCV) *Summary {
+  if len(bars) == 0 { return nil }
+
+  latest := bars[len(bars)-1]
+  s := &Summary{Code: stock.Code, Name: stock.Name, Date: latest.Yyyymmdd, Close: latest.ClosePrice}
+  if latest.ClosePrice == nil { return s }
+  closePrice := *latest.ClosePrice
+  t := latest.Yyyymmdd.Time()
+
+  s.Return1W = change(closePrice, closeOnOrBefore(bars, model.DateOf(t.AddDate(0, 0, -7))))
+  s.Return1M = change(closePrice, closeOnOrBefore(bars, model.DateOf(t.AddDate(0, -1, 0))))
+  s.Return3M = change(closePrice, closeOnOrBefore(bars, model.DateOf(t.AddDate(0, -3, 0))))
+  s.ReturnYTD = change(closePrice, closeOnOrBefore(bars, model.NewDate(t.Year()-1, time.December, 31)))
+  s.DMA25Gap = change(closePrice, latest.DMAPrice25)
+  s.DMA75Gap = change(closePrice, latest.DMAPrice75)
+  if latest.Volume != nil && latest.VMA25 != nil && *latest.VMA25 > 0 { s.VolumeRatio = ptr(*latest.Volume / *latest.VMA25) }
+
+  yearAgo := model.DateOf(t.AddDate(-1, 0, 0))
+  for _, bar := range bars {
+    if !bar.Yyyymmdd.After(yearAgo) { continue }
+    if bar.HighPrice != nil && (s.High52W == nil || *bar.HighPrice > *s.High52W) { s.High52W = bar.HighPrice }
+    if bar.LowPrice != nil && (s.Low52W == nil || *bar.LowPrice < *s.Low52W) { s.Low52W = bar.LowPrice }
+  }
+  if s.High52W != nil && s.Low52W != nil && *s.High52W > *s.Low52W {
+    s.Position52W = ptr((closePrice - *s.Low52W) / (*s.High52W - *s.Low52W) * 100)
+  }
+
+  return s
+}
+
+// closeOnOrBefore returns the close of the last bar on or before the date,
+// or nil when every bar is later.
+func closeOnOrBefore(bars []*model.AdjustedDailyOHLCV, d model.Date) *float64 {
+  for i := len(bars) - 1; i >= 0; i-- {
+    if !bars[i].Yyyymmdd.After(d) { return bars[i].ClosePrice }
+  }
+  return nil
+}
+
+// change is the change from base to v in percent.
+func change(v float64, base *float64) *float64 {
+  if base == nil || *base == 0 { return nil }
+  return ptr((v / *base - 1) * 100)
+}
+
+func ptr(v float64) *float64 { return &v }
@@ -0,0 +1,135 @@
+package scheduler
+
+import (
+  "fmt"
+  "strconv"
+  "strings"
+  "time"
+)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+// Schedule is a cron expression read in JST: minute, hour, day of month,
+// month and day of week, e.g. "45 15 * * 1-5" for 15:45 on weekdays. A field
+// is *, a value, a range such as 1-5 or a list of them, each optionally
+// stepped with /n. Months and days of week may be JAN to DEC and SUN to
+// SAT; 7 is Sunday too. As in cron, a day matches either the day of month
+// or the day of week when both are restricted.
+type Schedule struct {
+  expr       string
+  minutes    uint64
+  hours      uint64
+  days       uint64
+  months     uint64
+  weekdays   uint64
+  anyDay     bool
+  anyWeekday bool
+}
+
+var (
+  monthNames   = []string{"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+  weekdayNames = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+)
+
+func ParseSchedule(expr string) (*Schedule, error) {
+  fields := strings.Fields(expr)
+  if len(fields) != 5 { return nil, fmt.Errorf("cron expression %q: want 5 fields, got %d", expr, len(fields)) }
+
+  s := &Schedule{expr: strings.Join(fields, " "), anyDay: fields[2] == "*", anyWeekday: fields[4] == "*"}
+  parsers := []struct {
+    bits  *uint64
+    min   int
+    max   int
+    names []string
+  }{
+    {&s.minutes, 0, 59, nil},
+    {&s.hours, 0, 23, nil},
+    {&s.days, 1, 31, nil},
+    {&s.months, 1, 12, monthNames},
+    {&s.weekdays, 0, 7, weekdayNames},
+  }
+  for i, p := range parsers {
+    bits, err := parseField(fields[i], p.min, p.max, p.names)
+    if err != nil { return nil, fmt.Errorf("cron expression %q: %w", expr, err) }
+    *p.bits = bits
+  }
+  if s.weekdays&(1<<7) != 0 { s.weekdays |= 1 }
+
+  return s, nil
+}
+
+func (s *Schedule) String() string { return s.expr }
+
+func parseField(field string, min int, max int, names []string) (uint64, error) {
+  var bits uint64
+  for _, part := range strings.Split(field, ",") {
+    rng, step := part, 1
+    if i := strings.Index(part, "/"); i >= 0 {
+      n, err := strconv.Atoi(part[i+1:])
+      if err != nil || n <= 0 { return 0, fmt.Errorf("invalid step in %q", part) }
+      rng, step = part[:i], n
+    }
+
+    lo, hi := min, max
+    if rng != "*" {
+      bounds := strings.SplitN(rng, "-", 2)
+      var err error
+      if lo, err = parseValue(bounds[0], min, max, names); err != nil { return 0, err }
+      hi = lo
+      if len(bounds) == 2 {
+        if hi, err = parseValue(bounds[1], min, max, names); err != nil { return 0, err }
+      } else if step > 1 {
+        // 5/15 is 5-max/15.
+        hi = max
+      }
+      if hi < lo { return 0, fmt.Errorf("invalid range %q", rng) }
+    }
+
+    for v := lo; v <= hi; v += step {
+      bits |= 1 << v
+    }
+  }
+
+  return bits, nil
+}
+
+func parseValue(s string, min int, max int, names []string) (int, error) {
+  for i, name := range names {
+    if name != "" && strings.EqualFold(s, name) { return i, nil }
+  }
+  v, err := strconv.Atoi(s)
+  if err != nil || v < min || v > max { return 0, fmt.Errorf("%q is not in %d-%d", s, min, max) }
+
+  return v, nil
+}
+
+// Next returns the first time after t, in JST, that the schedule matches.
+// It is zero when there is none within five years, as for February 30.
+func (s *Schedule) Next(t time.Time) time.Time {
+  t = t.In(jst).Truncate(time.Minute).Add(time.Minute)
+  limit := t.AddDate(5, 0, 0)
+
+  for t.Before(limit) {
+    switch {
+      case s.months&(1<<int(t.Month())) == 0:
+        t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, jst)
+      case !s.matchesDay(t):
+        t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, jst)
+      case s.hours&(1<<t.Hour()) == 0:
+        t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, jst)
+      case s.minutes&(1<<t.Minute()) == 0:
+        t = t.Add(time.Minute)
+      default:
+        return t
+    }
+  }
+
+  return time.Time{}
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+  day := s.days&(1<<t.Day()) != 0
+  weekday := s.weekdays&(1<<int(t.Weekday())) != 0
+  if s.anyDay || s.anyWeekday { return day && weekday }
+  return day || weekday
+}
@@ -0,0 +1,46 @@
+package scheduler_test
+
+import (
+  "testing"
+  "time"
+
+  "dunn-finance/pkg/scheduler"
+)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+func TestSchedule_Next(t *testing.T) {
+  // Friday 2025/07/18 16:00 JST.
+  from := time.Date(2025, time.July, 18, 16, 0, 0, 0, jst)
+  tests := []struct {
+    expr string
+    want time.Time
+  }{
+    {"45 15 * * 1-5", time.Date(2025, time.July, 21, 15, 45, 0, 0, jst)},
+    {"45 15 * * MON-FRI", time.Date(2025, time.July, 21, 15, 45, 0, 0, jst)},
+    {"*/15 * * * *", time.Date(2025, time.July, 18, 16, 15, 0, 0, jst)},
+    {"0 9,18 * * *", time.Date(2025, time.July, 18, 18, 0, 0, 0, jst)},
+    {"30 8 1 * *", time.Date(2025, time.August, 1, 8, 30, 0, 0, jst)},
+    {"0 0 1 jan *", time.Date(2026, time.January, 1, 0, 0, 0, 0, jst)},
+    {"0 12 * * 7", time.Date(2025, time.July, 20, 12, 0, 0, 0, jst)},
+    // Either the 25th or a Monday.
+    {"0 7 25 * 1", time.Date(2025, time.July, 21, 7, 0, 0, 0, jst)},
+    {"5/20 16 * * *", time.Date(2025, time.July, 18, 16, 5, 0, 0, jst)},
+    {"0 0 30 2 *", time.Time{}},
+  }
+  for _, tt := range tests {
+    s, err := scheduler.ParseSchedule(tt.expr)
+    if err != nil { t.Fatalf("ParseSchedule(%q): %v", tt.expr, err) }
+    if got := s.Next(from); !got.Equal(tt.want) { t.Errorf("%q: Next = %s, want %s", tt.expr, got, tt.want) }
+  }
+
+  // Times in other zones are read in JST: 06:45 UTC is 15:45 JST.
+  s, _ := scheduler.ParseSchedule("45 15 * * *")
+  if got := s.Next(time.Date(2025, time.July, 18, 6, 44, 0, 0, time.UTC)); !got.Equal(time.Date(2025, time.July, 18, 6, 45, 0, 0, time.UTC)) { t.Errorf("Next = %s", got) }
+}
+
+func TestParseSchedule_Failure(t *testing.T) {
+  for _, expr := range []string{"", "45 15 * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "5-1 * * * *", "*/0 * * * *", "* * * * FOO"} {
+    if _, err := scheduler.ParseSchedule(expr); err == nil { t.Errorf("Expected an error for %q", expr) }
+  }
+}
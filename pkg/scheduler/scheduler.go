@@ -0,0 +1,127 @@
+// Package scheduler runs jobs on cron schedules in JST, by default only on
+// the days the Tokyo Stock Exchange is open, and records every run in the
+// jobs table.
+package scheduler
+
+import (
+  "context"
+  "database/sql"
+  "errors"
+  "log"
+  "time"
+
+  "dunn-finance/pkg/calendar"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/model"
+)
+
+// Job is run at the times of Schedule. Run gets the last run of the job that
+// succeeded, nil before the first, and returns a summary of what it did.
+type Job struct {
+  Name     string
+  Schedule *Schedule
+  // AllDays runs the job on the days the exchange is closed too.
+  AllDays  bool
+  Run      func(ctx context.Context, last *model.Job) (string, error)
+}
+
+// Next returns the first time after t the job runs, zero if never within
+// five years.
+func (j *Job) Next(t time.Time) time.Time {
+  // A schedule may only match holidays, like "0 9 1 1 *".
+  limit := t.AddDate(5, 0, 0)
+  for t = j.Schedule.Next(t); !t.IsZero() && t.Before(limit); t = j.Schedule.Next(t) {
+    day := model.DateOf(t)
+    if j.AllDays || calendar.IsTradingDay(day) { return t }
+    // Closed days are skipped whole, as a minute schedule matches thousands
+    // of times over a weekend. Schedule.Next starts after the minute before.
+    open := calendar.NextTradingDay(day).Time()
+    t = time.Date(open.Year(), open.Month(), open.Day(), 0, 0, 0, 0, jst).Add(-time.Minute)
+  }
+  return time.Time{}
+}
+
+type Scheduler struct {
+  DB   database.Querier
+  Jobs []*Job
+  // Now is time.Now when nil.
+  Now  func() time.Time
+}
+
+func (s *Scheduler) now() time.Time {
+  if s.Now == nil { return time.Now() }
+  return s.Now()
+}
+
+// Run runs the jobs at their times until ctx is done. Jobs due at the same
+// time run one after another in the order of Jobs, and a job that is still
+// running at its next time skips it. Runs left running by a previous daemon
+// are marked failed first.
+func (s *Scheduler) Run(ctx context.Context) error {
+  n, err := dao.NewJobDAO(s.DB).FailRunning(ctx, "interrupted", s.now())
+  if err != nil { return err }
+  if n > 0 { log.Printf("[WARN] Marked %d interrupted job runs as failed\n", n) }
+
+  next := make(map[*Job]time.Time)
+  for _, job := range s.Jobs {
+    next[job] = job.Next(s.now())
+    if next[job].IsZero() {
+      log.Printf("[WARN] %s never runs: %s\n", job.Name, job.Schedule)
+      continue
+    }
+    log.Printf("[INFO] %s runs next at %s\n", job.Name, next[job].Format("2006/01/02 15:04 MST"))
+  }
+
+  for {
+    var at time.Time
+    for _, t := range next {
+      if !t.IsZero() && (at.IsZero() || t.Before(at)) { at = t }
+    }
+    if at.IsZero() { return errors.New("no job to run") }
+
+    timer := time.NewTimer(time.Until(at))
+    select {
+      case <-ctx.Done():
+        timer.Stop()
+        return nil
+      case <-timer.C:
+    }
+
+    for _, job := range s.Jobs {
+      if !next[job].Equal(at) { continue }
+      if _, err := s.RunJob(ctx, job, at); err != nil { log.Printf("[ERROR] Failed to record the run of %s: %v\n", job.Name, err) }
+      if ctx.Err() != nil { return nil }
+      next[job] = job.Next(s.now())
+      log.Printf("[INFO] %s runs next at %s\n", job.Name, next[job].Format("2006/01/02 15:04 MST"))
+    }
+  }
+}
+
+// RunJob runs the job scheduled at scheduledAt once and records the run. The
+// error of the job is in the returned run; the error returned is that of the
+// jobs table.
+func (s *Scheduler) RunJob(ctx context.Context, job *Job, scheduledAt time.Time) (*model.Job, error) {
+  jobDao := dao.NewJobDAO(s.DB)
+  last, err := jobDao.FindLast(ctx, job.Name, model.JobSucceeded)
+  if errors.Is(err, sql.ErrNoRows) { last, err = nil, nil }
+  if err != nil { return nil, err }
+
+  run := &model.Job{Name: job.Name, ScheduledAt: scheduledAt, StartedAt: s.now(), Status: model.JobRunning}
+  if err := jobDao.Start(ctx, run); err != nil { return nil, err }
+  log.Printf("[INFO] %s started\n", job.Name)
+
+  summary, err := job.Run(ctx, last)
+  finishedAt := s.now()
+  run.FinishedAt, run.Summary, run.Status = &finishedAt, summary, model.JobSucceeded
+  if err != nil {
+    run.Status, run.Error = model.JobFailed, err.Error()
+    log.Printf("[ERROR] %s failed: %v\n", job.Name, err)
+  } else {
+    log.Printf("[INFO] %s succeeded: %s\n", job.Name, summary)
+  }
+
+  // The run is recorded even when ctx was canceled during it.
+  if err := jobDao.Finish(context.WithoutCancel(ctx), run); err != nil { return run, err }
+  return run, nil
+}
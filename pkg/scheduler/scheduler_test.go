@@ -0,0 +1,83 @@
+package scheduler_test
+
+import (
+  "context"
+  "errors"
+  "testing"
+  "time"
+
+  _ "github.com/mattn/go-sqlite3"
+
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/model"
+  "dunn-finance/pkg/scheduler"
+)
+
+func TestJob_Next_SkipsHolidays(t *testing.T) {
+  s, err := scheduler.ParseSchedule("45 15 * * 1-5")
+  if err != nil { t.Fatal(err) }
+
+  // Monday 2025/07/21 is Marine Day.
+  from := time.Date(2025, time.July, 18, 16, 0, 0, 0, jst)
+  job := &scheduler.Job{Name: "eod", Schedule: s}
+  if got := job.Next(from); !got.Equal(time.Date(2025, time.July, 22, 15, 45, 0, 0, jst)) { t.Errorf("Next = %s", got) }
+
+  job.AllDays = true
+  if got := job.Next(from); !got.Equal(time.Date(2025, time.July, 21, 15, 45, 0, 0, jst)) { t.Errorf("Next = %s", got) }
+
+  // New Year's Day is never a trading day.
+  s, _ = scheduler.ParseSchedule("0 9 1 1 *")
+  if got := (&scheduler.Job{Name: "never", Schedule: s}).Next(from); !got.IsZero() { t.Errorf("Next = %s", got) }
+}
+
+func TestJob_Next_SkipsClosedDays(t *testing.T) {
+  tests := []struct {
+    expr string
+    from time.Time
+    want time.Time
+  }{
+    // Friday night to Monday.
+    {"* * * * *", time.Date(2026, time.October, 23, 23, 59, 0, 0, jst), time.Date(2026, time.October, 26, 0, 0, 0, 0, jst)},
+    // The year-end closure from December 31 to January 3, then a weekend.
+    {"*/5 * * * *", time.Date(2026, time.December, 30, 23, 59, 0, 0, jst), time.Date(2027, time.January, 4, 0, 0, 0, 0, jst)},
+    {"30 9 * * *", time.Date(2026, time.December, 30, 10, 0, 0, 0, jst), time.Date(2027, time.January, 4, 9, 30, 0, 0, jst)},
+  }
+
+  for _, tt := range tests {
+    s, err := scheduler.ParseSchedule(tt.expr)
+    if err != nil { t.Fatal(err) }
+    job := &scheduler.Job{Name: "minutely", Schedule: s}
+    if got := job.Next(tt.from); !got.Equal(tt.want) { t.Errorf("%q from %s: Next = %s, want %s", tt.expr, tt.from, got, tt.want) }
+  }
+}
+
+func TestScheduler_RunJob(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+  now := time.Date(2025, time.July, 18, 15, 45, 0, 0, jst)
+  s := &scheduler.Scheduler{DB: db, Now: func() time.Time { return now }}
+
+  var lasts []*model.Job
+  fail := true
+  job := &scheduler.Job{Name: "eod", Run: func(ctx context.Context, last *model.Job) (string, error) {
+    lasts = append(lasts, last)
+    if fail { return "fetch: 2 codes", errors.New("import: 1 of 3 files failed") }
+    return "import: 3 files", nil
+  }}
+
+  failed, err := s.RunJob(ctx, job, now)
+  if err != nil { t.Fatal(err) }
+  if failed.Status != model.JobFailed || failed.Error != "import: 1 of 3 files failed" || failed.Summary != "fetch: 2 codes" { t.Errorf("Unexpected run: %+v", failed) }
+
+  fail = false
+  succeeded, err := s.RunJob(ctx, job, now)
+  if err != nil { t.Fatal(err) }
+  if _, err := s.RunJob(ctx, job, now); err != nil { t.Fatal(err) }
+
+  // Only succeeded runs are passed to the next.
+  if len(lasts) != 3 || lasts[0] != nil || lasts[1] != nil || lasts[2] == nil || lasts[2].ID != succeeded.ID { t.Errorf("Unexpected last runs: %+v", lasts) }
+
+  recent, err := dao.NewJobDAO(db).FindRecent(ctx, "eod", 10)
+  if err != nil { t.Fatal(err) }
+  if len(recent) != 3 || recent[2].Status != model.JobFailed || recent[1].Status != model.JobSucceeded || recent[1].FinishedAt == nil { t.Errorf("Unexpected runs: %+v", recent) }
+}
@@ -0,0 +1,52 @@
+// Package screen finds the codes whose latest bar meets a set of conditions,
+// written as alert rule expressions.
+package screen
+
+import (
+  "context"
+  "sort"
+
+  "dunn-finance/pkg/alert"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/database"
+  "dunn-finance/pkg/model"
+)
+
+// Match is a code whose latest bar, Bar, meets every condition. Prev is the
+// bar before it, nil for a code with one bar.
+type Match struct {
+  Code string
+  Bar  *model.AdjustedDailyOHLCV
+  Prev *model.AdjustedDailyOHLCV
+}
+
+// Change is the change of the close from Prev in percent, nil when unknown.
+func (m *Match) Change() *float64 {
+  if m.Prev == nil || m.Prev.ClosePrice == nil || *m.Prev.ClosePrice == 0 || m.Bar.ClosePrice == nil { return nil }
+  change := (*m.Bar.ClosePrice / *m.Prev.ClosePrice - 1) * 100
+  return &change
+}
+
+// Screen returns the matches in code order. Every code is screened when
+// codes is empty.
+func Screen(ctx context.Context, db database.Querier, codes []string, conds []*alert.Condition) ([]*Match, error) {
+  latest, err := dao.NewAdjustedDailyOHLCVDAO(db).FindLatestN(ctx, codes, 2)
+  if err != nil { return nil, err }
+
+  var matches []*Match
+  for code, bars := range latest {
+    m := &Match{Code: code, Bar: bars[len(bars)-1]}
+    if len(bars) > 1 { m.Prev = bars[len(bars)-2] }
+    if holdsAll(conds, m) { matches = append(matches, m) }
+  }
+  sort.Slice(matches, func(i, j int) bool { return matches[i].Code < matches[j].Code })
+
+  return matches, nil
+}
+
+func holdsAll(conds []*alert.Condition, m *Match) bool {
+  for _, cond := range conds {
+    if !cond.Holds(m.Prev, m.Bar) { return false }
+  }
+  return true
+}
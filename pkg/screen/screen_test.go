@@ -0,0 +1,54 @@
+package screen_test
+
+import (
+  "context"
+  "testing"
+
+  _ "github.com/mattn/go-sqlite3"
+
+  "dunn-finance/pkg/alert"
+  "dunn-finance/pkg/dao"
+  "dunn-finance/pkg/model"
+  "dunn-finance/pkg/screen"
+)
+
+func f(v float64) *float64 { return &v }
+
+func TestScreen_Success(t *testing.T) {
+  db := dao.PrepareTestDB(t)
+  ctx := context.Background()
+
+  // Closes of the last two days, with the DMA75 and the volume against VMA25.
+  for code, bars := range map[string][][4]float64{
+    "1234": {{900, 1000, 1000, 1000}, {1100, 1000, 5000, 1000}},
+    "5253": {{2100, 2000, 1000, 1000}, {2200, 2000, 1000, 1000}},
+    "7203": {{2500, 2600, 1000, 1000}, {2400, 2600, 4000, 1000}},
+  } {
+    if err := dao.NewStockDAO(db).Create(ctx, &model.Stock{Code: code}); err != nil { t.Fatal(err) }
+    for i, bar := range bars {
+      ohlcv := &model.AdjustedDailyOHLCV{Yyyymmdd: model.MustParseDate("20250717").AddDays(i), Code: code, ClosePrice: f(bar[0]), DMAPrice75: f(bar[1]), Volume: f(bar[2]), VMA25: f(bar[3])}
+      if err := dao.NewAdjustedDailyOHLCVDAO(db).Create(ctx, ohlcv); err != nil { t.Fatal(err) }
+    }
+  }
+
+  parse := func(exprs ...string) []*alert.Condition {
+    var conds []*alert.Condition
+    for _, expr := range exprs {
+      cond, err := alert.Parse(expr)
+      if err != nil { t.Fatal(err) }
+      conds = append(conds, cond)
+    }
+    return conds
+  }
+
+  matches, err := screen.Screen(ctx, db, nil, parse("volume > 3x vma25"))
+  if err != nil { t.Fatal(err) }
+  if len(matches) != 2 || matches[0].Code != "1234" || matches[1].Code != "7203" { t.Fatalf("Unexpected matches: %+v", matches) }
+  if change := matches[0].Change(); change == nil || *change < 22.2 || *change > 22.3 { t.Errorf("Unexpected change: %v", change) }
+
+  matches, _ = screen.Screen(ctx, db, nil, parse("volume > 3x vma25", "close crosses_above dma75"))
+  if len(matches) != 1 || matches[0].Code != "1234" { t.Errorf("Unexpected matches: %+v", matches) }
+
+  matches, _ = screen.Screen(ctx, db, []string{"5253", "7203"}, parse("close > dma75"))
+  if len(matches) != 1 || matches[0].Code != "5253" { t.Errorf("Unexpected matches: %+v", matches) }
+}
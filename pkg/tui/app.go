@@ -0,0 +1,268 @@
+// Package tui is a terminal UI to browse stocks and their bars. It only
+// writes ANSI escapes, so it works in any terminal, including over SSH.
+package tui
+
+import (
+  "fmt"
+  "math"
+  "strings"
+
+  "dunn-finance/pkg/api"
+  "dunn-finance/pkg/model"
+)
+
+// Colors are ANSI SGR color codes of rising and falling candles.
+type Colors struct {
+  Up   string
+  Down string
+}
+
+var (
+  // JapaneseColors is the convention of Japanese brokers: red up, green down.
+  JapaneseColors = Colors{Up: "31", Down: "32"}
+  WesternColors  = Colors{Up: "32", Down: "31"}
+)
+
+const (
+  minVisible = 10
+  listWidth  = 28
+  axisWidth  = 9
+  help       = " ↑↓ code  ←→ pan  +/- zoom  w daily/weekly  q quit"
+)
+
+// App is the state of the UI. It draws nothing by itself: Run feeds it keys
+// and prints its View.
+type App struct {
+  Stocks []*model.Stock
+  // Load returns the daily bars of a code, oldest first.
+  Load   func(code string) ([]*model.AdjustedDailyOHLCV, error)
+  // Colors of the candles, JapaneseColors when zero.
+  Colors Colors
+
+  selected   int
+  scroll     int
+  weekly     bool
+  // visible is the number of bars on the chart, 0 for as many as fit.
+  visible    int
+  // pan is the number of the latest bars scrolled off to the right.
+  pan        int
+  chartWidth int
+
+  loadedCode string
+  daily      []*model.AdjustedDailyOHLCV
+  err        error
+}
+
+// HandleKey applies a key as decoded by Run and reports whether to quit.
+func (a *App) HandleKey(key string) bool {
+  page := 10
+  switch key {
+    case "q", "ctrl+c", "esc":
+      return true
+    case "up", "k":
+      a.selectStock(a.selected - 1)
+    case "down", "j":
+      a.selectStock(a.selected + 1)
+    case "pgup":
+      a.selectStock(a.selected - page)
+    case "pgdown":
+      a.selectStock(a.selected + page)
+    case "home", "g":
+      a.selectStock(0)
+    case "end", "G":
+      a.selectStock(len(a.Stocks) - 1)
+    case "left", "h":
+      a.pan += max(a.window()/4, 1)
+    case "right", "l":
+      a.pan = max(a.pan-max(a.window()/4, 1), 0)
+    case "+", "=":
+      a.visible = max(a.window()/2, minVisible)
+    case "-":
+      a.visible = a.window() * 2
+      if a.chartWidth > 0 && a.visible >= a.chartWidth { a.visible = 0 }
+    case "w":
+      a.weekly = !a.weekly
+      a.pan = 0
+  }
+  a.clampPan()
+  return false
+}
+
+func (a *App) selectStock(i int) {
+  i = min(max(i, 0), len(a.Stocks)-1)
+  if i == a.selected { return }
+  a.selected = i
+  a.pan = 0
+}
+
+// window is the number of bars the chart shows.
+func (a *App) window() int {
+  n := a.chartWidth
+  if a.visible > 0 && (n == 0 || a.visible < n) { n = a.visible }
+  return max(n, minVisible)
+}
+
+func (a *App) clampPan() {
+  a.pan = min(a.pan, max(len(a.bars())-a.window(), 0))
+}
+
+// ensureLoaded loads the bars of the selected code once.
+func (a *App) ensureLoaded() {
+  if len(a.Stocks) == 0 { return }
+  code := a.Stocks[a.selected].Code
+  if code == a.loadedCode { return }
+
+  a.loadedCode = code
+  a.daily, a.err = a.Load(code)
+}
+
+func (a *App) bars() []*model.AdjustedDailyOHLCV {
+  if a.weekly { return api.Resample(a.daily, api.IntervalWeekly) }
+  return a.daily
+}
+
+// View renders the screen as exactly height lines of width columns, joined
+// with CRLF as the terminal is in raw mode.
+func (a *App) View(width int, height int) string {
+  a.ensureLoaded()
+
+  leftWidth := min(listWidth, width/3)
+  rightWidth := width - leftWidth - 1
+  a.chartWidth = max(rightWidth-axisWidth, 1)
+  bodyHeight := max(height-1, 1)
+
+  left := a.listLines(leftWidth, bodyHeight)
+  right := a.detailLines(rightWidth, bodyHeight)
+  for len(right) < bodyHeight {
+    right = append(right, fit("", rightWidth))
+  }
+  lines := make([]string, 0, height)
+  for i := 0; i < bodyHeight; i++ {
+    lines = append(lines, left[i]+"│"+right[i])
+  }
+  if height > 1 { lines = append(lines, "\x1b[7m"+fit(help, width)+sgr("")) }
+
+  return strings.Join(lines, "\r\n")
+}
+
+func (a *App) listLines(width int, height int) []string {
+  lines := []string{fit(fmt.Sprintf(" Codes (%d)", len(a.Stocks)), width)}
+  rows := height - 1
+  if a.selected < a.scroll { a.scroll = a.selected }
+  if a.selected >= a.scroll+rows { a.scroll = a.selected - rows + 1 }
+
+  for i := a.scroll; len(lines) < height; i++ {
+    if i >= len(a.Stocks) {
+      lines = append(lines, fit("", width))
+      continue
+    }
+    line := fit(" "+a.Stocks[i].Code+" "+a.Stocks[i].Name, width)
+    if i == a.selected { line = "\x1b[7m" + line + sgr("") }
+    lines = append(lines, line)
+  }
+  return lines
+}
+
+func (a *App) detailLines(width int, height int) []string {
+  var lines []string
+  pad := func(s string) { lines = append(lines, fit(s, width)) }
+
+  if len(a.Stocks) == 0 {
+    pad(" No stocks in the database")
+    return lines
+  }
+  stock := a.Stocks[a.selected]
+  if a.err != nil {
+    pad(fmt.Sprintf(" %s: %v", stock.Code, a.err))
+    return lines
+  }
+
+  all := a.bars()
+  end := len(all) - a.pan
+  window := all[max(end-a.window(), 0):end]
+  view := "daily"
+  if a.weekly { view = "weekly" }
+  title := fmt.Sprintf(" %s %s  %s", stock.Code, stock.Name, view)
+  if len(window) > 0 { title += fmt.Sprintf("  %s - %s  %d bars", formatDate(window[0].Yyyymmdd), formatDate(window[len(window)-1].Yyyymmdd), len(window)) }
+  pad(title)
+  if len(window) == 0 {
+    pad(" No bars")
+    return lines
+  }
+
+  // The chart takes three fifths of the height and the table the rest.
+  chartHeight := max((height-1)*3/5, 2)
+  colors := a.Colors
+  if colors == (Colors{}) { colors = JapaneseColors }
+  for _, row := range candleRows(window, a.chartWidth, chartHeight, colors) {
+    lines = append(lines, row+strings.Repeat(" ", max(width-a.chartWidth-axisWidth, 0)))
+  }
+
+  pad(fmt.Sprintf(" %-10s %8s %8s %8s %8s %7s %11s", "Date", "Open", "High", "Low", "Close", "Chg%", "Volume"))
+  for i := len(window) - 1; i >= 0 && len(lines) < height; i-- {
+    bar := window[i]
+    change := "-"
+    if i > 0 && bar.ClosePrice != nil && window[i-1].ClosePrice != nil && *window[i-1].ClosePrice != 0 {
+      change = fmt.Sprintf("%+.2f", (*bar.ClosePrice / *window[i-1].ClosePrice - 1) * 100)
+    }
+    pad(fmt.Sprintf(" %-10s %8s %8s %8s %8s %7s %11s", formatDate(bar.Yyyymmdd), formatNumber(bar.OpenPrice, 0), formatNumber(bar.HighPrice, 0), formatNumber(bar.LowPrice, 0), formatNumber(bar.ClosePrice, 0), change, formatNumber(bar.Volume, 0)))
+  }
+  return lines
+}
+
+func formatDate(d model.Date) string {
+  return d.Time().Format("2006/01/02")
+}
+
+// candleRows draws the bars as braille candles, one per column, with the
+// price scale on the right. The wick takes the left dot of the column and
+// the body both.
+func candleRows(bars []*model.AdjustedDailyOHLCV, width int, height int, colors Colors) []string {
+  lo, hi := math.Inf(1), math.Inf(-1)
+  for _, bar := range bars {
+    for _, v := range []*float64{bar.HighPrice, bar.LowPrice, bar.OpenPrice, bar.ClosePrice} {
+      if v != nil { lo, hi = min(lo, *v), max(hi, *v) }
+    }
+  }
+  if math.IsInf(lo, 1) { lo, hi = 0, 1 }
+
+  dots := height*4 - 1
+  y := func(v float64) int {
+    if hi == lo { return dots / 2 }
+    return int(math.Round((hi - v) / (hi - lo) * float64(dots)))
+  }
+
+  canvas := newBrailleCanvas(width, height)
+  for i, bar := range bars {
+    if bar.OpenPrice == nil || bar.ClosePrice == nil { continue }
+    x := i * width / len(bars) * 2
+    color := colors.Up
+    if *bar.ClosePrice < *bar.OpenPrice { color = colors.Down }
+
+    if bar.HighPrice != nil && bar.LowPrice != nil {
+      for dy := y(*bar.HighPrice); dy <= y(*bar.LowPrice); dy++ {
+        canvas.set(x, dy, color)
+      }
+    }
+    for dy := y(max(*bar.OpenPrice, *bar.ClosePrice)); dy <= y(min(*bar.OpenPrice, *bar.ClosePrice)); dy++ {
+      canvas.set(x, dy, color)
+      canvas.set(x+1, dy, color)
+    }
+  }
+
+  rows := canvas.rows()
+  for i := range rows {
+    label := ""
+    switch i {
+      case 0:
+        label = formatNumber(&hi, 0)
+      case len(rows) - 1:
+        label = formatNumber(&lo, 0)
+      case len(rows) / 2:
+        mid := (hi + lo) / 2
+        label = formatNumber(&mid, 0)
+    }
+    rows[i] += fmt.Sprintf(" %*s", axisWidth-1, label)
+  }
+  return rows
+}
@@ -0,0 +1,60 @@
+package tui
+
+import (
+  "strings"
+)
+
+// brailleCanvas is a grid of dots drawn with braille characters, two dots
+// wide and four high per terminal cell. Each cell has one color.
+type brailleCanvas struct {
+  width  int
+  height int
+  cells  []rune
+  colors []string
+}
+
+func newBrailleCanvas(width int, height int) *brailleCanvas {
+  return &brailleCanvas{width: width, height: height, cells: make([]rune, width*height), colors: make([]string, width*height)}
+}
+
+// Bits of the dots of a braille character, by column and row.
+var brailleBits = [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+
+// set turns on the dot at x, y counted from the top left, and colors its
+// cell with an ANSI SGR code such as "31".
+func (b *brailleCanvas) set(x int, y int, color string) {
+  if x < 0 || y < 0 || x >= b.width*2 || y >= b.height*4 { return }
+  i := y/4*b.width + x/2
+  b.cells[i] |= brailleBits[x%2][y%4]
+  b.colors[i] = color
+}
+
+// rows returns the lines of the canvas with ANSI colors.
+func (b *brailleCanvas) rows() []string {
+  lines := make([]string, b.height)
+  for row := range lines {
+    var sb strings.Builder
+    current := ""
+    for col := 0; col < b.width; col++ {
+      i := row*b.width + col
+      if b.colors[i] != current {
+        current = b.colors[i]
+        sb.WriteString(sgr(current))
+      }
+      if b.cells[i] == 0 {
+        sb.WriteByte(' ')
+      } else {
+        sb.WriteRune(0x2800 + b.cells[i])
+      }
+    }
+    if current != "" { sb.WriteString(sgr("")) }
+    lines[row] = sb.String()
+  }
+  return lines
+}
+
+// sgr is the escape that sets the color, or resets it when empty.
+func sgr(code string) string {
+  if code == "" { return "\x1b[0m" }
+  return "\x1b[" + code + "m"
+}
@@ -0,0 +1,115 @@
+package tui
+
+import (
+  "context"
+  "errors"
+  "io"
+  "os"
+  "time"
+  "unicode/utf8"
+
+  "golang.org/x/term"
+)
+
+// Run shows the app full screen until it quits or ctx is done. in must be a
+// terminal, which is put in raw mode and restored on return.
+func Run(ctx context.Context, in *os.File, out *os.File, app *App) error {
+  fd := int(in.Fd())
+  if !term.IsTerminal(fd) { return errors.New("stdin is not a terminal") }
+  state, err := term.MakeRaw(fd)
+  if err != nil { return err }
+  defer term.Restore(fd, state)
+
+  // Switch to the alternate screen and hide the cursor, as less and vim do.
+  io.WriteString(out, "\x1b[?1049h\x1b[?25l")
+  defer io.WriteString(out, "\x1b[?25h\x1b[?1049l")
+
+  keys := make(chan string)
+  go readKeys(in, keys)
+
+  // Resizes are noticed by polling, which also works where SIGWINCH does not.
+  ticker := time.NewTicker(250 * time.Millisecond)
+  defer ticker.Stop()
+
+  width, height, dirty := 0, 0, true
+  for {
+    w, h, err := term.GetSize(int(out.Fd()))
+    if err != nil { w, h = 80, 24 }
+    if dirty || w != width || h != height {
+      width, height, dirty = w, h, false
+      if _, err := io.WriteString(out, "\x1b[H"+app.View(width, height)); err != nil { return err }
+    }
+
+    select {
+      case <-ctx.Done():
+        return nil
+      case key, ok := <-keys:
+        if !ok || app.HandleKey(key) { return nil }
+        dirty = true
+      case <-ticker.C:
+    }
+  }
+}
+
+// readKeys sends the keys read from in until it fails.
+func readKeys(in io.Reader, keys chan<- string) {
+  defer close(keys)
+  buf := make([]byte, 256)
+  for {
+    n, err := in.Read(buf)
+    for _, key := range decodeKeys(buf[:n]) {
+      keys <- key
+    }
+    if err != nil { return }
+  }
+}
+
+var escapes = map[string]string{
+  "[A": "up", "[B": "down", "[C": "right", "[D": "left",
+  "OA": "up", "OB": "down", "OC": "right", "OD": "left",
+  "[5~": "pgup", "[6~": "pgdown",
+  "[H": "home", "[1~": "home", "OH": "home",
+  "[F": "end", "[4~": "end", "OF": "end",
+}
+
+// decodeKeys turns the bytes of one read into key names: "up", "pgdown",
+// "ctrl+c", "enter", "esc" and so on, or the typed character. Unknown escape
+// sequences are dropped.
+func decodeKeys(b []byte) []string {
+  var keys []string
+  for len(b) > 0 {
+    switch b[0] {
+      case 0x1b:
+        if len(b) == 1 {
+          keys = append(keys, "esc")
+          return keys
+        }
+        // A sequence ends with its first letter or tilde after ESC.
+        end := 2
+        for end < len(b) && !isFinal(b[end-1], end) {
+          end++
+        }
+        if key, ok := escapes[string(b[1:end])]; ok { keys = append(keys, key) }
+        b = b[end:]
+        continue
+      case 0x03:
+        keys = append(keys, "ctrl+c")
+      case '\r', '\n':
+        keys = append(keys, "enter")
+      default:
+        r, size := utf8.DecodeRune(b)
+        keys = append(keys, string(r))
+        b = b[size:]
+        continue
+    }
+    b = b[1:]
+  }
+  return keys
+}
+
+// isFinal reports whether c, at position i of an escape sequence, ends it.
+// The byte after ESC is "[" or "O" and never ends it.
+func isFinal(c byte, i int) bool {
+  if i == 2 { return false }
+  return c == '~' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
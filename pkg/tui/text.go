@@ -0,0 +1,62 @@
+package tui
+
+import (
+  "strconv"
+  "strings"
+  "unicode/utf8"
+
+  "golang.org/x/text/width"
+)
+
+// cellWidth is the number of terminal columns a rune takes: two for wide
+// characters such as kanji and full-width katakana, one otherwise.
+func cellWidth(r rune) int {
+  switch width.LookupRune(r).Kind() {
+    case width.EastAsianWide, width.EastAsianFullwidth:
+      return 2
+    default:
+      return 1
+  }
+}
+
+// displayWidth is the number of terminal columns of s, which has no escapes.
+func displayWidth(s string) int {
+  w := 0
+  for _, r := range s {
+    w += cellWidth(r)
+  }
+  return w
+}
+
+// fit cuts s to n columns and pads it with spaces to exactly n, so that
+// columns line up whatever the script of the names.
+func fit(s string, n int) string {
+  var sb strings.Builder
+  w := 0
+  for len(s) > 0 {
+    r, size := utf8.DecodeRuneInString(s)
+    if w+cellWidth(r) > n { break }
+    sb.WriteRune(r)
+    w += cellWidth(r)
+    s = s[size:]
+  }
+  sb.WriteString(strings.Repeat(" ", n-w))
+  return sb.String()
+}
+
+// formatNumber prints v with thousands separators and the given decimals.
+func formatNumber(v *float64, decimals int) string {
+  if v == nil { return "-" }
+
+  s := strconv.FormatFloat(*v, 'f', decimals, 64)
+  sign := ""
+  if strings.HasPrefix(s, "-") { sign, s = "-", s[1:] }
+  integer, fraction, _ := strings.Cut(s, ".")
+  var sb strings.Builder
+  for i, r := range integer {
+    if i > 0 && (len(integer)-i)%3 == 0 { sb.WriteByte(',') }
+    sb.WriteRune(r)
+  }
+  if fraction != "" { return sign + sb.String() + "." + fraction }
+  return sign + sb.String()
+}
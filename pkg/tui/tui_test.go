@@ -0,0 +1,124 @@
+package tui
+
+import (
+  "errors"
+  "regexp"
+  "slices"
+  "strings"
+  "testing"
+
+  "dunn-finance/pkg/model"
+)
+
+var ansi = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func f(v float64) *float64 { return &v }
+
+// weekdays returns n bars of weekdays from Mon 2025-06-02, rising by 10 a day
+// except every third day, which falls.
+func weekdays(n int) []*model.AdjustedDailyOHLCV {
+  var bars []*model.AdjustedDailyOHLCV
+  d := model.MustParseDate("20250602")
+  for i := 0; len(bars) < n; i++ {
+    if wd := d.Time().Weekday(); wd != 0 && wd != 6 {
+      open, closePrice := 1000+float64(i)*10, 1005+float64(i)*10
+      if len(bars)%3 == 2 { open, closePrice = closePrice, open }
+      bars = append(bars, &model.AdjustedDailyOHLCV{Yyyymmdd: d, Code: "5253", OpenPrice: f(open), HighPrice: f(open + 20), LowPrice: f(open - 20), ClosePrice: f(closePrice), Volume: f(1500000)})
+    }
+    d = d.AddDays(1)
+  }
+  return bars
+}
+
+func newApp() (*App, *[]string) {
+  var loaded []string
+  app := &App{
+    Stocks: []*model.Stock{{Code: "5253", Name: "カバー"}, {Code: "7203", Name: "トヨタ自動車"}, {Code: "9999", Name: "Broken"}},
+    Load: func(code string) ([]*model.AdjustedDailyOHLCV, error) {
+      loaded = append(loaded, code)
+      if code == "9999" { return nil, errors.New("disk on fire") }
+      return weekdays(60), nil
+    },
+  }
+  return app, &loaded
+}
+
+func plain(view string) []string {
+  return strings.Split(ansi.ReplaceAllString(view, ""), "\r\n")
+}
+
+func TestApp_View(t *testing.T) {
+  app, loaded := newApp()
+  lines := plain(app.View(100, 30))
+
+  if len(lines) != 30 { t.Fatalf("want 30 lines, got %d", len(lines)) }
+  for i, line := range lines {
+    if w := displayWidth(line); w != 100 { t.Errorf("line %d is %d columns wide: %q", i, w, line) }
+  }
+  if !strings.Contains(lines[1], "5253 カバー") || !strings.Contains(lines[2], "7203 トヨタ自動車") { t.Errorf("Expected the codes listed, got %q and %q", lines[1], lines[2]) }
+  if !strings.Contains(lines[0], "5253 カバー  daily  2025/06/02 - 2025/08/22  60 bars") { t.Errorf("Unexpected title %q", lines[0]) }
+  if !strings.ContainsFunc(lines[1], func(r rune) bool { return r > 0x2800 && r <= 0x28ff }) || !strings.HasSuffix(lines[1], "1,835") { t.Errorf("Expected braille candles up to the high, got %q", lines[1]) }
+  if !strings.Contains(lines[18], "2025/08/22    1,815    1,835    1,795    1,810   +0.28   1,500,000") { t.Errorf("Expected the latest bar first in the table, got %q", lines[18]) }
+  if !slices.Equal(*loaded, []string{"5253"}) { t.Errorf("Expected only the selected code loaded, got %v", *loaded) }
+
+  // The selected row is highlighted.
+  if !strings.Contains(app.View(100, 30), "\x1b[7m 5253 カバー") { t.Error("Expected the selected code in reverse video") }
+}
+
+func TestApp_Keys(t *testing.T) {
+  app, loaded := newApp()
+  app.View(100, 30)
+
+  app.HandleKey("down")
+  if title := plain(app.View(100, 30))[0]; !strings.Contains(title, "7203 トヨタ自動車") { t.Errorf("Expected 7203 selected, got %q", title) }
+
+  app.HandleKey("w")
+  if title := plain(app.View(100, 30))[0]; !strings.Contains(title, "weekly  2025/06/02 - 2025/08/18  12 bars") { t.Errorf("Expected weekly bars, got %q", title) }
+  app.HandleKey("w")
+
+  // 62 columns, 31, 15 and then the minimum of 10.
+  app.HandleKey("+")
+  app.HandleKey("+")
+  app.HandleKey("+")
+  if title := plain(app.View(100, 30))[0]; !strings.Contains(title, "2025/08/11 - 2025/08/22  10 bars") { t.Errorf("Expected zoomed in to 10 bars, got %q", title) }
+  app.HandleKey("left")
+  if title := plain(app.View(100, 30))[0]; !strings.Contains(title, "2025/08/07 - 2025/08/20  10 bars") { t.Errorf("Expected panned back 2 bars, got %q", title) }
+  for range 100 {
+    app.HandleKey("left")
+  }
+  if title := plain(app.View(100, 30))[0]; !strings.Contains(title, "2025/06/02 - 2025/06/13") { t.Errorf("Expected the pan to stop at the first bar, got %q", title) }
+  app.HandleKey("-")
+  app.HandleKey("-")
+  app.HandleKey("-")
+  app.HandleKey("-")
+  if title := plain(app.View(100, 30))[0]; !strings.Contains(title, "60 bars") { t.Errorf("Expected zoomed out to every bar, got %q", title) }
+
+  app.HandleKey("G")
+  if view := plain(app.View(100, 30)); !strings.Contains(view[0], "9999: disk on fire") { t.Errorf("Expected the load error, got %q", view[0]) }
+  if !slices.Equal(*loaded, []string{"5253", "7203", "9999"}) { t.Errorf("Unexpected loads %v", *loaded) }
+
+  if app.HandleKey("x") { t.Error("Expected an unknown key ignored") }
+  if !app.HandleKey("q") { t.Error("Expected q to quit") }
+}
+
+func TestCandleRows(t *testing.T) {
+  up := &model.AdjustedDailyOHLCV{OpenPrice: f(100), HighPrice: f(130), LowPrice: f(90), ClosePrice: f(120)}
+  down := &model.AdjustedDailyOHLCV{OpenPrice: f(120), HighPrice: f(125), LowPrice: f(95), ClosePrice: f(100)}
+
+  rows := candleRows([]*model.AdjustedDailyOHLCV{up, down}, 2, 2, JapaneseColors)
+  // 8 dot rows from 130 to 90, 40/7 apart. The up wick spans rows 0 to 7 and
+  // its body 2 to 5. The down wick spans rows 1 to 6 and its body 2 to 5.
+  expected := []string{"\x1b[31m⣧\x1b[32m⣦\x1b[0m      130", "\x1b[31m⡟\x1b[32m⠟\x1b[0m       90"}
+  if !slices.Equal(rows, expected) { t.Errorf("got %q, want %q", rows, expected) }
+}
+
+func TestDecodeKeys(t *testing.T) {
+  keys := decodeKeys([]byte("\x1b[A\x1b[6~jq\x03\x1bOC\x1b[99Xカ\r\x1b"))
+  expected := []string{"up", "pgdown", "j", "q", "ctrl+c", "right", "カ", "enter", "esc"}
+  if !slices.Equal(keys, expected) { t.Errorf("got %q, want %q", keys, expected) }
+}
+
+func TestFit(t *testing.T) {
+  if s := fit("トヨタ自動車", 7); s != "トヨタ " { t.Errorf("got %q", s) }
+  if s := formatNumber(f(-1234567.891), 2); s != "-1,234,567.89" { t.Errorf("got %s", s) }
+}
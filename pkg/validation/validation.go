@@ -0,0 +1,169 @@
+package validation
+
+import (
+  "errors"
+  "fmt"
+  "math"
+  "strings"
+  "time"
+
+  "dunn-finance/pkg/csvreader"
+  "dunn-finance/pkg/model"
+)
+
+// Policy decides what happens to a file with invalid rows.
+type Policy string
+
+const (
+  // PolicyRejectRow drops the invalid rows and keeps the rest.
+  PolicyRejectRow  Policy = "reject-row"
+  // PolicyRejectFile drops the whole file when any row is invalid.
+  PolicyRejectFile Policy = "reject-file"
+  // PolicyWarn keeps every row and only reports the issues. Cells that did
+  // not parse are stored as NULL, and rows without a date still fail to insert.
+  PolicyWarn       Policy = "warn"
+)
+
+func ParsePolicy(s string) (Policy, error) {
+  switch p := Policy(s); p {
+    case PolicyRejectRow, PolicyRejectFile, PolicyWarn:
+      return p, nil
+    default:
+      return "", fmt.Errorf("unknown policy %q: want %s, %s or %s", s, PolicyRejectRow, PolicyRejectFile, PolicyWarn)
+  }
+}
+
+// Issue is one problem of a row. Line is the CSV line, or 0 when the row did
+// not come from a file.
+type Issue struct {
+  Line     int
+  Yyyymmdd model.Date
+  Field    string
+  Message  string
+}
+
+func (i Issue) Error() string {
+  var sb strings.Builder
+  if i.Line > 0 { fmt.Fprintf(&sb, "line %d: ", i.Line) }
+  if !i.Yyyymmdd.IsZero() { fmt.Fprintf(&sb, "%s: ", i.Yyyymmdd) }
+  if i.Field != "" { fmt.Fprintf(&sb, "%s: ", i.Field) }
+  sb.WriteString(i.Message)
+
+  return sb.String()
+}
+
+// TSE reopened after the war on this date. Earlier dates are typos.
+var earliestDate = model.NewDate(1949, time.May, 16)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+// Check returns the problems of one bar: non-positive prices, a high below or
+// a low above the other prices, a negative or fractional volume, and a date
+// that is missing, before the market existed or in the future.
+func Check(ohlcv *model.AdjustedDailyOHLCV, now time.Time) []Issue {
+  var issues []Issue
+  add := func(field string, format string, args ...any) {
+    issues = append(issues, Issue{Yyyymmdd: ohlcv.Yyyymmdd, Field: field, Message: fmt.Sprintf(format, args...)})
+  }
+
+  today := model.DateOf(now.In(jst))
+  switch {
+    case ohlcv.Yyyymmdd.IsZero():
+      add("Yyyymmdd", "missing date")
+    case ohlcv.Yyyymmdd.Before(earliestDate):
+      add("Yyyymmdd", "before %s", earliestDate)
+    case ohlcv.Yyyymmdd.After(today):
+      add("Yyyymmdd", "in the future")
+  }
+
+  prices := []struct {
+    field string
+    value *float64
+  }{
+    {"OpenPrice", ohlcv.OpenPrice},
+    {"HighPrice", ohlcv.HighPrice},
+    {"LowPrice", ohlcv.LowPrice},
+    {"ClosePrice", ohlcv.ClosePrice},
+  }
+  for _, p := range prices {
+    if p.value != nil && !(*p.value > 0) { add(p.field, "must be positive, got %g", *p.value) }
+  }
+
+  if high := ohlcv.HighPrice; high != nil {
+    for _, p := range prices {
+      if p.value != nil && *p.value > *high { add("HighPrice", "%g is below %s %g", *high, p.field, *p.value) }
+    }
+  }
+  if low := ohlcv.LowPrice; low != nil {
+    // A low above the high is already reported by the high.
+    for _, p := range prices {
+      if p.field == "HighPrice" { continue }
+      if p.value != nil && *p.value < *low { add("LowPrice", "%g is above %s %g", *low, p.field, *p.value) }
+    }
+  }
+
+  if v := ohlcv.Volume; v != nil {
+    if *v < 0 { add("Volume", "must not be negative, got %g", *v) }
+    if *v != math.Trunc(*v) { add("Volume", "must be a whole number, got %g", *v) }
+  }
+
+  return issues
+}
+
+// Result is the outcome of validating one file.
+type Result struct {
+  Valid        []*model.AdjustedDailyOHLCV
+  Issues       []Issue
+  // Rows dropped for their issues. Every row when the file was rejected.
+  Rejected     int
+  FileRejected bool
+}
+
+// Err is nil when no issue was found, and otherwise joins every issue.
+func (r *Result) Err() error {
+  if len(r.Issues) == 0 { return nil }
+
+  errs := make([]error, len(r.Issues))
+  for i, issue := range r.Issues {
+    errs[i] = issue
+  }
+  return errors.Join(errs...)
+}
+
+// ValidateRows checks the rows loaded from a CSV file, including the cells
+// that failed to parse, and applies the policy.
+func ValidateRows(rows []*csvreader.Row, policy Policy, now time.Time) *Result {
+  result := &Result{}
+  for _, row := range rows {
+    var issues []Issue
+    unparsed := make(map[string]bool)
+    for _, cellErr := range row.Errs {
+      issues = append(issues, Issue{Field: cellErr.Field, Message: fmt.Sprintf("cannot parse %q: %v", cellErr.Value, cellErr.Err)})
+      unparsed[cellErr.Field] = true
+    }
+    // A cell that did not parse is already reported, e.g. not again as a
+    // missing date.
+    for _, issue := range Check(row.OHLCV, now) {
+      if !unparsed[issue.Field] { issues = append(issues, issue) }
+    }
+    for i := range issues {
+      issues[i].Line = row.Line
+      if issues[i].Yyyymmdd.IsZero() { issues[i].Yyyymmdd = row.OHLCV.Yyyymmdd }
+    }
+    result.Issues = append(result.Issues, issues...)
+
+    if len(issues) > 0 && policy != PolicyWarn {
+      result.Rejected++
+      continue
+    }
+    result.Valid = append(result.Valid, row.OHLCV)
+  }
+
+  if policy == PolicyRejectFile && len(result.Issues) > 0 {
+    result.Valid = nil
+    result.Rejected = len(rows)
+    result.FileRejected = true
+  }
+
+  return result
+}
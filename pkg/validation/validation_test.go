@@ -0,0 +1,99 @@
+package validation_test
+
+import (
+  "errors"
+  "strings"
+  "testing"
+  "time"
+
+  "dunn-finance/pkg/csvreader"
+  "dunn-finance/pkg/model"
+  "dunn-finance/pkg/validation"
+)
+
+var now = time.Date(2025, 7, 20, 12, 0, 0, 0, time.UTC)
+
+func f(v float64) *float64 { return &v }
+
+func bar(yyyymmdd string, open, high, low, closePrice, volume float64) *model.AdjustedDailyOHLCV {
+  return &model.AdjustedDailyOHLCV{
+    Yyyymmdd:   model.MustParseDate(yyyymmdd),
+    Code:       "1234",
+    OpenPrice:  f(open),
+    HighPrice:  f(high),
+    LowPrice:   f(low),
+    ClosePrice: f(closePrice),
+    Volume:     f(volume),
+  }
+}
+
+func TestCheck(t *testing.T) {
+  tests := []struct {
+    name   string
+    ohlcv  *model.AdjustedDailyOHLCV
+    fields []string
+  }{
+    {"valid", bar("20250718", 100, 110, 90, 105, 1000), nil},
+    {"missing values are fine", &model.AdjustedDailyOHLCV{Yyyymmdd: model.MustParseDate("20250718")}, nil},
+    {"zero price", bar("20250718", 0, 110, 90, 105, 1000), []string{"OpenPrice", "LowPrice"}},
+    {"high below close", bar("20250718", 100, 104, 90, 105, 1000), []string{"HighPrice"}},
+    {"low above open", bar("20250718", 100, 110, 101, 105, 1000), []string{"LowPrice"}},
+    {"low above high", bar("20250718", 100, 110, 120, 105, 1000), []string{"HighPrice", "LowPrice", "LowPrice"}},
+    {"fractional volume", bar("20250718", 100, 110, 90, 105, 1000.5), []string{"Volume"}},
+    {"negative volume", bar("20250718", 100, 110, 90, 105, -1), []string{"Volume"}},
+    {"future date", bar("20250721", 100, 110, 90, 105, 1000), []string{"Yyyymmdd"}},
+    {"ancient date", bar("19000101", 100, 110, 90, 105, 1000), []string{"Yyyymmdd"}},
+    {"missing date", &model.AdjustedDailyOHLCV{}, []string{"Yyyymmdd"}},
+  }
+
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      var fields []string
+      for _, issue := range validation.Check(tt.ohlcv, now) {
+        fields = append(fields, issue.Field)
+      }
+      if strings.Join(fields, ",") != strings.Join(tt.fields, ",") { t.Errorf("got %v, want %v", fields, tt.fields) }
+    })
+  }
+}
+
+func rows() []*csvreader.Row {
+  return []*csvreader.Row{
+    {Line: 2, OHLCV: bar("20250718", 100, 110, 90, 105, 1000)},
+    {Line: 3, OHLCV: &model.AdjustedDailyOHLCV{Yyyymmdd: model.MustParseDate("20250717")}, Errs: []*csvreader.CellError{{Field: "ClosePrice", Value: "1O5", Err: errors.New("invalid syntax")}}},
+    {Line: 4, OHLCV: bar("20250716", 100, 110, 90, 0, 1000)},
+    {Line: 5, OHLCV: &model.AdjustedDailyOHLCV{}, Errs: []*csvreader.CellError{{Field: "Yyyymmdd", Value: "2025/02/30", Err: model.ErrInvalidDate}}},
+  }
+}
+
+func TestValidateRows_RejectRow(t *testing.T) {
+  result := validation.ValidateRows(rows(), validation.PolicyRejectRow, now)
+
+  if len(result.Valid) != 1 || result.Valid[0].Yyyymmdd.String() != "20250718" { t.Errorf("Unexpected valid rows: %v", result.Valid) }
+  if result.Rejected != 3 || result.FileRejected { t.Errorf("Unexpected rejection: %d, %t", result.Rejected, result.FileRejected) }
+  // The unparsable date is reported once, not again as missing.
+  if len(result.Issues) != 4 { t.Fatalf("want 4 issues, got %v", result.Issues) }
+
+  expected := `line 3: 20250717: ClosePrice: cannot parse "1O5": invalid syntax`
+  if got := result.Issues[0].Error(); got != expected { t.Errorf("got %q, want %q", got, expected) }
+  if result.Issues[3].Line != 5 { t.Errorf("want line 5, got %d", result.Issues[3].Line) }
+  if result.Err() == nil { t.Error("Expected Err to report the issues") }
+}
+
+func TestValidateRows_RejectFile(t *testing.T) {
+  result := validation.ValidateRows(rows(), validation.PolicyRejectFile, now)
+  if len(result.Valid) != 0 || result.Rejected != 4 || !result.FileRejected { t.Errorf("Expected the whole file rejected, got %+v", result) }
+
+  clean := validation.ValidateRows(rows()[:1], validation.PolicyRejectFile, now)
+  if len(clean.Valid) != 1 || clean.FileRejected || clean.Err() != nil { t.Errorf("Expected a clean file kept, got %+v", clean) }
+}
+
+func TestValidateRows_Warn(t *testing.T) {
+  result := validation.ValidateRows(rows(), validation.PolicyWarn, now)
+  if len(result.Valid) != 4 || result.Rejected != 0 || len(result.Issues) != 4 { t.Errorf("Expected every row kept with issues, got %+v", result) }
+}
+
+func TestParsePolicy(t *testing.T) {
+  if p, err := validation.ParsePolicy("reject-file"); err != nil || p != validation.PolicyRejectFile { t.Errorf("got %q, %v", p, err) }
+  if _, err := validation.ParsePolicy("ignore"); err == nil { t.Error("Expected an error for an unknown policy") }
+}